@@ -0,0 +1,276 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/payments.billing-agreements/
+
+var (
+	AgreementStatePending   AgreementState = "Pending"
+	AgreementStateActive    AgreementState = "Active"
+	AgreementStateSuspended AgreementState = "Suspended"
+	AgreementStateCancelled AgreementState = "Cancelled"
+	AgreementStateExpired   AgreementState = "Expired"
+)
+
+type (
+	AgreementState string
+
+	// Agreement maps to agreement object. To create an agreement, Plan only
+	// needs its ID set
+	Agreement struct {
+		ID                          string                `json:"id,omitempty"`
+		State                       AgreementState        `json:"state,omitempty"`
+		Name                        string                `json:"name"`
+		Description                 string                `json:"description"`
+		StartDate                   *time.Time            `json:"start_date,omitempty"`
+		Payer                       *Payer                `json:"payer,omitempty"`
+		ShippingAddress             *ShippingAddress      `json:"shipping_address,omitempty"`
+		OverrideMerchantPreferences *MerchantPreferences  `json:"override_merchant_preferences,omitempty"`
+		OverrideChargeModels        []OverrideChargeModel `json:"override_charge_models,omitempty"`
+		Plan                        *Plan                 `json:"plan,omitempty"`
+		CreateTime                  *time.Time            `json:"create_time,omitempty"`
+		UpdateTime                  *time.Time            `json:"update_time,omitempty"`
+		AgreementDetails            *AgreementDetails     `json:"agreement_details,omitempty"`
+		Links                       []Links               `json:"links,omitempty"`
+	}
+
+	// AgreementDetails maps to agreement_details object
+	AgreementDetails struct {
+		OutstandingBalance *Currency  `json:"outstanding_balance,omitempty"`
+		CyclesRemaining    string     `json:"cycles_remaining,omitempty"`
+		CyclesCompleted    string     `json:"cycles_completed,omitempty"`
+		NextBillingDate    *time.Time `json:"next_billing_date,omitempty"`
+		LastPaymentDate    *time.Time `json:"last_payment_date,omitempty"`
+		LastPaymentAmount  *Currency  `json:"last_payment_amount,omitempty"`
+		FinalPaymentDate   *time.Time `json:"final_payment_date,omitempty"`
+		FailedPaymentCount string     `json:"failed_payment_count,omitempty"`
+	}
+
+	// OverrideChargeModel maps to override_charge_model object
+	OverrideChargeModel struct {
+		ChargeID string    `json:"charge_id"`
+		Amount   *Currency `json:"amount"`
+	}
+
+	// AgreementStateDescriptor maps to agreement_state_descriptor object
+	AgreementStateDescriptor struct {
+		Note   string    `json:"note,omitempty"`
+		Amount *Currency `json:"amount,omitempty"`
+	}
+
+	// AgreementTransaction maps to agreement_transaction object
+	AgreementTransaction struct {
+		TransactionID   string     `json:"transaction_id"`
+		Status          string     `json:"status"`
+		TransactionType string     `json:"transaction_type"`
+		Amount          *Currency  `json:"amount,omitempty"`
+		FeeAmount       *Currency  `json:"fee_amount,omitempty"`
+		NetAmount       *Currency  `json:"net_amount,omitempty"`
+		PayerEmail      string     `json:"payer_email,omitempty"`
+		PayerName       string     `json:"payer_name,omitempty"`
+		TimeStamp       *time.Time `json:"time_stamp,omitempty"`
+		TimeZone        string     `json:"time_zone,omitempty"`
+	}
+
+	// CreateAgreementResp is the agreement returned when creating one.
+	// ApprovalURL is where the payer must be redirected to approve it, and
+	// Token is what ExecuteAgreement must be called with once they did
+	CreateAgreementResp struct {
+		*Agreement
+		ApprovalURL string `json:"-"`
+		Token       string `json:"-"`
+	}
+
+	// AgreementTransactions maps to agreement_transactions object
+	AgreementTransactions struct {
+		AgreementTransactionList []AgreementTransaction `json:"agreement_transaction_list"`
+	}
+)
+
+// CreateAgreement creates a billing agreement for an active plan. The payer
+// must then approve it at the returned ApprovalURL before it is executed
+func (c *Client) CreateAgreement(a Agreement) (*CreateAgreementResp, error) {
+	return c.CreateAgreementContext(context.Background(), a)
+}
+
+// CreateAgreementContext is like CreateAgreement but uses ctx for the request
+func (c *Client) CreateAgreementContext(ctx context.Context, a Agreement) (*CreateAgreementResp, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/billing-agreements", c.APIBase), a)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &CreateAgreementResp{Agreement: &Agreement{}}
+
+	err = c.SendWithAuth(req, v.Agreement)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range v.Links {
+		if l.Rel != "approval_url" {
+			continue
+		}
+		v.ApprovalURL = l.Href
+		if u, err := url.Parse(l.Href); err == nil {
+			v.Token = u.Query().Get("token")
+		}
+	}
+
+	return v, nil
+}
+
+// ExecuteAgreement executes a billing agreement once the payer approved it,
+// token being the one of the approval URL
+func (c *Client) ExecuteAgreement(token string) (*Agreement, error) {
+	return c.ExecuteAgreementContext(context.Background(), token)
+}
+
+// ExecuteAgreementContext is like ExecuteAgreement but uses ctx for the request
+func (c *Client) ExecuteAgreementContext(ctx context.Context, token string) (*Agreement, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/billing-agreements/%s/agreement-execute", c.APIBase, token), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Agreement{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetAgreement returns a billing agreement by ID
+func (c *Client) GetAgreement(agreementID string) (*Agreement, error) {
+	return c.GetAgreementContext(context.Background(), agreementID)
+}
+
+// GetAgreementContext is like GetAgreement but uses ctx for the request
+func (c *Client) GetAgreementContext(ctx context.Context, agreementID string) (*Agreement, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/billing-agreements/%s", c.APIBase, agreementID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Agreement{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateAgreement applies a JSON Patch to a billing agreement
+func (c *Client) UpdateAgreement(agreementID string, patches []Patch) error {
+	return c.UpdateAgreementContext(context.Background(), agreementID, patches)
+}
+
+// UpdateAgreementContext is like UpdateAgreement but uses ctx for the request
+func (c *Client) UpdateAgreementContext(ctx context.Context, agreementID string, patches []Patch) error {
+	req, err := NewRequestContext(ctx, "PATCH", fmt.Sprintf("%s/payments/billing-agreements/%s", c.APIBase, agreementID), patches)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// SuspendAgreement suspends a billing agreement, with note explaining why
+func (c *Client) SuspendAgreement(agreementID, note string) error {
+	return c.SuspendAgreementContext(context.Background(), agreementID, note)
+}
+
+// SuspendAgreementContext is like SuspendAgreement but uses ctx for the request
+func (c *Client) SuspendAgreementContext(ctx context.Context, agreementID, note string) error {
+	return c.agreementAction(ctx, agreementID, "suspend", AgreementStateDescriptor{Note: note})
+}
+
+// ReactivateAgreement reactivates a suspended billing agreement
+func (c *Client) ReactivateAgreement(agreementID, note string) error {
+	return c.ReactivateAgreementContext(context.Background(), agreementID, note)
+}
+
+// ReactivateAgreementContext is like ReactivateAgreement but uses ctx for the request
+func (c *Client) ReactivateAgreementContext(ctx context.Context, agreementID, note string) error {
+	return c.agreementAction(ctx, agreementID, "re-activate", AgreementStateDescriptor{Note: note})
+}
+
+// CancelAgreement cancels a billing agreement. It can't be reactivated
+func (c *Client) CancelAgreement(agreementID, note string) error {
+	return c.CancelAgreementContext(context.Background(), agreementID, note)
+}
+
+// CancelAgreementContext is like CancelAgreement but uses ctx for the request
+func (c *Client) CancelAgreementContext(ctx context.Context, agreementID, note string) error {
+	return c.agreementAction(ctx, agreementID, "cancel", AgreementStateDescriptor{Note: note})
+}
+
+// SetAgreementBalance sets the outstanding balance of a billing agreement
+func (c *Client) SetAgreementBalance(agreementID string, balance Currency) error {
+	return c.SetAgreementBalanceContext(context.Background(), agreementID, balance)
+}
+
+// SetAgreementBalanceContext is like SetAgreementBalance but uses ctx for the request
+func (c *Client) SetAgreementBalanceContext(ctx context.Context, agreementID string, balance Currency) error {
+	return c.agreementAction(ctx, agreementID, "set-balance", balance)
+}
+
+// BillAgreementBalance bills the outstanding balance of a billing agreement.
+// If amount is nil, the whole balance is billed
+func (c *Client) BillAgreementBalance(agreementID, note string, amount *Currency) error {
+	return c.BillAgreementBalanceContext(context.Background(), agreementID, note, amount)
+}
+
+// BillAgreementBalanceContext is like BillAgreementBalance but uses ctx for the request
+func (c *Client) BillAgreementBalanceContext(ctx context.Context, agreementID, note string, amount *Currency) error {
+	return c.agreementAction(ctx, agreementID, "bill-balance", AgreementStateDescriptor{Note: note, Amount: amount})
+}
+
+// ListAgreementTransactions returns the transactions of a billing agreement
+// between the start and end dates, both included
+func (c *Client) ListAgreementTransactions(agreementID string, start, end time.Time) ([]AgreementTransaction, error) {
+	return c.ListAgreementTransactionsContext(context.Background(), agreementID, start, end)
+}
+
+// ListAgreementTransactionsContext is like ListAgreementTransactions but uses ctx for the request
+func (c *Client) ListAgreementTransactionsContext(ctx context.Context, agreementID string, start, end time.Time) ([]AgreementTransaction, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/billing-agreements/%s/transactions", c.APIBase, agreementID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("start_date", start.Format("2006-01-02"))
+	q.Set("end_date", end.Format("2006-01-02"))
+	req.URL.RawQuery = q.Encode()
+
+	var v AgreementTransactions
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.AgreementTransactionList, nil
+}
+
+// agreementAction posts payload to one of the action endpoints of a billing
+// agreement, which respond with no content
+func (c *Client) agreementAction(ctx context.Context, agreementID, action string, payload interface{}) error {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/billing-agreements/%s/%s", c.APIBase, agreementID, action), payload)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
@@ -0,0 +1,253 @@
+package paypal
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Interfaces grouping the methods of Client by resource, so that code using
+// the client can depend on them and be tested against a fake. The
+// paypaltest package provides a Mock implementing all of them
+
+type (
+	// PaymentsAPI is implemented by Client for payment resources
+	PaymentsAPI interface {
+		CreatePayment(p Payment) (*CreatePaymentResp, error)
+		CreatePaymentContext(ctx context.Context, p Payment) (*CreatePaymentResp, error)
+		ExecutePayment(paymentID, payerID string, transactions []Transaction) (*ExecutePaymentResp, error)
+		ExecutePaymentContext(ctx context.Context, paymentID, payerID string, transactions []Transaction) (*ExecutePaymentResp, error)
+		GetPayment(id string) (*Payment, error)
+		GetPaymentContext(ctx context.Context, id string) (*Payment, error)
+		ListPayments(filter map[string]string) ([]Payment, error)
+		ListPaymentsContext(ctx context.Context, filter map[string]string) ([]Payment, error)
+	}
+
+	// SalesAPI is implemented by Client for sale transactions
+	SalesAPI interface {
+		GetSale(saleID string) (*Sale, error)
+		GetSaleContext(ctx context.Context, saleID string) (*Sale, error)
+		RefundSale(saleID string, a *Amount) (*Refund, error)
+		RefundSaleContext(ctx context.Context, saleID string, a *Amount) (*Refund, error)
+	}
+
+	// AuthorizationsAPI is implemented by Client for authorizations
+	AuthorizationsAPI interface {
+		GetAuthorization(authID string) (*Authorization, error)
+		GetAuthorizationContext(ctx context.Context, authID string) (*Authorization, error)
+		CaptureAuthorization(authID string, a *Amount, isFinalCapture bool) (*Capture, error)
+		CaptureAuthorizationContext(ctx context.Context, authID string, a *Amount, isFinalCapture bool) (*Capture, error)
+		VoidAuthorization(authID string) (*Authorization, error)
+		VoidAuthorizationContext(ctx context.Context, authID string) (*Authorization, error)
+		ReauthorizeAuthorization(authID string, a *Amount) (*Authorization, error)
+		ReauthorizeAuthorizationContext(ctx context.Context, authID string, a *Amount) (*Authorization, error)
+	}
+
+	// OrdersAPI is implemented by Client for orders
+	OrdersAPI interface {
+		GetOrder(orderID string) (*Order, error)
+		GetOrderContext(ctx context.Context, orderID string) (*Order, error)
+		AuthorizeOrder(orderID string, a *Amount) (*Authorization, error)
+		AuthorizeOrderContext(ctx context.Context, orderID string, a *Amount) (*Authorization, error)
+		CaptureOrder(orderID string, a *Amount, isFinalCapture bool) (*Capture, error)
+		CaptureOrderContext(ctx context.Context, orderID string, a *Amount, isFinalCapture bool) (*Capture, error)
+		VoidOrder(orderID string) (*Order, error)
+		VoidOrderContext(ctx context.Context, orderID string) (*Order, error)
+		RefundOrder(orderID string, a *Amount) (*Refund, error)
+		RefundOrderContext(ctx context.Context, orderID string, a *Amount) (*Refund, error)
+	}
+
+	// CapturesAPI is implemented by Client for captures
+	CapturesAPI interface {
+		GetCapture(captureID string) (*Capture, error)
+		GetCaptureContext(ctx context.Context, captureID string) (*Capture, error)
+		RefundCapture(captureID string, a *Amount) (*Refund, error)
+		RefundCaptureContext(ctx context.Context, captureID string, a *Amount) (*Refund, error)
+	}
+
+	// RefundsAPI is implemented by Client for refunds
+	RefundsAPI interface {
+		GetRefund(refundID string) (*Refund, error)
+		GetRefundContext(ctx context.Context, refundID string) (*Refund, error)
+	}
+
+	// VaultAPI is implemented by Client for credit cards stored in the vault
+	VaultAPI interface {
+		StoreInVault(cc VaultRequest) (*VaultResponse, error)
+		StoreInVaultContext(ctx context.Context, cc VaultRequest) (*VaultResponse, error)
+		GetStoredCreditCard(cardID string) (*VaultResponse, error)
+		GetStoredCreditCardContext(ctx context.Context, cardID string) (*VaultResponse, error)
+		ListStoredCreditCards(filter map[string]string) (*ListStoredCreditCardsResp, error)
+		ListStoredCreditCardsContext(ctx context.Context, filter map[string]string) (*ListStoredCreditCardsResp, error)
+		UpdateStoredCreditCard(cardID string, patches []Patch) (*VaultResponse, error)
+		UpdateStoredCreditCardContext(ctx context.Context, cardID string, patches []Patch) (*VaultResponse, error)
+		DeleteStoredCreditCard(cardID string) error
+		DeleteStoredCreditCardContext(ctx context.Context, cardID string) error
+	}
+
+	// PlansAPI is implemented by Client for billing plans
+	PlansAPI interface {
+		CreatePlan(p Plan) (*Plan, error)
+		CreatePlanContext(ctx context.Context, p Plan) (*Plan, error)
+		GetPlan(planID string) (*Plan, error)
+		GetPlanContext(ctx context.Context, planID string) (*Plan, error)
+		ListPlans(filter map[string]string) (*ListPlansResp, error)
+		ListPlansContext(ctx context.Context, filter map[string]string) (*ListPlansResp, error)
+		UpdatePlan(planID string, patches []Patch) error
+		UpdatePlanContext(ctx context.Context, planID string, patches []Patch) error
+		ActivatePlan(planID string) error
+		ActivatePlanContext(ctx context.Context, planID string) error
+		DeactivatePlan(planID string) error
+		DeactivatePlanContext(ctx context.Context, planID string) error
+	}
+
+	// AgreementsAPI is implemented by Client for billing agreements
+	AgreementsAPI interface {
+		CreateAgreement(a Agreement) (*CreateAgreementResp, error)
+		CreateAgreementContext(ctx context.Context, a Agreement) (*CreateAgreementResp, error)
+		ExecuteAgreement(token string) (*Agreement, error)
+		ExecuteAgreementContext(ctx context.Context, token string) (*Agreement, error)
+		GetAgreement(agreementID string) (*Agreement, error)
+		GetAgreementContext(ctx context.Context, agreementID string) (*Agreement, error)
+		UpdateAgreement(agreementID string, patches []Patch) error
+		UpdateAgreementContext(ctx context.Context, agreementID string, patches []Patch) error
+		SuspendAgreement(agreementID, note string) error
+		SuspendAgreementContext(ctx context.Context, agreementID, note string) error
+		ReactivateAgreement(agreementID, note string) error
+		ReactivateAgreementContext(ctx context.Context, agreementID, note string) error
+		CancelAgreement(agreementID, note string) error
+		CancelAgreementContext(ctx context.Context, agreementID, note string) error
+		SetAgreementBalance(agreementID string, balance Currency) error
+		SetAgreementBalanceContext(ctx context.Context, agreementID string, balance Currency) error
+		BillAgreementBalance(agreementID, note string, amount *Currency) error
+		BillAgreementBalanceContext(ctx context.Context, agreementID, note string, amount *Currency) error
+		ListAgreementTransactions(agreementID string, start, end time.Time) ([]AgreementTransaction, error)
+		ListAgreementTransactionsContext(ctx context.Context, agreementID string, start, end time.Time) ([]AgreementTransaction, error)
+	}
+
+	// WebhooksAPI is implemented by Client for webhooks and webhook events
+	WebhooksAPI interface {
+		CreateWebhook(url string, eventTypes []EventType) (*Webhook, error)
+		CreateWebhookContext(ctx context.Context, url string, eventTypes []EventType) (*Webhook, error)
+		ListWebhooks() ([]Webhook, error)
+		ListWebhooksContext(ctx context.Context) ([]Webhook, error)
+		GetWebhook(webhookID string) (*Webhook, error)
+		GetWebhookContext(ctx context.Context, webhookID string) (*Webhook, error)
+		UpdateWebhook(webhookID string, patches []Patch) (*Webhook, error)
+		UpdateWebhookContext(ctx context.Context, webhookID string, patches []Patch) (*Webhook, error)
+		DeleteWebhook(webhookID string) error
+		DeleteWebhookContext(ctx context.Context, webhookID string) error
+		ListWebhookEventTypes(webhookID string) ([]WebhookEventType, error)
+		ListWebhookEventTypesContext(ctx context.Context, webhookID string) ([]WebhookEventType, error)
+		ListAvailableEventTypes() ([]WebhookEventType, error)
+		ListAvailableEventTypesContext(ctx context.Context) ([]WebhookEventType, error)
+		ListWebhookEvents(filter map[string]string) (*ListWebhookEventsResp, error)
+		ListWebhookEventsContext(ctx context.Context, filter map[string]string) (*ListWebhookEventsResp, error)
+		GetWebhookEvent(eventID string) (*WebhookEvent, error)
+		GetWebhookEventContext(ctx context.Context, eventID string) (*WebhookEvent, error)
+		ResendWebhookEvent(eventID string, webhookIDs []string) (*WebhookEvent, error)
+		ResendWebhookEventContext(ctx context.Context, eventID string, webhookIDs []string) (*WebhookEvent, error)
+		SimulateWebhookEvent(s SimulateWebhookEventReq) (*WebhookEvent, error)
+		SimulateWebhookEventContext(ctx context.Context, s SimulateWebhookEventReq) (*WebhookEvent, error)
+		VerifyWebhookSignature(v VerifyWebhookSignatureReq) (*VerifyWebhookSignatureResp, error)
+		VerifyWebhookSignatureContext(ctx context.Context, v VerifyWebhookSignatureReq) (*VerifyWebhookSignatureResp, error)
+	}
+
+	// IdentityAPI is implemented by Client for users logging in with PayPal
+	IdentityAPI interface {
+		ExchangeAuthorizationCode(code, redirectURI string) (*IdentityToken, error)
+		ExchangeAuthorizationCodeContext(ctx context.Context, code, redirectURI string) (*IdentityToken, error)
+		RefreshIdentityToken(refreshToken string) (*IdentityToken, error)
+		RefreshIdentityTokenContext(ctx context.Context, refreshToken string) (*IdentityToken, error)
+		GetUserInfo(accessToken string) (*UserInfo, error)
+		GetUserInfoContext(ctx context.Context, accessToken string) (*UserInfo, error)
+	}
+
+	// InvoicesAPI is implemented by Client for invoices and invoice templates
+	InvoicesAPI interface {
+		CreateInvoice(i Invoice) (*Invoice, error)
+		CreateInvoiceContext(ctx context.Context, i Invoice) (*Invoice, error)
+		GetInvoice(invoiceID string) (*Invoice, error)
+		GetInvoiceContext(ctx context.Context, invoiceID string) (*Invoice, error)
+		UpdateInvoice(invoiceID string, i Invoice) (*Invoice, error)
+		UpdateInvoiceContext(ctx context.Context, invoiceID string, i Invoice) (*Invoice, error)
+		DeleteInvoice(invoiceID string) error
+		DeleteInvoiceContext(ctx context.Context, invoiceID string) error
+		SendInvoice(invoiceID string, notifyMerchant bool) error
+		SendInvoiceContext(ctx context.Context, invoiceID string, notifyMerchant bool) error
+		RemindInvoice(invoiceID string, n InvoiceNotification) error
+		RemindInvoiceContext(ctx context.Context, invoiceID string, n InvoiceNotification) error
+		CancelInvoice(invoiceID string, n InvoiceNotification) error
+		CancelInvoiceContext(ctx context.Context, invoiceID string, n InvoiceNotification) error
+		RecordInvoicePayment(invoiceID string, p InvoicePaymentDetail) error
+		RecordInvoicePaymentContext(ctx context.Context, invoiceID string, p InvoicePaymentDetail) error
+		RecordInvoiceRefund(invoiceID string, r InvoiceRefundDetail) error
+		RecordInvoiceRefundContext(ctx context.Context, invoiceID string, r InvoiceRefundDetail) error
+		SearchInvoices(s InvoiceSearch) (*SearchInvoicesResp, error)
+		SearchInvoicesContext(ctx context.Context, s InvoiceSearch) (*SearchInvoicesResp, error)
+		GenerateInvoiceNumber() (string, error)
+		GenerateInvoiceNumberContext(ctx context.Context) (string, error)
+		GetInvoiceQRCode(invoiceID string, width, height int) ([]byte, error)
+		GetInvoiceQRCodeContext(ctx context.Context, invoiceID string, width, height int) ([]byte, error)
+		WriteInvoiceQRCode(w io.Writer, invoiceID string, width, height int) error
+		WriteInvoiceQRCodeContext(ctx context.Context, w io.Writer, invoiceID string, width, height int) error
+		CreateInvoiceTemplate(t InvoiceTemplate) (*InvoiceTemplate, error)
+		CreateInvoiceTemplateContext(ctx context.Context, t InvoiceTemplate) (*InvoiceTemplate, error)
+		ListInvoiceTemplates() (*ListInvoiceTemplatesResp, error)
+		ListInvoiceTemplatesContext(ctx context.Context) (*ListInvoiceTemplatesResp, error)
+		GetInvoiceTemplate(templateID string) (*InvoiceTemplate, error)
+		GetInvoiceTemplateContext(ctx context.Context, templateID string) (*InvoiceTemplate, error)
+		UpdateInvoiceTemplate(templateID string, t InvoiceTemplate) (*InvoiceTemplate, error)
+		UpdateInvoiceTemplateContext(ctx context.Context, templateID string, t InvoiceTemplate) (*InvoiceTemplate, error)
+		DeleteInvoiceTemplate(templateID string) error
+		DeleteInvoiceTemplateContext(ctx context.Context, templateID string) error
+	}
+
+	// WebProfilesAPI is implemented by Client for web experience profiles
+	WebProfilesAPI interface {
+		CreateWebProfile(p WebProfile) (*WebProfile, error)
+		CreateWebProfileContext(ctx context.Context, p WebProfile) (*WebProfile, error)
+		ListWebProfiles() ([]WebProfile, error)
+		ListWebProfilesContext(ctx context.Context) ([]WebProfile, error)
+		GetWebProfile(profileID string) (*WebProfile, error)
+		GetWebProfileContext(ctx context.Context, profileID string) (*WebProfile, error)
+		UpdateWebProfile(profileID string, p WebProfile) error
+		UpdateWebProfileContext(ctx context.Context, profileID string, p WebProfile) error
+		PatchWebProfile(profileID string, patches []Patch) error
+		PatchWebProfileContext(ctx context.Context, profileID string, patches []Patch) error
+		DeleteWebProfile(profileID string) error
+		DeleteWebProfileContext(ctx context.Context, profileID string) error
+	}
+
+	// PayoutsAPI is implemented by Client for payout batches and items
+	PayoutsAPI interface {
+		CreatePayout(p Payout, syncMode bool) (*PayoutBatch, error)
+		CreatePayoutContext(ctx context.Context, p Payout, syncMode bool) (*PayoutBatch, error)
+		GetPayoutBatch(payoutBatchID string, filter map[string]string) (*PayoutBatch, error)
+		GetPayoutBatchContext(ctx context.Context, payoutBatchID string, filter map[string]string) (*PayoutBatch, error)
+		GetPayoutItem(payoutItemID string) (*PayoutItemDetail, error)
+		GetPayoutItemContext(ctx context.Context, payoutItemID string) (*PayoutItemDetail, error)
+		CancelPayoutItem(payoutItemID string) (*PayoutItemDetail, error)
+		CancelPayoutItemContext(ctx context.Context, payoutItemID string) (*PayoutItemDetail, error)
+	}
+
+	// API groups all the resource interfaces implemented by Client
+	API interface {
+		PaymentsAPI
+		SalesAPI
+		AuthorizationsAPI
+		OrdersAPI
+		CapturesAPI
+		RefundsAPI
+		VaultAPI
+		PlansAPI
+		AgreementsAPI
+		WebhooksAPI
+		IdentityAPI
+		InvoicesAPI
+		WebProfilesAPI
+		PayoutsAPI
+	}
+)
+
+var _ API = (*Client)(nil)
@@ -1,6 +1,9 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // https://developer.paypal.com/webapps/developer/docs/api/#authorizations
 
@@ -12,7 +15,12 @@ type (
 
 // GetAuthorization returns an authorization by ID
 func (c *Client) GetAuthorization(authID string) (*Authorization, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/authorization/%s", c.APIBase, authID), nil)
+	return c.GetAuthorizationContext(context.Background(), authID)
+}
+
+// GetAuthorizationContext is like GetAuthorization but uses ctx for the request
+func (c *Client) GetAuthorizationContext(ctx context.Context, authID string) (*Authorization, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/authorization/%s", c.APIBase, authID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -30,7 +38,12 @@ func (c *Client) GetAuthorization(authID string) (*Authorization, error) {
 // CaptureAuthorization captures and process an existing authorization.
 // To use this method, the original payment must have Intent set to PaymentIntentAuthorize
 func (c *Client) CaptureAuthorization(authID string, a *Amount, isFinalCapture bool) (*Capture, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/authorization/%s/capture", c.APIBase, authID), struct {
+	return c.CaptureAuthorizationContext(context.Background(), authID, a, isFinalCapture)
+}
+
+// CaptureAuthorizationContext is like CaptureAuthorization but uses ctx for the request
+func (c *Client) CaptureAuthorizationContext(ctx context.Context, authID string, a *Amount, isFinalCapture bool) (*Capture, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/authorization/%s/capture", c.APIBase, authID), struct {
 		Amount         *Amount `json:"amount"`
 		IsFinalCapture bool    `json:"is_final_capture"`
 	}{a, isFinalCapture})
@@ -51,7 +64,12 @@ func (c *Client) CaptureAuthorization(authID string, a *Amount, isFinalCapture b
 // VoidAuthorization voids a previously authorized payment. A fully
 // captured authorization cannot be voided
 func (c *Client) VoidAuthorization(authID string) (*Authorization, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/authorization/%s/void", c.APIBase, authID), nil)
+	return c.VoidAuthorizationContext(context.Background(), authID)
+}
+
+// VoidAuthorizationContext is like VoidAuthorization but uses ctx for the request
+func (c *Client) VoidAuthorizationContext(ctx context.Context, authID string) (*Authorization, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/authorization/%s/void", c.APIBase, authID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -71,7 +89,12 @@ func (c *Client) VoidAuthorization(authID string) (*Authorization, error) {
 // ensure that funds are still available. Only paypal account payments can be re-
 // authorized
 func (c *Client) ReauthorizeAuthorization(authID string, a *Amount) (*Authorization, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/authorization/%s/reauthorize", c.APIBase, authID), struct {
+	return c.ReauthorizeAuthorizationContext(context.Background(), authID, a)
+}
+
+// ReauthorizeAuthorizationContext is like ReauthorizeAuthorization but uses ctx for the request
+func (c *Client) ReauthorizeAuthorizationContext(ctx context.Context, authID string, a *Amount) (*Authorization, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/authorization/%s/reauthorize", c.APIBase, authID), struct {
 		Amount *Amount `json:"amount"`
 	}{a})
 	if err != nil {
@@ -1,12 +1,20 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // https://developer.paypal.com/webapps/developer/docs/api/#captures
 
 // GetCapture returns details about a captured payment
 func (c *Client) GetCapture(captureID string) (*Capture, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/capture/%s", c.APIBase, captureID), nil)
+	return c.GetCaptureContext(context.Background(), captureID)
+}
+
+// GetCaptureContext is like GetCapture but uses ctx for the request
+func (c *Client) GetCaptureContext(ctx context.Context, captureID string) (*Capture, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/capture/%s", c.APIBase, captureID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -24,7 +32,12 @@ func (c *Client) GetCapture(captureID string) (*Capture, error) {
 // RefundCapture refund a captured payment. For partial refunds, a lower
 // Amount object can be passed in.
 func (c *Client) RefundCapture(captureID string, a *Amount) (*Refund, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/capture/%s/refund", c.APIBase, captureID), struct {
+	return c.RefundCaptureContext(context.Background(), captureID, a)
+}
+
+// RefundCaptureContext is like RefundCapture but uses ctx for the request
+func (c *Client) RefundCaptureContext(ctx context.Context, captureID string, a *Amount) (*Refund, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/capture/%s/refund", c.APIBase, captureID), struct {
 		Amount *Amount `json:"amount"`
 	}{a})
 	if err != nil {
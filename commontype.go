@@ -12,4 +12,19 @@ type (
 		Enctype string `json:"enctype"`
 		// Schema HyperSchema `json:"schema"`
 	}
+
+	// Currency maps to currency object
+	Currency struct {
+		Currency string `json:"currency"`
+		Value    string `json:"value"`
+	}
+
+	// Patch maps to patch_request object, one operation of a JSON Patch
+	// document (RFC 6902)
+	Patch struct {
+		Operation string      `json:"op"`
+		Path      string      `json:"path"`
+		Value     interface{} `json:"value,omitempty"`
+		From      string      `json:"from,omitempty"`
+	}
 )
@@ -0,0 +1,63 @@
+package paypal
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	retryPolicyKey
+	responseMetaKey
+)
+
+// WithRequestID returns a copy of ctx carrying id as the PayPal-Request-Id
+// for the call it is used with. Reusing the same id when retrying a failed
+// POST lets Paypal detect the duplicate and return the original result
+// instead of processing it again
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey, id)
+}
+
+// RequestIDFromContext returns the PayPal-Request-Id set with WithRequestID,
+// if any
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(requestIDKey).(string)
+	return id, ok && id != ""
+}
+
+// WithRetryPolicy returns a copy of ctx that makes the call it is used with
+// follow p instead of the Client's RetryPolicy. A nil p disables retries
+// for that call
+func WithRetryPolicy(ctx context.Context, p *RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey, p)
+}
+
+// withoutRequestID returns a copy of ctx that no longer carries the id set
+// with WithRequestID, so that the token requests made on behalf of a call
+// don't reuse the id meant for the call itself
+func withoutRequestID(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestIDKey, "")
+}
+
+func retryPolicyFromContext(ctx context.Context) (*RetryPolicy, bool) {
+	p, ok := ctx.Value(retryPolicyKey).(*RetryPolicy)
+	return p, ok
+}
+
+// newRequestID generates a random UUID (version 4) to be used as
+// PayPal-Request-Id when the caller did not supply one
+func newRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+
+	h := hex.EncodeToString(b)
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:], nil
+}
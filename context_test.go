@@ -0,0 +1,55 @@
+package paypal
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRequestIDNotSentWithTokenRequests(t *testing.T) {
+	Convey("An id set with WithRequestID should only be sent with the call", t, func() {
+		var mu sync.Mutex
+		ids := make(map[string]string)
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			ids[r.URL.Path] = r.Header.Get(RequestIDHeader)
+			mu.Unlock()
+
+			switch r.URL.Path {
+			case "/v1/oauth2/token":
+				writeToken(w, "token")
+			case "/v1/identity/openidconnect/tokenservice":
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"access_token":"user-token","expires_in":3600}`))
+			default:
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"id":"PAY-1"}`))
+			}
+		})
+		ctx := WithRequestID(context.Background(), "caller-id")
+
+		_, err := c.CreatePaymentContext(ctx, Payment{})
+		So(err, ShouldBeNil)
+		_, err = c.ExchangeAuthorizationCodeContext(ctx, "code", "https://example.com/callback")
+		So(err, ShouldBeNil)
+
+		So(ids["/v1/payments/payment"], ShouldEqual, "caller-id")
+		So(ids["/v1/oauth2/token"], ShouldNotBeBlank)
+		So(ids["/v1/oauth2/token"], ShouldNotEqual, "caller-id")
+		So(ids["/v1/identity/openidconnect/tokenservice"], ShouldNotEqual, "caller-id")
+	})
+
+	Convey("A request ID set with WithRequestID should be found in the context", t, func() {
+		id, ok := RequestIDFromContext(WithRequestID(context.Background(), "id"))
+		So(ok, ShouldBeTrue)
+		So(id, ShouldEqual, "id")
+
+		_, ok = RequestIDFromContext(withoutRequestID(WithRequestID(context.Background(), "id")))
+		So(ok, ShouldBeFalse)
+		_, ok = RequestIDFromContext(context.Background())
+		So(ok, ShouldBeFalse)
+	})
+}
@@ -7,7 +7,7 @@ import (
 )
 
 func TestPayment(t *testing.T) {
-	withContext(func(client *Client) {
+	withContext(t, func(client *Client) {
 		Convey("With the payments endpoint", t, func() {
 			Convey("Creating a payment with valid data should be successful", func() {
 				billingAddress := Address{
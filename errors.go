@@ -0,0 +1,154 @@
+package paypal
+
+import (
+	"errors"
+	"net/http"
+)
+
+// Names of the errors returned by the API, found in ErrorResponse.Name
+const (
+	ErrorNameValidation          = "VALIDATION_ERROR"
+	ErrorNameInstrumentDeclined  = "INSTRUMENT_DECLINED"
+	ErrorNameTransactionRefused  = "TRANSACTION_REFUSED"
+	ErrorNameInsufficientFunds   = "INSUFFICIENT_FUNDS"
+	ErrorNameAuthentication      = "AUTHENTICATION_FAILURE"
+	ErrorNamePermissionDenied    = "PERMISSION_DENIED"
+	ErrorNameInvalidResourceID   = "INVALID_RESOURCE_ID"
+	ErrorNameResourceNotFound    = "RESOURCE_NOT_FOUND"
+	ErrorNameRateLimitReached    = "RATE_LIMIT_REACHED"
+	ErrorNameInternalServerError = "INTERNAL_SERVICE_ERROR"
+)
+
+// Sentinel errors an ErrorResponse can be compared to with errors.Is
+var (
+	ErrAuth               = errors.New("paypal: authentication failed")
+	ErrPermission         = errors.New("paypal: permission denied")
+	ErrValidation         = errors.New("paypal: validation error")
+	ErrRateLimited        = errors.New("paypal: rate limited")
+	ErrNotFound           = errors.New("paypal: resource not found")
+	ErrInstrumentDeclined = errors.New("paypal: instrument declined")
+	ErrTransactionRefused = errors.New("paypal: transaction refused")
+	ErrInsufficientFunds  = errors.New("paypal: insufficient funds")
+)
+
+type (
+	// ValidationError is an ErrorResponse named VALIDATION_ERROR
+	ValidationError struct{ *ErrorResponse }
+
+	// InstrumentDeclinedError is an ErrorResponse named INSTRUMENT_DECLINED
+	InstrumentDeclinedError struct{ *ErrorResponse }
+
+	// TransactionRefusedError is an ErrorResponse named TRANSACTION_REFUSED
+	TransactionRefusedError struct{ *ErrorResponse }
+
+	// InsufficientFundsError is an ErrorResponse named INSUFFICIENT_FUNDS
+	InsufficientFundsError struct{ *ErrorResponse }
+)
+
+func (r *ErrorResponse) statusCode() int {
+	if r.Response == nil {
+		return 0
+	}
+	return r.Response.StatusCode
+}
+
+// Is reports whether the response matches one of the sentinel errors of
+// this package, based on its name or HTTP status
+func (r *ErrorResponse) Is(target error) bool {
+	switch target {
+	case ErrAuth:
+		return r.Name == ErrorNameAuthentication || r.statusCode() == http.StatusUnauthorized
+	case ErrPermission:
+		return r.Name == ErrorNamePermissionDenied || r.statusCode() == http.StatusForbidden
+	case ErrValidation:
+		return r.Name == ErrorNameValidation
+	case ErrRateLimited:
+		return r.Name == ErrorNameRateLimitReached || r.statusCode() == http.StatusTooManyRequests
+	case ErrNotFound:
+		return r.Name == ErrorNameInvalidResourceID || r.Name == ErrorNameResourceNotFound ||
+			r.statusCode() == http.StatusNotFound
+	case ErrInstrumentDeclined:
+		return r.Name == ErrorNameInstrumentDeclined
+	case ErrTransactionRefused:
+		return r.Name == ErrorNameTransactionRefused
+	case ErrInsufficientFunds:
+		return r.Name == ErrorNameInsufficientFunds
+	}
+
+	return false
+}
+
+// As lets errors.As convert the response into the typed error matching its
+// name, such as *ValidationError
+func (r *ErrorResponse) As(target interface{}) bool {
+	switch t := target.(type) {
+	case **ValidationError:
+		if r.Name == ErrorNameValidation {
+			*t = &ValidationError{r}
+			return true
+		}
+	case **InstrumentDeclinedError:
+		if r.Name == ErrorNameInstrumentDeclined {
+			*t = &InstrumentDeclinedError{r}
+			return true
+		}
+	case **TransactionRefusedError:
+		if r.Name == ErrorNameTransactionRefused {
+			*t = &TransactionRefusedError{r}
+			return true
+		}
+	case **InsufficientFundsError:
+		if r.Name == ErrorNameInsufficientFunds {
+			*t = &InsufficientFundsError{r}
+			return true
+		}
+	}
+
+	return false
+}
+
+// Unwrap returns the error met while reading or decoding the error body,
+// if any
+func (r *ErrorResponse) Unwrap() error {
+	return r.bodyErr
+}
+
+// IsAuthError reports whether err was caused by invalid credentials or a
+// rejected access token. It does not match requests the credentials are
+// not allowed to make, see IsPermissionError
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrAuth)
+}
+
+// IsPermissionError reports whether err was caused by the app not being
+// allowed to make the request, which new credentials would not fix
+func IsPermissionError(err error) bool {
+	return errors.Is(err, ErrPermission)
+}
+
+// IsValidationError reports whether err was caused by an invalid request
+func IsValidationError(err error) bool {
+	return errors.Is(err, ErrValidation)
+}
+
+// IsRateLimited reports whether err was caused by too many requests
+func IsRateLimited(err error) bool {
+	return errors.Is(err, ErrRateLimited)
+}
+
+// IsNotFound reports whether err was caused by an unknown resource ID
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsInstrumentDeclined reports whether err was caused by the processor or
+// bank declining the funding instrument
+func IsInstrumentDeclined(err error) bool {
+	return errors.Is(err, ErrInstrumentDeclined)
+}
+
+// IsRetryable reports whether the request that caused err can be tried
+// again: transport errors, 429 and 5xx responses
+func IsRetryable(err error) bool {
+	return isRetryable(err)
+}
@@ -0,0 +1,137 @@
+package paypal
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestErrorClassification(t *testing.T) {
+	named := func(name string) error {
+		return &ErrorResponse{Name: name, Response: &http.Response{StatusCode: http.StatusBadRequest}}
+	}
+	status := func(code int) error {
+		return &ErrorResponse{Response: &http.Response{StatusCode: code}}
+	}
+
+	Convey("The Is helpers should classify responses by name and status", t, func() {
+		cases := []struct {
+			name  string
+			is    func(error) bool
+			match []error
+			other []error
+		}{
+			{
+				"IsAuthError", IsAuthError,
+				[]error{named(ErrorNameAuthentication), status(http.StatusUnauthorized)},
+				[]error{status(http.StatusForbidden), named(ErrorNamePermissionDenied), named(ErrorNameValidation)},
+			},
+			{
+				"IsPermissionError", IsPermissionError,
+				[]error{named(ErrorNamePermissionDenied), status(http.StatusForbidden)},
+				[]error{status(http.StatusUnauthorized), named(ErrorNameAuthentication)},
+			},
+			{
+				"IsValidationError", IsValidationError,
+				[]error{named(ErrorNameValidation)},
+				[]error{status(http.StatusBadRequest), named(ErrorNameInstrumentDeclined)},
+			},
+			{
+				"IsRateLimited", IsRateLimited,
+				[]error{named(ErrorNameRateLimitReached), status(http.StatusTooManyRequests)},
+				[]error{status(http.StatusServiceUnavailable)},
+			},
+			{
+				"IsNotFound", IsNotFound,
+				[]error{named(ErrorNameInvalidResourceID), named(ErrorNameResourceNotFound), status(http.StatusNotFound)},
+				[]error{status(http.StatusGone), named(ErrorNameValidation)},
+			},
+			{
+				"IsInstrumentDeclined", IsInstrumentDeclined,
+				[]error{named(ErrorNameInstrumentDeclined)},
+				[]error{named(ErrorNameTransactionRefused)},
+			},
+			{
+				"IsRetryable", IsRetryable,
+				[]error{status(http.StatusTooManyRequests), status(http.StatusBadGateway)},
+				[]error{status(http.StatusBadRequest), named(ErrorNameInternalServerError)},
+			},
+		}
+
+		for _, c := range cases {
+			for _, err := range c.match {
+				So(c.is(err), ShouldBeTrue)
+				So(c.is(fmt.Errorf("wrapped: %w", err)), ShouldBeTrue)
+				So(c.is(&RetryError{Attempts: 2, Err: err}), ShouldBeTrue)
+			}
+			for _, err := range c.other {
+				So(c.is(err), ShouldBeFalse)
+			}
+			So(c.is(nil), ShouldBeFalse)
+			So(c.is(errors.New("boom")), ShouldBeFalse)
+		}
+	})
+
+	Convey("Sentinel errors should match by name without a response", t, func() {
+		So(errors.Is(&ErrorResponse{Name: ErrorNameTransactionRefused}, ErrTransactionRefused), ShouldBeTrue)
+		So(errors.Is(&ErrorResponse{Name: ErrorNameInsufficientFunds}, ErrInsufficientFunds), ShouldBeTrue)
+		So(errors.Is(&ErrorResponse{}, ErrAuth), ShouldBeFalse)
+		So(errors.Is(&ErrorResponse{}, ErrNotFound), ShouldBeFalse)
+	})
+
+	Convey("errors.As should convert responses to the typed error matching their name", t, func() {
+		resp := func(name string) error {
+			return fmt.Errorf("wrapped: %w", &ErrorResponse{Name: name, Message: "message"})
+		}
+
+		var validation *ValidationError
+		So(errors.As(resp(ErrorNameValidation), &validation), ShouldBeTrue)
+		So(validation.Name, ShouldEqual, ErrorNameValidation)
+		So(errors.As(resp(ErrorNameInstrumentDeclined), &validation), ShouldBeFalse)
+
+		var declined *InstrumentDeclinedError
+		So(errors.As(resp(ErrorNameInstrumentDeclined), &declined), ShouldBeTrue)
+		So(declined.Message, ShouldEqual, "message")
+		So(errors.As(resp(ErrorNameValidation), &declined), ShouldBeFalse)
+
+		var refused *TransactionRefusedError
+		So(errors.As(resp(ErrorNameTransactionRefused), &refused), ShouldBeTrue)
+		So(errors.As(resp(ErrorNameInsufficientFunds), &refused), ShouldBeFalse)
+
+		var insufficient *InsufficientFundsError
+		So(errors.As(resp(ErrorNameInsufficientFunds), &insufficient), ShouldBeTrue)
+		So(errors.As(resp(ErrorNameTransactionRefused), &insufficient), ShouldBeFalse)
+
+		var errResp *ErrorResponse
+		So(errors.As(resp(ErrorNameValidation), &errResp), ShouldBeTrue)
+		So(errors.As(&RetryError{Attempts: 3, Err: resp(ErrorNameValidation)}, &validation), ShouldBeTrue)
+	})
+}
+
+func TestErrorResponseError(t *testing.T) {
+	details := []ErrorDetail{{Field: "amount", Issue: "Required field missing"}}
+
+	Convey("Error should describe the response with whatever is known", t, func() {
+		So((&ErrorResponse{Message: "Invalid request", Details: details}).Error(), ShouldEqual,
+			"Invalid request\nDetails: [{amount Required field missing}]")
+
+		resp := &http.Response{StatusCode: http.StatusBadRequest}
+		So((&ErrorResponse{Response: resp, Name: ErrorNameValidation}).Error(), ShouldEqual,
+			"400 VALIDATION_ERROR\nDetails: []")
+
+		resp.Request = &http.Request{Method: "POST", URL: &url.URL{Scheme: "https", Host: "api.paypal.com", Path: "/v1/payments/payment"}}
+		So((&ErrorResponse{Response: resp, ErrorDescription: "Token is expired"}).Error(), ShouldEqual,
+			"POST https://api.paypal.com/v1/payments/payment: 400 Token is expired\nDetails: []")
+	})
+
+	Convey("Unwrap should return the error met while decoding the body", t, func() {
+		bodyErr := errors.New("invalid character")
+
+		So(errors.Is(&ErrorResponse{bodyErr: bodyErr}, bodyErr), ShouldBeTrue)
+		So((&ErrorResponse{}).Unwrap(), ShouldBeNil)
+	})
+}
@@ -0,0 +1,11 @@
+module github.com/leebenson/paypal
+
+go 1.22
+
+require github.com/smartystreets/goconvey v1.6.4
+
+require (
+	github.com/gopherjs/gopherjs v0.0.0-20181017120253-0766667cb4d1 // indirect
+	github.com/jtolds/gls v4.20.0+incompatible // indirect
+	github.com/smartystreets/assertions v1.0.1 // indirect
+)
@@ -0,0 +1,199 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/identity/v1/
+
+// Scopes a user can grant with Log In with PayPal
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopeAddress = "address"
+	ScopePhone   = "phone"
+
+	ScopeAccountInfo = "https://uri.paypal.com/services/paypalattributes"
+)
+
+type (
+	// IdentityToken maps to the tokens issued for a user who logged in
+	// with PayPal. Unlike the token of the Client, it grants access to the
+	// data of that user only
+	IdentityToken struct {
+		Scope        string    `json:"scope"`
+		Token        string    `json:"access_token"`
+		RefreshToken string    `json:"refresh_token,omitempty"`
+		IDToken      string    `json:"id_token,omitempty"`
+		Type         string    `json:"token_type"`
+		ExpiresIn    int       `json:"expires_in"`
+		ExpiresAt    time.Time `json:"expires_at"`
+	}
+
+	// UserInfo maps to userinfo object
+	UserInfo struct {
+		UserID          string       `json:"user_id"`
+		Sub             string       `json:"sub,omitempty"`
+		Name            string       `json:"name,omitempty"`
+		GivenName       string       `json:"given_name,omitempty"`
+		FamilyName      string       `json:"family_name,omitempty"`
+		MiddleName      string       `json:"middle_name,omitempty"`
+		Picture         string       `json:"picture,omitempty"`
+		Email           string       `json:"email,omitempty"`
+		EmailVerified   bool         `json:"email_verified,omitempty"`
+		Gender          string       `json:"gender,omitempty"`
+		Birthdate       string       `json:"birthdate,omitempty"`
+		Zoneinfo        string       `json:"zoneinfo,omitempty"`
+		Locale          string       `json:"locale,omitempty"`
+		PhoneNumber     string       `json:"phone_number,omitempty"`
+		Address         *UserAddress `json:"address,omitempty"`
+		VerifiedAccount string       `json:"verified_account,omitempty"`
+		AccountType     string       `json:"account_type,omitempty"`
+		AgeRange        string       `json:"age_range,omitempty"`
+		PayerID         string       `json:"payer_id,omitempty"`
+	}
+
+	// UserAddress maps to the address object of userinfo
+	UserAddress struct {
+		StreetAddress string `json:"street_address,omitempty"`
+		Locality      string `json:"locality,omitempty"`
+		Region        string `json:"region,omitempty"`
+		PostalCode    string `json:"postal_code,omitempty"`
+		Country       string `json:"country,omitempty"`
+	}
+)
+
+// AuthorizationURL returns the URL to redirect a user to so that they log
+// in with PayPal and grant the scopes. PayPal then redirects them to
+// redirectURI with the state and an authorization code, to exchange with
+// ExchangeAuthorizationCode
+func (c *Client) AuthorizationURL(redirectURI string, scopes []string, state string) string {
+	q := url.Values{}
+	q.Set("flowEntry", "static")
+	q.Set("client_id", c.ClientID)
+	q.Set("response_type", "code")
+	q.Set("scope", strings.Join(scopes, " "))
+	q.Set("redirect_uri", redirectURI)
+	if state != "" {
+		q.Set("state", state)
+	}
+
+	return fmt.Sprintf("%s/connect?%s", c.webBase(), q.Encode())
+}
+
+// LogoutURL returns the URL ending the PayPal session of the user the
+// idToken was issued to, which then redirects them to redirectURI
+func (c *Client) LogoutURL(idToken, redirectURI string) string {
+	q := url.Values{}
+	q.Set("id_token", idToken)
+	q.Set("redirect_uri", redirectURI)
+	q.Set("logout", "true")
+
+	return fmt.Sprintf("%s/webapps/auth/protocol/openidconnect/v1/endsession?%s", c.webBase(), q.Encode())
+}
+
+// webBase returns the base of the PayPal website for the environment of
+// APIBase
+func (c *Client) webBase() string {
+	switch c.APIBase {
+	case APIBaseSandBox:
+		return "https://www.sandbox.paypal.com"
+	case APIBaseLive:
+		return "https://www.paypal.com"
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(c.APIBase, "/"), "/v1")
+}
+
+// ExchangeAuthorizationCode exchanges the authorization code a user was
+// redirected with for their tokens. redirectURI must be the one passed to
+// AuthorizationURL. The access token of the Client is left untouched
+func (c *Client) ExchangeAuthorizationCode(code, redirectURI string) (*IdentityToken, error) {
+	return c.ExchangeAuthorizationCodeContext(context.Background(), code, redirectURI)
+}
+
+// ExchangeAuthorizationCodeContext is like ExchangeAuthorizationCode but uses ctx for the request
+func (c *Client) ExchangeAuthorizationCodeContext(ctx context.Context, code, redirectURI string) (*IdentityToken, error) {
+	q := url.Values{}
+	q.Set("grant_type", "authorization_code")
+	q.Set("code", code)
+	q.Set("redirect_uri", redirectURI)
+
+	return c.identityToken(ctx, q)
+}
+
+// RefreshIdentityToken returns a new access token for the user refreshToken
+// was issued to
+func (c *Client) RefreshIdentityToken(refreshToken string) (*IdentityToken, error) {
+	return c.RefreshIdentityTokenContext(context.Background(), refreshToken)
+}
+
+// RefreshIdentityTokenContext is like RefreshIdentityToken but uses ctx for the request
+func (c *Client) RefreshIdentityTokenContext(ctx context.Context, refreshToken string) (*IdentityToken, error) {
+	q := url.Values{}
+	q.Set("grant_type", "refresh_token")
+	q.Set("refresh_token", refreshToken)
+
+	t, err := c.identityToken(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken
+	}
+
+	return t, nil
+}
+
+// identityToken requests user tokens from the token service with the form
+// values of a grant. Like for GetAccessTokenContext, an id set on ctx with
+// WithRequestID is not sent with the request
+func (c *Client) identityToken(ctx context.Context, grant url.Values) (*IdentityToken, error) {
+	buf := bytes.NewBufferString(grant.Encode())
+	req, err := http.NewRequestWithContext(withoutRequestID(ctx), "POST", fmt.Sprintf("%s/identity/openidconnect/tokenservice", c.APIBase), buf)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(c.ClientID, c.Secret)
+	req.Header.Set("Content-type", "application/x-www-form-urlencoded")
+
+	t := &IdentityToken{}
+	err = c.Send(req, t)
+	if err != nil {
+		return nil, err
+	}
+	t.ExpiresAt = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+
+	return t, nil
+}
+
+// GetUserInfo returns the profile of the user accessToken was issued to,
+// limited to the scopes they granted
+func (c *Client) GetUserInfo(accessToken string) (*UserInfo, error) {
+	return c.GetUserInfoContext(context.Background(), accessToken)
+}
+
+// GetUserInfoContext is like GetUserInfo but uses ctx for the request
+func (c *Client) GetUserInfoContext(ctx context.Context, accessToken string) (*UserInfo, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/identity/openidconnect/userinfo/?schema=openid", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	v := &UserInfo{}
+
+	err = c.Send(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
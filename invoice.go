@@ -0,0 +1,460 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// https://developer.paypal.com/docs/api/invoicing/v1/
+
+var (
+	InvoiceStatusDraft             InvoiceStatus = "DRAFT"
+	InvoiceStatusSent              InvoiceStatus = "SENT"
+	InvoiceStatusScheduled         InvoiceStatus = "SCHEDULED"
+	InvoiceStatusPaid              InvoiceStatus = "PAID"
+	InvoiceStatusMarkedAsPaid      InvoiceStatus = "MARKED_AS_PAID"
+	InvoiceStatusPartiallyPaid     InvoiceStatus = "PARTIALLY_PAID"
+	InvoiceStatusPaymentPending    InvoiceStatus = "PAYMENT_PENDING"
+	InvoiceStatusCancelled         InvoiceStatus = "CANCELLED"
+	InvoiceStatusRefunded          InvoiceStatus = "REFUNDED"
+	InvoiceStatusPartiallyRefunded InvoiceStatus = "PARTIALLY_REFUNDED"
+	InvoiceStatusMarkedAsRefunded  InvoiceStatus = "MARKED_AS_REFUNDED"
+
+	PaymentTermTypeDueOnReceipt       PaymentTermType = "DUE_ON_RECEIPT"
+	PaymentTermTypeDueOnDateSpecified PaymentTermType = "DUE_ON_DATE_SPECIFIED"
+	PaymentTermTypeNet10              PaymentTermType = "NET_10"
+	PaymentTermTypeNet15              PaymentTermType = "NET_15"
+	PaymentTermTypeNet30              PaymentTermType = "NET_30"
+	PaymentTermTypeNet45              PaymentTermType = "NET_45"
+	PaymentTermTypeNet60              PaymentTermType = "NET_60"
+	PaymentTermTypeNet90              PaymentTermType = "NET_90"
+	PaymentTermTypeNoDueDate          PaymentTermType = "NO_DUE_DATE"
+
+	InvoicePaymentMethodBankTransfer InvoicePaymentMethod = "BANK_TRANSFER"
+	InvoicePaymentMethodCash         InvoicePaymentMethod = "CASH"
+	InvoicePaymentMethodCheck        InvoicePaymentMethod = "CHECK"
+	InvoicePaymentMethodCreditCard   InvoicePaymentMethod = "CREDIT_CARD"
+	InvoicePaymentMethodDebitCard    InvoicePaymentMethod = "DEBIT_CARD"
+	InvoicePaymentMethodPaypal       InvoicePaymentMethod = "PAYPAL"
+	InvoicePaymentMethodWireTransfer InvoicePaymentMethod = "WIRE_TRANSFER"
+	InvoicePaymentMethodOther        InvoicePaymentMethod = "OTHER"
+)
+
+type (
+	InvoiceStatus        string
+	PaymentTermType      string
+	InvoicePaymentMethod string
+
+	// Invoice maps to invoice object. Dates are formatted as
+	// "2006-01-02 MST", the time zone being optional
+	Invoice struct {
+		ID                         string                 `json:"id,omitempty"`
+		Number                     string                 `json:"number,omitempty"`
+		TemplateID                 string                 `json:"template_id,omitempty"`
+		URI                        string                 `json:"uri,omitempty"`
+		Status                     InvoiceStatus          `json:"status,omitempty"`
+		MerchantInfo               *MerchantInfo          `json:"merchant_info,omitempty"`
+		BillingInfo                []BillingInfo          `json:"billing_info,omitempty"`
+		CCInfo                     []Participant          `json:"cc_info,omitempty"`
+		ShippingInfo               *ShippingInfo          `json:"shipping_info,omitempty"`
+		Items                      []InvoiceItem          `json:"items,omitempty"`
+		InvoiceDate                string                 `json:"invoice_date,omitempty"`
+		PaymentTerm                *PaymentTerm           `json:"payment_term,omitempty"`
+		Reference                  string                 `json:"reference,omitempty"`
+		Discount                   *Discount              `json:"discount,omitempty"`
+		ShippingCost               *ShippingCost          `json:"shipping_cost,omitempty"`
+		AllowPartialPayment        bool                   `json:"allow_partial_payment,omitempty"`
+		MinimumAmountDue           *Currency              `json:"minimum_amount_due,omitempty"`
+		TaxCalculatedAfterDiscount bool                   `json:"tax_calculated_after_discount,omitempty"`
+		TaxInclusive               bool                   `json:"tax_inclusive,omitempty"`
+		Terms                      string                 `json:"terms,omitempty"`
+		Note                       string                 `json:"note,omitempty"`
+		MerchantMemo               string                 `json:"merchant_memo,omitempty"`
+		LogoURL                    string                 `json:"logo_url,omitempty"`
+		TotalAmount                *Currency              `json:"total_amount,omitempty"`
+		Payments                   []InvoicePaymentDetail `json:"payments,omitempty"`
+		Refunds                    []InvoiceRefundDetail  `json:"refunds,omitempty"`
+		Metadata                   *InvoiceMetadata       `json:"metadata,omitempty"`
+		PaidAmount                 *InvoicePaidAmount     `json:"paid_amount,omitempty"`
+		RefundedAmount             *InvoicePaidAmount     `json:"refunded_amount,omitempty"`
+		Links                      []Links                `json:"links,omitempty"`
+	}
+
+	// InvoiceItem maps to invoice_item object
+	InvoiceItem struct {
+		Name          string      `json:"name"`
+		Description   string      `json:"description,omitempty"`
+		Quantity      float64     `json:"quantity"`
+		UnitPrice     *Currency   `json:"unit_price"`
+		Tax           *InvoiceTax `json:"tax,omitempty"`
+		Date          string      `json:"date,omitempty"`
+		Discount      *Discount   `json:"discount,omitempty"`
+		UnitOfMeasure string      `json:"unit_of_measure,omitempty"`
+		ImageURL      string      `json:"image_url,omitempty"`
+	}
+
+	// MerchantInfo maps to merchant_info object
+	MerchantInfo struct {
+		Email          string        `json:"email,omitempty"`
+		FirstName      string        `json:"first_name,omitempty"`
+		LastName       string        `json:"last_name,omitempty"`
+		BusinessName   string        `json:"business_name,omitempty"`
+		Address        *Address      `json:"address,omitempty"`
+		Phone          *InvoicePhone `json:"phone,omitempty"`
+		Website        string        `json:"website,omitempty"`
+		TaxID          string        `json:"tax_id,omitempty"`
+		AdditionalInfo string        `json:"additional_info,omitempty"`
+	}
+
+	// BillingInfo maps to billing_info object
+	BillingInfo struct {
+		Email          string        `json:"email,omitempty"`
+		FirstName      string        `json:"first_name,omitempty"`
+		LastName       string        `json:"last_name,omitempty"`
+		BusinessName   string        `json:"business_name,omitempty"`
+		Address        *Address      `json:"address,omitempty"`
+		Phone          *InvoicePhone `json:"phone,omitempty"`
+		Language       string        `json:"language,omitempty"`
+		AdditionalInfo string        `json:"additional_info,omitempty"`
+	}
+
+	// ShippingInfo maps to shipping_info object
+	ShippingInfo struct {
+		FirstName    string   `json:"first_name,omitempty"`
+		LastName     string   `json:"last_name,omitempty"`
+		BusinessName string   `json:"business_name,omitempty"`
+		Address      *Address `json:"address,omitempty"`
+	}
+
+	// Participant maps to participant object, someone copied on an invoice
+	Participant struct {
+		Email string `json:"email"`
+	}
+
+	// InvoicePhone maps to the phone object of invoices
+	InvoicePhone struct {
+		CountryCode    string `json:"country_code"`
+		NationalNumber string `json:"national_number"`
+	}
+
+	// PaymentTerm maps to payment_term object. DueDate is only set with
+	// PaymentTermTypeDueOnDateSpecified
+	PaymentTerm struct {
+		TermType PaymentTermType `json:"term_type,omitempty"`
+		DueDate  string          `json:"due_date,omitempty"`
+	}
+
+	// Discount maps to cost object, a discount given either as a
+	// percentage or as an amount
+	Discount struct {
+		Percent float64   `json:"percent,omitempty"`
+		Amount  *Currency `json:"amount,omitempty"`
+	}
+
+	// InvoiceTax maps to the tax object of invoices
+	InvoiceTax struct {
+		ID      string    `json:"id,omitempty"`
+		Name    string    `json:"name"`
+		Percent float64   `json:"percent"`
+		Amount  *Currency `json:"amount,omitempty"`
+	}
+
+	// ShippingCost maps to shipping_cost object
+	ShippingCost struct {
+		Amount *Currency   `json:"amount,omitempty"`
+		Tax    *InvoiceTax `json:"tax,omitempty"`
+	}
+
+	// InvoicePaymentDetail maps to payment_detail object. It records a
+	// payment made outside of Paypal with RecordInvoicePayment
+	InvoicePaymentDetail struct {
+		Type            string               `json:"type,omitempty"`
+		TransactionID   string               `json:"transaction_id,omitempty"`
+		TransactionType string               `json:"transaction_type,omitempty"`
+		Date            string               `json:"date,omitempty"`
+		Method          InvoicePaymentMethod `json:"method,omitempty"`
+		Note            string               `json:"note,omitempty"`
+		Amount          *Currency            `json:"amount,omitempty"`
+	}
+
+	// InvoiceRefundDetail maps to refund_detail object. It records a refund
+	// made outside of Paypal with RecordInvoiceRefund
+	InvoiceRefundDetail struct {
+		Type          string    `json:"type,omitempty"`
+		TransactionID string    `json:"transaction_id,omitempty"`
+		Date          string    `json:"date,omitempty"`
+		Note          string    `json:"note,omitempty"`
+		Amount        *Currency `json:"amount,omitempty"`
+	}
+
+	// InvoicePaidAmount maps to paid_amount object, splitting an amount
+	// between Paypal and other payment methods
+	InvoicePaidAmount struct {
+		Paypal *Currency `json:"paypal,omitempty"`
+		Other  *Currency `json:"other,omitempty"`
+	}
+
+	// InvoiceMetadata maps to metadata object
+	InvoiceMetadata struct {
+		CreatedDate     string `json:"created_date,omitempty"`
+		CreatedBy       string `json:"created_by,omitempty"`
+		CancelledDate   string `json:"cancelled_date,omitempty"`
+		CancelledBy     string `json:"cancelled_by,omitempty"`
+		LastUpdatedDate string `json:"last_updated_date,omitempty"`
+		LastUpdatedBy   string `json:"last_updated_by,omitempty"`
+		FirstSentDate   string `json:"first_sent_date,omitempty"`
+		LastSentDate    string `json:"last_sent_date,omitempty"`
+		LastSentBy      string `json:"last_sent_by,omitempty"`
+		PayerViewURL    string `json:"payer_view_url,omitempty"`
+	}
+
+	// InvoiceNotification maps to notification and cancel_notification
+	// objects, the email sent when reminding of or cancelling an invoice.
+	// SendToPayer only applies to cancellations
+	InvoiceNotification struct {
+		Subject        string   `json:"subject,omitempty"`
+		Note           string   `json:"note,omitempty"`
+		SendToMerchant bool     `json:"send_to_merchant"`
+		SendToPayer    bool     `json:"send_to_payer,omitempty"`
+		CCEmails       []string `json:"cc_emails,omitempty"`
+	}
+
+	// InvoiceSearch maps to search object. Unset fields don't filter the
+	// invoices, and pages start at 0
+	InvoiceSearch struct {
+		Email                 string          `json:"email,omitempty"`
+		RecipientFirstName    string          `json:"recipient_first_name,omitempty"`
+		RecipientLastName     string          `json:"recipient_last_name,omitempty"`
+		RecipientBusinessName string          `json:"recipient_business_name,omitempty"`
+		Number                string          `json:"number,omitempty"`
+		Status                []InvoiceStatus `json:"status,omitempty"`
+		LowerTotalAmount      *Currency       `json:"lower_total_amount,omitempty"`
+		UpperTotalAmount      *Currency       `json:"upper_total_amount,omitempty"`
+		StartInvoiceDate      string          `json:"start_invoice_date,omitempty"`
+		EndInvoiceDate        string          `json:"end_invoice_date,omitempty"`
+		StartDueDate          string          `json:"start_due_date,omitempty"`
+		EndDueDate            string          `json:"end_due_date,omitempty"`
+		StartPaymentDate      string          `json:"start_payment_date,omitempty"`
+		EndPaymentDate        string          `json:"end_payment_date,omitempty"`
+		StartCreationDate     string          `json:"start_creation_date,omitempty"`
+		EndCreationDate       string          `json:"end_creation_date,omitempty"`
+		Page                  int             `json:"page,omitempty"`
+		PageSize              int             `json:"page_size,omitempty"`
+		TotalCountRequired    bool            `json:"total_count_required,omitempty"`
+	}
+
+	// SearchInvoicesResp maps to the invoices object returned by a search.
+	// TotalCount is only set when requested by the search
+	SearchInvoicesResp struct {
+		TotalCount int       `json:"total_count"`
+		Invoices   []Invoice `json:"invoices"`
+		Links      []Links   `json:"links,omitempty"`
+	}
+
+	// invoiceNumber maps to the object holding the next invoice number
+	invoiceNumber struct {
+		Number string `json:"number"`
+	}
+)
+
+// CreateInvoice creates a draft invoice, to be sent with SendInvoice
+func (c *Client) CreateInvoice(i Invoice) (*Invoice, error) {
+	return c.CreateInvoiceContext(context.Background(), i)
+}
+
+// CreateInvoiceContext is like CreateInvoice but uses ctx for the request
+func (c *Client) CreateInvoiceContext(ctx context.Context, i Invoice) (*Invoice, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/invoices", c.APIBase), i)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Invoice{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetInvoice returns an invoice by ID
+func (c *Client) GetInvoice(invoiceID string) (*Invoice, error) {
+	return c.GetInvoiceContext(context.Background(), invoiceID)
+}
+
+// GetInvoiceContext is like GetInvoice but uses ctx for the request
+func (c *Client) GetInvoiceContext(ctx context.Context, invoiceID string) (*Invoice, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/invoicing/invoices/%s", c.APIBase, invoiceID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Invoice{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateInvoice replaces an invoice with i. Fields left unset in i are
+// cleared
+func (c *Client) UpdateInvoice(invoiceID string, i Invoice) (*Invoice, error) {
+	return c.UpdateInvoiceContext(context.Background(), invoiceID, i)
+}
+
+// UpdateInvoiceContext is like UpdateInvoice but uses ctx for the request
+func (c *Client) UpdateInvoiceContext(ctx context.Context, invoiceID string, i Invoice) (*Invoice, error) {
+	req, err := NewRequestContext(ctx, "PUT", fmt.Sprintf("%s/invoicing/invoices/%s", c.APIBase, invoiceID), i)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Invoice{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// DeleteInvoice deletes a draft invoice. Invoices that were sent must be
+// cancelled instead
+func (c *Client) DeleteInvoice(invoiceID string) error {
+	return c.DeleteInvoiceContext(context.Background(), invoiceID)
+}
+
+// DeleteInvoiceContext is like DeleteInvoice but uses ctx for the request
+func (c *Client) DeleteInvoiceContext(ctx context.Context, invoiceID string) error {
+	req, err := NewRequestContext(ctx, "DELETE", fmt.Sprintf("%s/invoicing/invoices/%s", c.APIBase, invoiceID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// SendInvoice sends a draft invoice to its payer by email. The merchant is
+// emailed a copy when notifyMerchant is true
+func (c *Client) SendInvoice(invoiceID string, notifyMerchant bool) error {
+	return c.SendInvoiceContext(context.Background(), invoiceID, notifyMerchant)
+}
+
+// SendInvoiceContext is like SendInvoice but uses ctx for the request
+func (c *Client) SendInvoiceContext(ctx context.Context, invoiceID string, notifyMerchant bool) error {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/invoices/%s/send", c.APIBase, invoiceID), nil)
+	if err != nil {
+		return err
+	}
+
+	q := req.URL.Query()
+	q.Set("notify_merchant", strconv.FormatBool(notifyMerchant))
+	req.URL.RawQuery = q.Encode()
+
+	return c.SendWithAuth(req, nil)
+}
+
+// RemindInvoice emails the payer of a sent invoice a reminder to pay it
+func (c *Client) RemindInvoice(invoiceID string, n InvoiceNotification) error {
+	return c.RemindInvoiceContext(context.Background(), invoiceID, n)
+}
+
+// RemindInvoiceContext is like RemindInvoice but uses ctx for the request
+func (c *Client) RemindInvoiceContext(ctx context.Context, invoiceID string, n InvoiceNotification) error {
+	return c.invoiceAction(ctx, invoiceID, "remind", n)
+}
+
+// CancelInvoice cancels a sent invoice, so that it can no longer be paid
+func (c *Client) CancelInvoice(invoiceID string, n InvoiceNotification) error {
+	return c.CancelInvoiceContext(context.Background(), invoiceID, n)
+}
+
+// CancelInvoiceContext is like CancelInvoice but uses ctx for the request
+func (c *Client) CancelInvoiceContext(ctx context.Context, invoiceID string, n InvoiceNotification) error {
+	return c.invoiceAction(ctx, invoiceID, "cancel", n)
+}
+
+// RecordInvoicePayment marks an invoice as paid, in full or in part, by a
+// payment made outside of Paypal such as cash or a check
+func (c *Client) RecordInvoicePayment(invoiceID string, p InvoicePaymentDetail) error {
+	return c.RecordInvoicePaymentContext(context.Background(), invoiceID, p)
+}
+
+// RecordInvoicePaymentContext is like RecordInvoicePayment but uses ctx for the request
+func (c *Client) RecordInvoicePaymentContext(ctx context.Context, invoiceID string, p InvoicePaymentDetail) error {
+	return c.invoiceAction(ctx, invoiceID, "record-payment", p)
+}
+
+// RecordInvoiceRefund marks an invoice as refunded, in full or in part, by
+// a refund made outside of Paypal
+func (c *Client) RecordInvoiceRefund(invoiceID string, r InvoiceRefundDetail) error {
+	return c.RecordInvoiceRefundContext(context.Background(), invoiceID, r)
+}
+
+// RecordInvoiceRefundContext is like RecordInvoiceRefund but uses ctx for the request
+func (c *Client) RecordInvoiceRefundContext(ctx context.Context, invoiceID string, r InvoiceRefundDetail) error {
+	return c.invoiceAction(ctx, invoiceID, "record-refund", r)
+}
+
+// SearchInvoices returns the invoices matching s
+func (c *Client) SearchInvoices(s InvoiceSearch) (*SearchInvoicesResp, error) {
+	return c.SearchInvoicesContext(context.Background(), s)
+}
+
+// SearchInvoicesContext is like SearchInvoices but uses ctx for the request
+func (c *Client) SearchInvoicesContext(ctx context.Context, s InvoiceSearch) (*SearchInvoicesResp, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/search", c.APIBase), s)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &SearchInvoicesResp{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GenerateInvoiceNumber returns the next invoice number available to the
+// merchant
+func (c *Client) GenerateInvoiceNumber() (string, error) {
+	return c.GenerateInvoiceNumberContext(context.Background())
+}
+
+// GenerateInvoiceNumberContext is like GenerateInvoiceNumber but uses ctx for the request
+func (c *Client) GenerateInvoiceNumberContext(ctx context.Context) (string, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/invoices/next-invoice-number", c.APIBase), nil)
+	if err != nil {
+		return "", err
+	}
+
+	var v invoiceNumber
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return "", err
+	}
+
+	return v.Number, nil
+}
+
+// invoiceAction posts payload to one of the action endpoints of an
+// invoice, which respond with no content
+func (c *Client) invoiceAction(ctx context.Context, invoiceID, action string, payload interface{}) error {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/invoices/%s/%s", c.APIBase, invoiceID, action), payload)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
@@ -0,0 +1,173 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+)
+
+// https://developer.paypal.com/docs/api/invoicing/v1/#templates
+
+type (
+	// InvoiceTemplate maps to template object. Invoices created with its
+	// TemplateID start from its TemplateData
+	InvoiceTemplate struct {
+		TemplateID    string                   `json:"template_id,omitempty"`
+		Name          string                   `json:"name,omitempty"`
+		Default       bool                     `json:"default"`
+		TemplateData  *InvoiceTemplateData     `json:"template_data,omitempty"`
+		Settings      []InvoiceTemplateSetting `json:"settings,omitempty"`
+		UnitOfMeasure string                   `json:"unit_of_measure,omitempty"`
+		Custom        bool                     `json:"custom,omitempty"`
+		Links         []Links                  `json:"links,omitempty"`
+	}
+
+	// InvoiceTemplateData maps to template_data object, the invoice fields
+	// a template fills in
+	InvoiceTemplateData struct {
+		MerchantInfo               *MerchantInfo `json:"merchant_info,omitempty"`
+		BillingInfo                []BillingInfo `json:"billing_info,omitempty"`
+		CCInfo                     []Participant `json:"cc_info,omitempty"`
+		ShippingInfo               *ShippingInfo `json:"shipping_info,omitempty"`
+		Items                      []InvoiceItem `json:"items,omitempty"`
+		PaymentTerm                *PaymentTerm  `json:"payment_term,omitempty"`
+		Reference                  string        `json:"reference,omitempty"`
+		Discount                   *Discount     `json:"discount,omitempty"`
+		ShippingCost               *ShippingCost `json:"shipping_cost,omitempty"`
+		AllowPartialPayment        bool          `json:"allow_partial_payment,omitempty"`
+		MinimumAmountDue           *Currency     `json:"minimum_amount_due,omitempty"`
+		TaxCalculatedAfterDiscount bool          `json:"tax_calculated_after_discount,omitempty"`
+		TaxInclusive               bool          `json:"tax_inclusive,omitempty"`
+		Terms                      string        `json:"terms,omitempty"`
+		Note                       string        `json:"note,omitempty"`
+		MerchantMemo               string        `json:"merchant_memo,omitempty"`
+		LogoURL                    string        `json:"logo_url,omitempty"`
+		TotalAmount                *Currency     `json:"total_amount,omitempty"`
+	}
+
+	// InvoiceTemplateSetting maps to template_settings object, whether a
+	// field of the template is shown on invoices
+	InvoiceTemplateSetting struct {
+		FieldName         string                            `json:"field_name"`
+		DisplayPreference *InvoiceTemplateDisplayPreference `json:"display_preference,omitempty"`
+	}
+
+	// InvoiceTemplateDisplayPreference maps to
+	// template_settings_metadata object
+	InvoiceTemplateDisplayPreference struct {
+		Hidden bool `json:"hidden"`
+	}
+
+	// ListInvoiceTemplatesResp maps to templates object, holding the
+	// templates of the merchant along with their contact details
+	ListInvoiceTemplatesResp struct {
+		Addresses []Address         `json:"addresses,omitempty"`
+		Emails    []string          `json:"emails,omitempty"`
+		Phones    []InvoicePhone    `json:"phones,omitempty"`
+		Templates []InvoiceTemplate `json:"templates"`
+		Links     []Links           `json:"links,omitempty"`
+	}
+)
+
+// CreateInvoiceTemplate creates an invoice template
+func (c *Client) CreateInvoiceTemplate(t InvoiceTemplate) (*InvoiceTemplate, error) {
+	return c.CreateInvoiceTemplateContext(context.Background(), t)
+}
+
+// CreateInvoiceTemplateContext is like CreateInvoiceTemplate but uses ctx for the request
+func (c *Client) CreateInvoiceTemplateContext(ctx context.Context, t InvoiceTemplate) (*InvoiceTemplate, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/invoicing/templates", c.APIBase), t)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &InvoiceTemplate{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListInvoiceTemplates returns the invoice templates of the merchant, with
+// their data
+func (c *Client) ListInvoiceTemplates() (*ListInvoiceTemplatesResp, error) {
+	return c.ListInvoiceTemplatesContext(context.Background())
+}
+
+// ListInvoiceTemplatesContext is like ListInvoiceTemplates but uses ctx for the request
+func (c *Client) ListInvoiceTemplatesContext(ctx context.Context) (*ListInvoiceTemplatesResp, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/invoicing/templates?fields=all", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &ListInvoiceTemplatesResp{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetInvoiceTemplate returns an invoice template by ID
+func (c *Client) GetInvoiceTemplate(templateID string) (*InvoiceTemplate, error) {
+	return c.GetInvoiceTemplateContext(context.Background(), templateID)
+}
+
+// GetInvoiceTemplateContext is like GetInvoiceTemplate but uses ctx for the request
+func (c *Client) GetInvoiceTemplateContext(ctx context.Context, templateID string) (*InvoiceTemplate, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/invoicing/templates/%s", c.APIBase, templateID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &InvoiceTemplate{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateInvoiceTemplate replaces an invoice template with t
+func (c *Client) UpdateInvoiceTemplate(templateID string, t InvoiceTemplate) (*InvoiceTemplate, error) {
+	return c.UpdateInvoiceTemplateContext(context.Background(), templateID, t)
+}
+
+// UpdateInvoiceTemplateContext is like UpdateInvoiceTemplate but uses ctx for the request
+func (c *Client) UpdateInvoiceTemplateContext(ctx context.Context, templateID string, t InvoiceTemplate) (*InvoiceTemplate, error) {
+	req, err := NewRequestContext(ctx, "PUT", fmt.Sprintf("%s/invoicing/templates/%s", c.APIBase, templateID), t)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &InvoiceTemplate{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// DeleteInvoiceTemplate deletes an invoice template
+func (c *Client) DeleteInvoiceTemplate(templateID string) error {
+	return c.DeleteInvoiceTemplateContext(context.Background(), templateID)
+}
+
+// DeleteInvoiceTemplateContext is like DeleteInvoiceTemplate but uses ctx for the request
+func (c *Client) DeleteInvoiceTemplateContext(ctx context.Context, templateID string) error {
+	req, err := NewRequestContext(ctx, "DELETE", fmt.Sprintf("%s/invoicing/templates/%s", c.APIBase, templateID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
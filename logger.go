@@ -0,0 +1,120 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// Logger receives a record for each request made by a Client. Records carry
+// the method, path, status, latency, Paypal-Debug-Id and attempt number as
+// key-value pairs. *slog.Logger satisfies this interface
+type Logger interface {
+	Log(ctx context.Context, level slog.Level, msg string, args ...interface{})
+}
+
+var _ Logger = (*slog.Logger)(nil)
+
+// DebugIDHeader is the response header holding the id Paypal support
+// needs to look up a request
+const DebugIDHeader = "Paypal-Debug-Id"
+
+// WithLogger sets the logger requests are reported to. Logging is disabled
+// by default
+func WithLogger(l Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = l
+	}
+}
+
+// WithDebug makes the Client log the body of every request and response at
+// slog.LevelDebug, after passing them through Redact. Bodies written to an
+// io.Writer passed to Send are not logged
+func WithDebug(debug bool) ClientOption {
+	return func(c *Client) {
+		c.debug = debug
+	}
+}
+
+func (c *Client) log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	if c.logger != nil {
+		c.logger.Log(ctx, level, msg, args...)
+	}
+}
+
+// logRequest logs the outcome of a single attempt at req. resp is nil when
+// the request failed before a response was received
+func (c *Client) logRequest(req *http.Request, resp *http.Response, attempt int, start time.Time, err error) {
+	if c.logger == nil {
+		return
+	}
+
+	args := []interface{}{
+		"method", req.Method,
+		"path", req.URL.Path,
+		"attempt", attempt,
+		"latency", time.Since(start),
+	}
+
+	if resp == nil {
+		c.log(req.Context(), slog.LevelError, "paypal: request failed", append(args, "error", err)...)
+		return
+	}
+
+	args = append(args, "status", resp.StatusCode, "debug_id", resp.Header.Get(DebugIDHeader))
+	level := slog.LevelInfo
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	c.log(req.Context(), level, "paypal: request", args...)
+}
+
+// dumpRequest logs the body of req in debug mode, without consuming it
+func (c *Client) dumpRequest(req *http.Request, attempt int) {
+	if !c.debug || c.logger == nil {
+		return
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		if rc, err := req.GetBody(); err == nil {
+			body, _ = ioutil.ReadAll(rc)
+			rc.Close()
+		}
+	}
+
+	c.log(req.Context(), slog.LevelDebug, "paypal: request body",
+		"method", req.Method, "path", req.URL.Path, "attempt", attempt, "body", string(Redact(body)))
+}
+
+// dumpResponse logs the body of resp in debug mode. The body is buffered
+// so that it can still be read afterwards
+func (c *Client) dumpResponse(req *http.Request, resp *http.Response, attempt int) {
+	if !c.debug || c.logger == nil {
+		return
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), errReader{err}))
+
+	c.log(req.Context(), slog.LevelDebug, "paypal: response body",
+		"method", req.Method, "path", req.URL.Path, "attempt", attempt,
+		"status", resp.StatusCode, "body", string(Redact(body)))
+}
+
+// errReader replays the error met while buffering a response body
+type errReader struct {
+	err error
+}
+
+func (r errReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	return 0, io.EOF
+}
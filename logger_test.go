@@ -0,0 +1,178 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// logRecord is a record received by a recordingLogger
+type logRecord struct {
+	level slog.Level
+	msg   string
+	attrs map[string]interface{}
+}
+
+// recordingLogger is a Logger keeping the records it receives
+type recordingLogger struct {
+	mu      sync.Mutex
+	records []logRecord
+}
+
+func (l *recordingLogger) Log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	r := logRecord{level: level, msg: msg, attrs: make(map[string]interface{})}
+	for i := 0; i+1 < len(args); i += 2 {
+		r.attrs[fmt.Sprint(args[i])] = args[i+1]
+	}
+
+	l.mu.Lock()
+	l.records = append(l.records, r)
+	l.mu.Unlock()
+}
+
+// find returns the records with the given message
+func (l *recordingLogger) find(msg string) []logRecord {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var found []logRecord
+	for _, r := range l.records {
+		if r.msg == msg {
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+func TestLogger(t *testing.T) {
+	Convey("Each request should be logged with its outcome", t, func() {
+		logger := &recordingLogger{}
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DebugIDHeader, "debug-"+r.Method)
+			if r.Method == "DELETE" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}, WithLogger(logger))
+
+		req, _ := NewRequest("GET", c.APIBase+"/payments/payment", nil)
+		So(c.Send(req, nil), ShouldBeNil)
+		req, _ = NewRequest("DELETE", c.APIBase+"/vault/credit-card/CARD-1", nil)
+		So(c.Send(req, nil), ShouldNotBeNil)
+
+		records := logger.find("paypal: request")
+		So(records, ShouldHaveLength, 2)
+
+		So(records[0].level, ShouldEqual, slog.LevelInfo)
+		So(records[0].attrs["method"], ShouldEqual, "GET")
+		So(records[0].attrs["path"], ShouldEqual, "/v1/payments/payment")
+		So(records[0].attrs["status"], ShouldEqual, http.StatusOK)
+		So(records[0].attrs["attempt"], ShouldEqual, 1)
+		So(records[0].attrs["debug_id"], ShouldEqual, "debug-GET")
+		So(records[0].attrs["latency"], ShouldHaveSameTypeAs, time.Duration(0))
+
+		So(records[1].level, ShouldEqual, slog.LevelWarn)
+		So(records[1].attrs["method"], ShouldEqual, "DELETE")
+		So(records[1].attrs["status"], ShouldEqual, http.StatusNotFound)
+		So(records[1].attrs["debug_id"], ShouldEqual, "debug-DELETE")
+
+		So(logger.find("paypal: request body"), ShouldBeEmpty)
+		So(logger.find("paypal: response body"), ShouldBeEmpty)
+	})
+
+	Convey("Retried requests should be logged with their attempt number", t, func() {
+		logger := &recordingLogger{}
+		var mu sync.Mutex
+		calls := 0
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			calls++
+			n := calls
+			mu.Unlock()
+			if n == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{}`))
+		}, WithLogger(logger))
+		c.RetryPolicy = &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+
+		req, _ := NewRequest("GET", c.APIBase+"/payments/payment", nil)
+		So(c.Send(req, nil), ShouldBeNil)
+
+		records := logger.find("paypal: request")
+		So(records, ShouldHaveLength, 2)
+		So(records[0].attrs["attempt"], ShouldEqual, 1)
+		So(records[0].attrs["status"], ShouldEqual, http.StatusServiceUnavailable)
+		So(records[1].attrs["attempt"], ShouldEqual, 2)
+		So(records[1].attrs["status"], ShouldEqual, http.StatusOK)
+	})
+
+	Convey("A request that got no response should be logged as an error", t, func() {
+		logger := &recordingLogger{}
+		c := NewClient("id", "secret", "http://127.0.0.1:1/v1", WithLogger(logger))
+
+		req, _ := NewRequest("GET", c.APIBase+"/payments/payment", nil)
+		So(c.Send(req, nil), ShouldNotBeNil)
+
+		records := logger.find("paypal: request failed")
+		So(records, ShouldHaveLength, 1)
+		So(records[0].level, ShouldEqual, slog.LevelError)
+		So(records[0].attrs["path"], ShouldEqual, "/v1/payments/payment")
+		So(records[0].attrs["error"], ShouldNotBeNil)
+	})
+
+	Convey("WithDebug should log redacted bodies and leave the response readable", t, func() {
+		logger := &recordingLogger{}
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"id":"CARD-1","number":"4417119669820331","cvv2":"874","type":"visa"}`))
+		}, WithLogger(logger), WithDebug(true))
+
+		card := CreditCard{Number: "4417119669820331", CVV2: "874", Type: "visa", ExpireMonth: "11", ExpireYear: "2030"}
+		req, _ := NewRequest("POST", c.APIBase+"/vault/credit-card", card)
+		got := &CreditCard{}
+		So(c.Send(req, got), ShouldBeNil)
+		So(got.ID, ShouldEqual, "CARD-1")
+		So(got.Number, ShouldEqual, "4417119669820331")
+
+		requests := logger.find("paypal: request body")
+		So(requests, ShouldHaveLength, 1)
+		So(requests[0].level, ShouldEqual, slog.LevelDebug)
+		So(requests[0].attrs["method"], ShouldEqual, "POST")
+		So(requests[0].attrs["attempt"], ShouldEqual, 1)
+		body := requests[0].attrs["body"].(string)
+		So(body, ShouldContainSubstring, `"number":"************0331"`)
+		So(body, ShouldContainSubstring, `"cvv2":"[REDACTED]"`)
+
+		responses := logger.find("paypal: response body")
+		So(responses, ShouldHaveLength, 1)
+		So(responses[0].attrs["status"], ShouldEqual, http.StatusOK)
+		body = responses[0].attrs["body"].(string)
+		So(body, ShouldContainSubstring, `"id":"CARD-1"`)
+		So(body, ShouldNotContainSubstring, "4417119669820331")
+		So(body, ShouldNotContainSubstring, "874")
+	})
+
+	Convey("WithDebug should redact the token request and response", t, func() {
+		logger := &recordingLogger{}
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			writeToken(w, "A21AAF-secret-token")
+		}, WithLogger(logger), WithDebug(true))
+
+		_, err := c.GetAccessToken()
+		So(err, ShouldBeNil)
+
+		for _, r := range append(logger.find("paypal: request body"), logger.find("paypal: response body")...) {
+			So(strings.Contains(r.attrs["body"].(string), "A21AAF-secret-token"), ShouldBeFalse)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package paypal
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ResponseMeta describes the HTTP exchange behind a call. Pass one to
+// WithResponseMeta to have it filled in, whether the call succeeds or not
+type ResponseMeta struct {
+	StatusCode int
+	Header     http.Header
+
+	// DebugID is the Paypal-Debug-Id Paypal support asks for
+	DebugID string
+
+	// RequestID is the PayPal-Request-Id sent with the request, if any
+	RequestID string
+
+	// Latency is the duration of the last attempt
+	Latency time.Duration
+
+	// Attempts is the number of times the request was sent
+	Attempts int
+}
+
+// WithResponseMeta returns a copy of ctx that makes the call it is used with
+// record the metadata of its response into meta. When the call needs a
+// new access token first, meta describes the main request, not the token
+// request
+func WithResponseMeta(ctx context.Context, meta *ResponseMeta) context.Context {
+	return context.WithValue(ctx, responseMetaKey, meta)
+}
+
+func responseMetaFromContext(ctx context.Context) *ResponseMeta {
+	meta, _ := ctx.Value(responseMetaKey).(*ResponseMeta)
+	return meta
+}
+
+// recordResponse fills in the ResponseMeta attached to the request's
+// context, if any. resp is nil when no response was received
+func recordResponse(req *http.Request, resp *http.Response, attempt int, start time.Time) {
+	meta := responseMetaFromContext(req.Context())
+	if meta == nil {
+		return
+	}
+
+	*meta = ResponseMeta{
+		RequestID: req.Header.Get(RequestIDHeader),
+		Latency:   time.Since(start),
+		Attempts:  attempt,
+	}
+	if resp != nil {
+		meta.StatusCode = resp.StatusCode
+		meta.Header = resp.Header
+		meta.DebugID = resp.Header.Get(DebugIDHeader)
+	}
+}
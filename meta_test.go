@@ -0,0 +1,135 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestResponseMeta(t *testing.T) {
+	Convey("ResponseMeta should describe the call, not the token request made for it", t, func() {
+		var calls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				w.Header().Set(DebugIDHeader, "token-debug-id")
+				writeToken(w, "token")
+				return
+			}
+			w.Header().Set(DebugIDHeader, "call-debug-id")
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"id":"PAY-1"}`))
+		})
+		c.RetryPolicy = &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+		meta := &ResponseMeta{}
+		ctx := WithResponseMeta(WithRequestID(context.Background(), "request-id"), meta)
+		_, err := c.CreatePaymentContext(ctx, Payment{})
+
+		So(err, ShouldBeNil)
+		So(meta.StatusCode, ShouldEqual, http.StatusCreated)
+		So(meta.DebugID, ShouldEqual, "call-debug-id")
+		So(meta.RequestID, ShouldEqual, "request-id")
+		So(meta.Attempts, ShouldEqual, 2)
+		So(meta.Latency, ShouldBeGreaterThan, 0)
+		So(meta.Header.Get(DebugIDHeader), ShouldEqual, "call-debug-id")
+	})
+
+	Convey("ResponseMeta should not describe a token request that failed after the call", t, func() {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				w.Header().Set(DebugIDHeader, "token-debug-id")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set(DebugIDHeader, "call-debug-id")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"error":"invalid_token","error_description":"Token is expired"}`))
+		})
+		c.Token = &TokenResp{Token: "expired", ExpiresAt: time.Now().Add(time.Hour)}
+
+		meta := &ResponseMeta{}
+		_, err := c.GetPaymentContext(WithResponseMeta(context.Background(), meta), "PAY-1")
+
+		So(err, ShouldNotBeNil)
+		So(meta.StatusCode, ShouldEqual, http.StatusUnauthorized)
+		So(meta.DebugID, ShouldEqual, "call-debug-id")
+	})
+
+	Convey("ResponseMeta should be left empty when the token request fails before the call", t, func() {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DebugIDHeader, "token-debug-id")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		})
+
+		meta := &ResponseMeta{}
+		_, err := c.GetPaymentContext(WithResponseMeta(context.Background(), meta), "PAY-1")
+
+		So(err, ShouldNotBeNil)
+		So(*meta, ShouldResemble, ResponseMeta{})
+	})
+
+	Convey("ResponseMeta should be filled in when the call fails", t, func() {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				writeToken(w, "token")
+				return
+			}
+			w.Header().Set(DebugIDHeader, "call-debug-id")
+			w.WriteHeader(http.StatusNotFound)
+		})
+
+		meta := &ResponseMeta{}
+		_, err := c.GetPaymentContext(WithResponseMeta(context.Background(), meta), "PAY-1")
+
+		So(IsNotFound(err), ShouldBeTrue)
+		So(meta.StatusCode, ShouldEqual, http.StatusNotFound)
+		So(meta.DebugID, ShouldEqual, "call-debug-id")
+		So(meta.Attempts, ShouldEqual, 1)
+	})
+
+	Convey("ResponseMeta should report a request that got no response", t, func() {
+		c := NewClient("id", "secret", "http://127.0.0.1:1/v1")
+		c.Token = &TokenResp{Token: "token", ExpiresAt: time.Now().Add(time.Hour)}
+
+		meta := &ResponseMeta{StatusCode: http.StatusOK}
+		_, err := c.GetPaymentContext(WithResponseMeta(context.Background(), meta), "PAY-1")
+
+		So(err, ShouldNotBeNil)
+		So(meta.StatusCode, ShouldEqual, 0)
+		So(meta.Attempts, ShouldEqual, 1)
+	})
+}
+
+func TestErrorResponseDebugID(t *testing.T) {
+	Convey("ErrorResponse.DebugID should fall back to the Paypal-Debug-Id header", t, func() {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set(DebugIDHeader, "header-debug-id")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			if r.URL.Query().Get("with_body_id") != "" {
+				w.Write([]byte(`{"name":"VALIDATION_ERROR","debug_id":"body-debug-id"}`))
+				return
+			}
+			w.Write([]byte(`{"name":"VALIDATION_ERROR"}`))
+		})
+
+		var errResp *ErrorResponse
+		req, _ := NewRequest("GET", c.APIBase+"/payments/payment", nil)
+		So(errors.As(c.Send(req, nil), &errResp), ShouldBeTrue)
+		So(errResp.DebugID, ShouldEqual, "header-debug-id")
+
+		req, _ = NewRequest("GET", c.APIBase+"/payments/payment?with_body_id=1", nil)
+		So(errors.As(c.Send(req, nil), &errResp), ShouldBeTrue)
+		So(errResp.DebugID, ShouldEqual, "body-debug-id")
+	})
+}
@@ -0,0 +1,42 @@
+package paypal
+
+import (
+	"net/http"
+	"time"
+)
+
+// ClientOption configures a Client created with NewClient
+type ClientOption func(*Client)
+
+// WithHTTPClient makes the Client send its requests with hc, which allows
+// setting custom transports, proxies or TLS settings
+func WithHTTPClient(hc *http.Client) ClientOption {
+	return func(c *Client) {
+		c.client = hc
+	}
+}
+
+// WithTimeout sets the time limit for each request made by the Client,
+// including reading the response body. It applies whether it is passed
+// before or after WithHTTPClient, to a copy of the http.Client so that the
+// caller's one is not modified
+func WithTimeout(d time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = d
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request
+func WithUserAgent(ua string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = ua
+	}
+}
+
+// WithAcceptLanguage sets the Accept-Language header sent with each
+// request. Defaults to en_US
+func WithAcceptLanguage(lang string) ClientOption {
+	return func(c *Client) {
+		c.acceptLanguage = lang
+	}
+}
@@ -0,0 +1,58 @@
+package paypal
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptions(t *testing.T) {
+	Convey("WithTimeout should apply whether passed before or after WithHTTPClient", t, func() {
+		transport := &http.Transport{}
+		hc := &http.Client{Transport: transport}
+
+		before := NewClient("id", "secret", APIBaseSandBox, WithTimeout(time.Second), WithHTTPClient(hc))
+		after := NewClient("id", "secret", APIBaseSandBox, WithHTTPClient(hc), WithTimeout(time.Second))
+
+		for _, c := range []*Client{before, after} {
+			So(c.client.Timeout, ShouldEqual, time.Second)
+			So(c.client.Transport, ShouldEqual, transport)
+			So(c.client, ShouldNotPointTo, hc)
+		}
+		So(hc.Timeout, ShouldEqual, 0)
+	})
+
+	Convey("Without WithTimeout, the http.Client passed should be used as is", t, func() {
+		hc := &http.Client{Timeout: time.Minute}
+
+		c := NewClient("id", "secret", APIBaseSandBox, WithHTTPClient(hc))
+
+		So(c.client, ShouldPointTo, hc)
+	})
+
+	Convey("WithTimeout alone should set the timeout of the default http.Client", t, func() {
+		So(NewClient("id", "secret", APIBaseSandBox).client.Timeout, ShouldEqual, 0)
+		So(NewClient("id", "secret", APIBaseSandBox, WithTimeout(time.Second)).client.Timeout, ShouldEqual, time.Second)
+	})
+
+	Convey("Requests should carry the User-Agent and Accept-Language headers", t, func() {
+		var headers []http.Header
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			headers = append(headers, r.Header.Clone())
+			writeToken(w, "token")
+		}
+
+		_, err := newOfflineClient(t, handler).GetAccessToken()
+		So(err, ShouldBeNil)
+		_, err = newOfflineClient(t, handler, WithUserAgent("shop/1.0"), WithAcceptLanguage("fr_FR")).GetAccessToken()
+		So(err, ShouldBeNil)
+
+		So(headers, ShouldHaveLength, 2)
+		So(headers[0].Get("Accept-Language"), ShouldEqual, "en_US")
+		So(headers[0].Get("User-Agent"), ShouldStartWith, "Go-http-client/")
+		So(headers[1].Get("Accept-Language"), ShouldEqual, "fr_FR")
+		So(headers[1].Get("User-Agent"), ShouldEqual, "shop/1.0")
+	})
+}
@@ -0,0 +1,129 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+)
+
+// https://developer.paypal.com/webapps/developer/docs/api/#orders
+
+// GetOrder returns an order by ID
+func (c *Client) GetOrder(orderID string) (*Order, error) {
+	return c.GetOrderContext(context.Background(), orderID)
+}
+
+// GetOrderContext is like GetOrder but uses ctx for the request
+func (c *Client) GetOrderContext(ctx context.Context, orderID string) (*Order, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/orders/%s", c.APIBase, orderID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Order{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// AuthorizeOrder authorizes an order, so that it can be captured later.
+// To use this method, the original payment must have Intent set to PaymentIntentOrder
+func (c *Client) AuthorizeOrder(orderID string, a *Amount) (*Authorization, error) {
+	return c.AuthorizeOrderContext(context.Background(), orderID, a)
+}
+
+// AuthorizeOrderContext is like AuthorizeOrder but uses ctx for the request
+func (c *Client) AuthorizeOrderContext(ctx context.Context, orderID string, a *Amount) (*Authorization, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/orders/%s/authorize", c.APIBase, orderID), struct {
+		Amount *Amount `json:"amount"`
+	}{a})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Authorization{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// CaptureOrder captures a payment for an order. Set isFinalCapture to
+// complete the order once captured
+func (c *Client) CaptureOrder(orderID string, a *Amount, isFinalCapture bool) (*Capture, error) {
+	return c.CaptureOrderContext(context.Background(), orderID, a, isFinalCapture)
+}
+
+// CaptureOrderContext is like CaptureOrder but uses ctx for the request
+func (c *Client) CaptureOrderContext(ctx context.Context, orderID string, a *Amount, isFinalCapture bool) (*Capture, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/orders/%s/capture", c.APIBase, orderID), struct {
+		Amount         *Amount `json:"amount"`
+		IsFinalCapture bool    `json:"is_final_capture"`
+	}{a, isFinalCapture})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Capture{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// VoidOrder voids an existing order. An order cannot be voided once it
+// has been completed
+func (c *Client) VoidOrder(orderID string) (*Order, error) {
+	return c.VoidOrderContext(context.Background(), orderID)
+}
+
+// VoidOrderContext is like VoidOrder but uses ctx for the request
+func (c *Client) VoidOrderContext(ctx context.Context, orderID string) (*Order, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/orders/%s/do-void", c.APIBase, orderID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Order{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// RefundOrder refunds a captured order. For partial refunds, a lower
+// Amount object can be passed in
+func (c *Client) RefundOrder(orderID string, a *Amount) (*Refund, error) {
+	return c.RefundOrderContext(context.Background(), orderID, a)
+}
+
+// RefundOrderContext is like RefundOrder but uses ctx for the request
+func (c *Client) RefundOrderContext(ctx context.Context, orderID string, a *Amount) (*Refund, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/orders/%s/refund", c.APIBase, orderID), struct {
+		Amount *Amount `json:"amount"`
+	}{a})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Refund{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
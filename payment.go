@@ -1,6 +1,9 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 type (
 	CreatePaymentResp struct {
@@ -22,7 +25,12 @@ type (
 
 // CreatePayment creates a payment in Paypal
 func (c *Client) CreatePayment(p Payment) (*CreatePaymentResp, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/payment", c.APIBase), p)
+	return c.CreatePaymentContext(context.Background(), p)
+}
+
+// CreatePaymentContext is like CreatePayment but uses ctx for the request
+func (c *Client) CreatePaymentContext(ctx context.Context, p Payment) (*CreatePaymentResp, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/payment", c.APIBase), p)
 	if err != nil {
 		return nil, err
 	}
@@ -39,7 +47,12 @@ func (c *Client) CreatePayment(p Payment) (*CreatePaymentResp, error) {
 
 // ExecutePayment completes an approved Paypal payment that has been approved by the payer
 func (c *Client) ExecutePayment(paymentID, payerID string, transactions []Transaction) (*ExecutePaymentResp, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/payment/%s/execute", c.APIBase, paymentID), struct {
+	return c.ExecutePaymentContext(context.Background(), paymentID, payerID, transactions)
+}
+
+// ExecutePaymentContext is like ExecutePayment but uses ctx for the request
+func (c *Client) ExecutePaymentContext(ctx context.Context, paymentID, payerID string, transactions []Transaction) (*ExecutePaymentResp, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/payment/%s/execute", c.APIBase, paymentID), struct {
 		PayerID      string        `json:"payer_id"`
 		Transactions []Transaction `json:"transactions"`
 	}{
@@ -62,7 +75,12 @@ func (c *Client) ExecutePayment(paymentID, payerID string, transactions []Transa
 
 // GetPayment fetches a payment in Paypal
 func (c *Client) GetPayment(id string) (*Payment, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/payment/%s", c.APIBase, id), nil)
+	return c.GetPaymentContext(context.Background(), id)
+}
+
+// GetPaymentContext is like GetPayment but uses ctx for the request
+func (c *Client) GetPaymentContext(ctx context.Context, id string) (*Payment, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/payment/%s", c.APIBase, id), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -79,7 +97,12 @@ func (c *Client) GetPayment(id string) (*Payment, error) {
 
 // ListPayments retrieve payments resources from Paypal
 func (c *Client) ListPayments(filter map[string]string) ([]Payment, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/payment/", c.APIBase), nil)
+	return c.ListPaymentsContext(context.Background(), filter)
+}
+
+// ListPaymentsContext is like ListPayments but uses ctx for the request
+func (c *Client) ListPaymentsContext(ctx context.Context, filter map[string]string) ([]Payment, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/payment/", c.APIBase), nil)
 	if err != nil {
 		return nil, err
 	}
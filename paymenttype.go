@@ -25,7 +25,8 @@ var (
 	OrderStatePending           OrderState = "PENDING"
 	OrderStateCompleted         OrderState = "COMPLETED"
 	OrderStateRefunded          OrderState = "REFUNDED"
-	OrderStatePartiallyRefunded            = "PARTIALLY_REFUNDED"
+	OrderStatePartiallyRefunded OrderState = "PARTIALLY_REFUNDED"
+	OrderStateVoided            OrderState = "VOIDED"
 
 	PendingReasonPayerShippingUnconfirmed PendingReason = "PAYER-SHIPPING-UNCONFIRMED"
 	PendingReasonMultiCurrency            PendingReason = "MULTI-CURRENCY"
@@ -240,13 +241,15 @@ type (
 		PurchaseUnitReferenceID   string        `json:"purchase_unit_reference_id,omitempty"`
 		CreateTime                *time.Time    `json:"create_time,omitempty"`
 		UpdateTime                *time.Time    `json:"update_time,omitempty"`
-		Amount                    []Amount      `json:"amount,omitempty"`
+		Amount                    *Amount       `json:"amount,omitempty"`
 		State                     OrderState    `json:"state,omitempty"`
+		ParentPayment             string        `json:"parent_payment,omitempty"`
 		PendingReason             PendingReason `json:"pending_reason,omitempty"`
 		ReasonCode                ReasonCode    `json:"reason_code,omitempty"`
 		ClearingTime              string        `json:"clearing_time,omitempty"`
 		ProtectionEligibility     string        `json:"protection_eligibility,omitempty"`
-		ProtectionEligibilityType string        `json:"protection_eligiblity_type,omitempty"`
+		ProtectionEligibilityType string        `json:"protection_eligibility_type,omitempty"`
+		Links                     []Links       `json:"links,omitempty"`
 	}
 
 	// Payer maps to payer object
@@ -305,12 +308,13 @@ type (
 		UpdateTime    *time.Time  `json:"update_time,omitempty"`
 	}
 
-	// Resource can be either sale, authorization, capture or refund object
+	// Resource can be either sale, authorization, capture, refund or order object
 	Resource struct {
 		Sale          *Sale          `json:"sale,omitempty"`
 		Authorization *Authorization `json:"authorization,omitempty"`
 		Capture       *Capture       `json:"capture,omitempty"`
 		Refund        *Refund        `json:"refund,omitempty"`
+		Order         *Order         `json:"order,omitempty"`
 	}
 
 	// Sale maps to sale object
@@ -0,0 +1,219 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/payments.payouts-batch/v1/
+
+var (
+	PayoutRecipientTypeEmail    PayoutRecipientType = "EMAIL"
+	PayoutRecipientTypePhone    PayoutRecipientType = "PHONE"
+	PayoutRecipientTypePaypalID PayoutRecipientType = "PAYPAL_ID"
+
+	PayoutBatchStatusDenied     PayoutBatchStatus = "DENIED"
+	PayoutBatchStatusPending    PayoutBatchStatus = "PENDING"
+	PayoutBatchStatusProcessing PayoutBatchStatus = "PROCESSING"
+	PayoutBatchStatusSuccess    PayoutBatchStatus = "SUCCESS"
+	PayoutBatchStatusCanceled   PayoutBatchStatus = "CANCELED"
+
+	PayoutItemStatusSuccess   PayoutItemStatus = "SUCCESS"
+	PayoutItemStatusFailed    PayoutItemStatus = "FAILED"
+	PayoutItemStatusPending   PayoutItemStatus = "PENDING"
+	PayoutItemStatusUnclaimed PayoutItemStatus = "UNCLAIMED"
+	PayoutItemStatusReturned  PayoutItemStatus = "RETURNED"
+	PayoutItemStatusOnHold    PayoutItemStatus = "ONHOLD"
+	PayoutItemStatusBlocked   PayoutItemStatus = "BLOCKED"
+	PayoutItemStatusRefunded  PayoutItemStatus = "REFUNDED"
+	PayoutItemStatusReversed  PayoutItemStatus = "REVERSED"
+)
+
+type (
+	PayoutRecipientType string
+	PayoutBatchStatus   string
+	PayoutItemStatus    string
+
+	// Payout maps to payout object, a batch of payments to send
+	Payout struct {
+		SenderBatchHeader *SenderBatchHeader `json:"sender_batch_header"`
+		Items             []PayoutItem       `json:"items"`
+	}
+
+	// SenderBatchHeader maps to sender_batch_header object. SenderBatchID
+	// identifies the batch for the merchant, Paypal rejecting a batch with
+	// an ID used in the last 30 days. RecipientType applies to the items
+	// which don't set theirs
+	SenderBatchHeader struct {
+		SenderBatchID string              `json:"sender_batch_id,omitempty"`
+		EmailSubject  string              `json:"email_subject,omitempty"`
+		EmailMessage  string              `json:"email_message,omitempty"`
+		RecipientType PayoutRecipientType `json:"recipient_type,omitempty"`
+	}
+
+	// PayoutItem maps to payout_item object. Receiver is an email, a phone
+	// number or an encrypted Paypal account number, depending on
+	// RecipientType
+	PayoutItem struct {
+		RecipientType PayoutRecipientType `json:"recipient_type,omitempty"`
+		Amount        *Currency           `json:"amount"`
+		Note          string              `json:"note,omitempty"`
+		Receiver      string              `json:"receiver"`
+		SenderItemID  string              `json:"sender_item_id,omitempty"`
+	}
+
+	// PayoutBatch maps to payout_batch object. Items are only returned
+	// once the batch is processed
+	PayoutBatch struct {
+		BatchHeader *PayoutBatchHeader `json:"batch_header"`
+		Items       []PayoutItemDetail `json:"items,omitempty"`
+		TotalItems  int                `json:"total_items,omitempty"`
+		TotalPages  int                `json:"total_pages,omitempty"`
+		Links       []Links            `json:"links,omitempty"`
+	}
+
+	// PayoutBatchHeader maps to payout_batch_header object
+	PayoutBatchHeader struct {
+		PayoutBatchID     string             `json:"payout_batch_id"`
+		BatchStatus       PayoutBatchStatus  `json:"batch_status"`
+		TimeCreated       *time.Time         `json:"time_created,omitempty"`
+		TimeCompleted     *time.Time         `json:"time_completed,omitempty"`
+		SenderBatchHeader *SenderBatchHeader `json:"sender_batch_header,omitempty"`
+		Amount            *Currency          `json:"amount,omitempty"`
+		Fees              *Currency          `json:"fees,omitempty"`
+	}
+
+	// PayoutItemDetail maps to payout_item_details object
+	PayoutItemDetail struct {
+		PayoutItemID      string           `json:"payout_item_id"`
+		TransactionID     string           `json:"transaction_id,omitempty"`
+		TransactionStatus PayoutItemStatus `json:"transaction_status"`
+		PayoutItemFee     *Currency        `json:"payout_item_fee,omitempty"`
+		PayoutBatchID     string           `json:"payout_batch_id"`
+		SenderBatchID     string           `json:"sender_batch_id,omitempty"`
+		PayoutItem        *PayoutItem      `json:"payout_item"`
+		TimeProcessed     *time.Time       `json:"time_processed,omitempty"`
+		Errors            *PayoutItemError `json:"errors,omitempty"`
+		Links             []Links          `json:"links,omitempty"`
+	}
+
+	// PayoutItemError maps to the error object of a payout item that
+	// failed
+	PayoutItemError struct {
+		Name            string        `json:"name"`
+		Message         string        `json:"message"`
+		InformationLink string        `json:"information_link,omitempty"`
+		Details         []ErrorDetail `json:"details,omitempty"`
+	}
+)
+
+// CreatePayout sends a batch of payouts. With syncMode, the payouts are
+// processed before the response, which holds the items; otherwise the
+// returned batch is PENDING and its items are returned by GetPayoutBatch
+// once processed
+func (c *Client) CreatePayout(p Payout, syncMode bool) (*PayoutBatch, error) {
+	return c.CreatePayoutContext(context.Background(), p, syncMode)
+}
+
+// CreatePayoutContext is like CreatePayout but uses ctx for the request
+func (c *Client) CreatePayoutContext(ctx context.Context, p Payout, syncMode bool) (*PayoutBatch, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/payouts", c.APIBase), p)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	q.Set("sync_mode", strconv.FormatBool(syncMode))
+	req.URL.RawQuery = q.Encode()
+
+	v := &PayoutBatch{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetPayoutBatch returns a payout batch by ID, with a page of its items.
+// Items are paged with the page (starting at 1) and page_size filters, the
+// total_required filter set to "true" returning the number of items and
+// pages
+func (c *Client) GetPayoutBatch(payoutBatchID string, filter map[string]string) (*PayoutBatch, error) {
+	return c.GetPayoutBatchContext(context.Background(), payoutBatchID, filter)
+}
+
+// GetPayoutBatchContext is like GetPayoutBatch but uses ctx for the request
+func (c *Client) GetPayoutBatchContext(ctx context.Context, payoutBatchID string, filter map[string]string) (*PayoutBatch, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/payouts/%s", c.APIBase, payoutBatchID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter != nil {
+		q := req.URL.Query()
+
+		for k, v := range filter {
+			q.Set(k, v)
+		}
+
+		req.URL.RawQuery = q.Encode()
+	}
+
+	v := &PayoutBatch{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetPayoutItem returns a payout item by ID
+func (c *Client) GetPayoutItem(payoutItemID string) (*PayoutItemDetail, error) {
+	return c.GetPayoutItemContext(context.Background(), payoutItemID)
+}
+
+// GetPayoutItemContext is like GetPayoutItem but uses ctx for the request
+func (c *Client) GetPayoutItemContext(ctx context.Context, payoutItemID string) (*PayoutItemDetail, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/payouts-item/%s", c.APIBase, payoutItemID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &PayoutItemDetail{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// CancelPayoutItem cancels an UNCLAIMED payout item, returning its amount
+// to the sender
+func (c *Client) CancelPayoutItem(payoutItemID string) (*PayoutItemDetail, error) {
+	return c.CancelPayoutItemContext(context.Background(), payoutItemID)
+}
+
+// CancelPayoutItemContext is like CancelPayoutItem but uses ctx for the request
+func (c *Client) CancelPayoutItemContext(ctx context.Context, payoutItemID string) (*PayoutItemDetail, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/payouts-item/%s/cancel", c.APIBase, payoutItemID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &PayoutItemDetail{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
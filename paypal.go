@@ -2,12 +2,13 @@ package paypal
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"log"
 	"net/http"
+	"sync"
 	"time"
 )
 
@@ -17,6 +18,9 @@ const (
 
 	// APIBaseLive points to the live version of the API
 	APIBaseLive = "https://api.paypal.com/v1"
+
+	// RequestIDHeader is the header Paypal uses to detect duplicate requests
+	RequestIDHeader = "PayPal-Request-Id"
 )
 
 type (
@@ -27,7 +31,28 @@ type (
 		ClientID string
 		Secret   string
 		APIBase  string
-		Token    *TokenResp
+
+		// Token is the current access token. It is managed by SendWithAuth
+		// and must not be modified while requests are in flight
+		Token *TokenResp
+
+		// RetryPolicy is applied to every request made by the client, unless
+		// overridden for a call with WithRetryPolicy. Nil disables retries
+		RetryPolicy *RetryPolicy
+
+		// TokenStore, if set, is consulted before requesting a new access
+		// token, and receives every token the client obtains
+		TokenStore TokenStore
+
+		timeout        time.Duration
+		userAgent      string
+		acceptLanguage string
+		logger         Logger
+		debug          bool
+
+		tokenMu       sync.Mutex
+		tokenCall     *tokenCall
+		rejectedToken string
 	}
 
 	// ErrorResponse is used when a response contains errors
@@ -41,6 +66,14 @@ type (
 		Message         string        `json:"message"`
 		InformationLink string        `json:"information_link"`
 		Details         []ErrorDetail `json:"details"`
+
+		// OAuth2 errors, such as a rejected access token, are reported
+		// with these fields instead
+		ErrorCode        string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+
+		// error met while reading or decoding the body, if any
+		bodyErr error
 	}
 
 	// ErrorDetails map to error_details object
@@ -61,24 +94,60 @@ type (
 )
 
 func (r *ErrorResponse) Error() string {
+	msg := r.Message
+	if msg == "" {
+		msg = r.ErrorDescription
+	}
+	if msg == "" {
+		msg = r.Name
+	}
+
+	if r.Response == nil {
+		return fmt.Sprintf("%v\nDetails: %v", msg, r.Details)
+	}
+	if r.Response.Request == nil {
+		return fmt.Sprintf("%d %v\nDetails: %v", r.Response.StatusCode, msg, r.Details)
+	}
+
 	return fmt.Sprintf("%v %v: %d %v\nDetails: %v",
-		r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, r.Message, r.Details)
+		r.Response.Request.Method, r.Response.Request.URL, r.Response.StatusCode, msg, r.Details)
 }
 
-// NewClient returns a new Client struct
-func NewClient(clientID, secret, APIBase string) *Client {
-	return &Client{
-		&http.Client{},
-		clientID,
-		secret,
-		APIBase,
-		nil,
+// NewClient returns a new Client struct. Options are applied in order
+func NewClient(clientID, secret, APIBase string, opts ...ClientOption) *Client {
+	c := &Client{
+		ClientID:       clientID,
+		Secret:         secret,
+		APIBase:        APIBase,
+		acceptLanguage: "en_US",
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	// The timeout is applied once all options ran, so that it does not
+	// depend on the position of WithHTTPClient
+	switch {
+	case c.client == nil:
+		c.client = &http.Client{Timeout: c.timeout}
+	case c.timeout > 0:
+		hc := *c.client
+		hc.Timeout = c.timeout
+		c.client = &hc
+	}
+
+	return c
 }
 
 // NewRequest constructs a request. If payload is not empty, it will be
 // marshalled into JSON
 func NewRequest(method, url string, payload interface{}) (*http.Request, error) {
+	return NewRequestContext(context.Background(), method, url, payload)
+}
+
+// NewRequestContext is like NewRequest but attaches ctx to the request, so
+// that cancelling ctx aborts the call to the API
+func NewRequestContext(ctx context.Context, method, url string, payload interface{}) (*http.Request, error) {
 	var buf io.Reader
 	if payload != nil {
 		var b []byte
@@ -88,13 +157,19 @@ func NewRequest(method, url string, payload interface{}) (*http.Request, error)
 		}
 		buf = bytes.NewBuffer(b)
 	}
-	return http.NewRequest(method, url, buf)
+	return http.NewRequestWithContext(ctx, method, url, buf)
 }
 
 // GetAcessToken request a new access token from Paypal
 func (c *Client) GetAccessToken() (*TokenResp, error) {
+	return c.GetAccessTokenContext(context.Background())
+}
+
+// GetAccessTokenContext is like GetAccessToken but uses ctx for the request.
+// An id set on ctx with WithRequestID is not sent with the token request
+func (c *Client) GetAccessTokenContext(ctx context.Context) (*TokenResp, error) {
 	buf := bytes.NewBuffer([]byte("grant_type=client_credentials"))
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s%s", c.APIBase, "/oauth2/token"), buf)
+	req, err := http.NewRequestWithContext(withoutRequestID(ctx), "POST", fmt.Sprintf("%s%s", c.APIBase, "/oauth2/token"), buf)
 	if err != nil {
 		return nil, err
 	}
@@ -112,31 +187,83 @@ func (c *Client) GetAccessToken() (*TokenResp, error) {
 
 // Send makes a request to the API, the response body will be
 // unmarshaled into v, or if v is an io.Writer, the response will
-// be written to it without decoding
+// be written to it without decoding, an error met while reading the
+// body or writing it to v being returned. If the request's context is
+// canceled or its deadline passes, the context's error is returned
+// instead of an ErrorResponse.
+//
+// Every POST carries a PayPal-Request-Id header so that it can be safely
+// retried. The id is taken from the request header if already set, then
+// from the context (see WithRequestID), and is generated otherwise.
+//
+// When a RetryPolicy applies, failed requests that are safe to repeat are
+// retried with the same PayPal-Request-Id, and the final error is returned
+// as a *RetryError reporting the number of attempts
 func (c *Client) Send(req *http.Request, v interface{}) error {
 	// Set default headers
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Accept-Language", "en_US")
+	req.Header.Set("Accept-Language", c.acceptLanguage)
+	if c.userAgent != "" {
+		req.Header.Set("User-Agent", c.userAgent)
+	}
 
 	// Default values for headers
 	if req.Header.Get("Content-type") == "" {
 		req.Header.Set("Content-type", "application/json")
 	}
 
-	log.Println(req.Method, ": ", req.URL)
+	if req.Method == "POST" && req.Header.Get(RequestIDHeader) == "" {
+		id, ok := RequestIDFromContext(req.Context())
+		if !ok {
+			var err error
+			if id, err = newRequestID(); err != nil {
+				return err
+			}
+		}
+		req.Header.Set(RequestIDHeader, id)
+	}
 
+	if p := c.retryPolicy(req.Context()); p != nil && isReplayable(req) {
+		return c.sendWithRetry(req, v, p)
+	}
+
+	return c.send(req, v, 1)
+}
+
+// send makes a single attempt at req
+func (c *Client) send(req *http.Request, v interface{}, attempt int) error {
+	c.dumpRequest(req, attempt)
+
+	start := time.Now()
 	resp, err := c.client.Do(req)
+	c.logRequest(req, resp, attempt, start, err)
+	recordResponse(req, resp, attempt, start)
 	if err != nil {
+		if ctxErr := req.Context().Err(); ctxErr != nil {
+			return ctxErr
+		}
 		return err
 	}
 	defer resp.Body.Close()
 
+	if _, ok := v.(io.Writer); !ok {
+		c.dumpResponse(req, resp, attempt)
+	}
+
 	if c := resp.StatusCode; c < 200 || c > 299 {
 		errResp := &ErrorResponse{Response: resp}
 		data, err := ioutil.ReadAll(resp.Body)
-
-		if err == nil && len(data) > 0 {
-			json.Unmarshal(data, errResp)
+		if err != nil {
+			errResp.bodyErr = err
+		} else if len(data) > 0 {
+			if err := json.Unmarshal(data, errResp); err != nil {
+				// The body may echo what was sent, card numbers included
+				errResp.bodyErr = err
+				errResp.Message = string(Redact(data))
+			}
+		}
+		if errResp.DebugID == "" {
+			errResp.DebugID = resp.Header.Get(DebugIDHeader)
 		}
 
 		return errResp
@@ -144,11 +271,13 @@ func (c *Client) Send(req *http.Request, v interface{}) error {
 
 	if v != nil {
 		if w, ok := v.(io.Writer); ok {
-			io.Copy(w, resp.Body)
+			if _, err := io.Copy(w, resp.Body); err != nil {
+				return &responseBodyError{err}
+			}
 		} else {
 			err = json.NewDecoder(resp.Body).Decode(v)
 			if err != nil {
-				return err
+				return &responseBodyError{err}
 			}
 		}
 	}
@@ -158,17 +287,40 @@ func (c *Client) Send(req *http.Request, v interface{}) error {
 
 // SendWithAuth makes a request to the API and apply OAuth2 header automatically.
 // If the access token soon to be expired, it will try to get a new one before
-// making the main request
+// making the main request. The token refresh keeps the values of the
+// request's context but not its cancellation, so that it can complete for
+// the other callers waiting on it.
+// It is safe to call from multiple goroutines: concurrent callers share a
+// single token refresh. If the API rejects the token as invalid, a new one
+// is requested and the request is retried once
 func (c *Client) SendWithAuth(req *http.Request, v interface{}) error {
-	if (c.Token == nil) || (c.Token.ExpiresAt.Before(time.Now())) {
-		resp, err := c.GetAccessToken()
-		if err != nil {
+	token, err := c.accessToken(req.Context())
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token.Token)
+
+	err = c.Send(req, v)
+	if !isInvalidToken(err) {
+		return err
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			return err
+		}
+		body, bodyErr := req.GetBody()
+		if bodyErr != nil {
 			return err
 		}
+		req.Body = body
+	}
 
-		c.Token = resp
+	c.invalidateToken(token)
+	token, err = c.accessToken(req.Context())
+	if err != nil {
+		return err
 	}
-	req.Header.Set("Authorization", "Bearer "+c.Token.Token)
+	req.Header.Set("Authorization", "Bearer "+token.Token)
 
 	return c.Send(req, v)
 }
@@ -1,41 +1,40 @@
 package paypal
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
 
 var testClient *Client
-var done = make(chan bool)
 
-func init() {
-	getTestClient()
-}
-
-func getTestClient() *Client {
+// getTestClient returns a client for the sandbox, skipping the test if no
+// sandbox credentials are set. Use the paypaltest package for tests that
+// must run offline
+func getTestClient(t *testing.T) *Client {
 	if testClient == nil {
 
 		clientID := os.Getenv("PAYPAL_TEST_CLIENTID")
-		if clientID == "" {
-			panic("Test Paypal clientID is missing")
-		}
-
 		secret := os.Getenv("PAYPAL_TEST_SECRET")
-		if secret == "" {
-			panic("Test Paypal secret is missing")
+		if clientID == "" || secret == "" {
+			t.Skip("PAYPAL_TEST_CLIENTID and PAYPAL_TEST_SECRET are required to run tests against the sandbox")
 		}
 
 		testClient = NewClient(clientID, secret, APIBaseSandBox)
-		close(done)
 	}
 
 	return testClient
 }
 
 func TestAuth(t *testing.T) {
-	client := getTestClient()
+	client := getTestClient(t)
 
 	Convey("Requesting an access token should returns token response", t, func() {
 		tokenResp, err := client.GetAccessToken()
@@ -47,12 +46,54 @@ func TestAuth(t *testing.T) {
 	})
 }
 
-func withContext(fn func(c *Client)) {
-	for {
-		_, ok := <-done
-		if !ok {
-			break
+func withContext(t *testing.T, fn func(c *Client)) {
+	fn(getTestClient(t))
+}
+
+// newOfflineClient returns a client for an httptest server passing every
+// request, token requests included, to h. The server is closed when the
+// test ends
+func newOfflineClient(t *testing.T, h http.HandlerFunc, opts ...ClientOption) *Client {
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	return NewClient("client-id", "secret", srv.URL+"/v1", opts...)
+}
+
+// writeToken writes a token response issuing token for an hour
+func writeToken(w http.ResponseWriter, token string) {
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"access_token":%q,"token_type":"Bearer","expires_in":3600}`, token)
+}
+
+func TestContextCancellation(t *testing.T) {
+	// stall issues tokens and holds the other requests until the client
+	// gives up on them
+	stall := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/oauth2/token" {
+			writeToken(w, "token")
+			return
 		}
+		<-r.Context().Done()
 	}
-	fn(getTestClient())
-}
\ No newline at end of file
+
+	Convey("Cancelling ctx should abort a call in flight", t, func() {
+		c := newOfflineClient(t, stall)
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := c.GetPaymentContext(ctx, "PAY-1")
+
+		So(errors.Is(err, context.Canceled), ShouldBeTrue)
+	})
+
+	Convey("A ctx deadline passing should abort a call in flight", t, func() {
+		c := newOfflineClient(t, stall)
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		_, err := c.GetPaymentContext(ctx, "PAY-1")
+
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,357 @@
+package paypaltest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// agreement is a billing agreement as kept by the server
+type agreement struct {
+	paypal.Agreement
+
+	// token identifies the agreement until it is executed
+	token string
+
+	// approvedBy is the ID of the payer who approved the agreement
+	approvedBy string
+
+	transactions []paypal.AgreementTransaction
+}
+
+// ApproveAgreement simulates the payer approving a billing agreement on the
+// Paypal website, after which it can be executed with its token
+func (s *Server) ApproveAgreement(token, payerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.agreementTokens[token]
+	if !ok {
+		return fmt.Errorf("paypaltest: no agreement with token %q", token)
+	}
+	if a.ID != "" {
+		return fmt.Errorf("paypaltest: agreement %q is already executed", token)
+	}
+	a.approvedBy = payerID
+
+	return nil
+}
+
+func (s *Server) handleCreateAgreement(w http.ResponseWriter, r *http.Request) {
+	a := &agreement{}
+	if !decode(w, r, &a.Agreement) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if details := s.validateAgreement(&a.Agreement); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	plan := *s.plans[a.Plan.ID]
+	plan.Links = nil
+	a.Plan = &plan
+	a.token = s.newID("EC-")
+	a.Links = []paypal.Links{
+		{Href: s.URL + "/checkoutnow?token=" + a.token, Rel: "approval_url", Method: "REDIRECT"},
+		s.link("execute", "POST", "/payments/billing-agreements/"+a.token+"/agreement-execute"),
+	}
+	s.agreementTokens[a.token] = a
+
+	writeJSON(w, http.StatusCreated, a.Agreement)
+}
+
+// validateAgreement returns the issues with an agreement to create. Must be
+// called with s.mu held
+func (s *Server) validateAgreement(a *paypal.Agreement) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+	if a.Name == "" {
+		details = append(details, paypal.ErrorDetail{Field: "name", Issue: "Required field missing"})
+	}
+	if a.Description == "" {
+		details = append(details, paypal.ErrorDetail{Field: "description", Issue: "Required field missing"})
+	}
+	if a.StartDate == nil {
+		details = append(details, paypal.ErrorDetail{Field: "start_date", Issue: "Required field missing"})
+	}
+	if a.Payer == nil || a.Payer.PaymentMethod != paypal.PaymentMethodPaypal {
+		details = append(details, paypal.ErrorDetail{Field: "payer.payment_method", Issue: "Value is invalid"})
+	}
+	if a.Plan == nil {
+		details = append(details, paypal.ErrorDetail{Field: "plan", Issue: "Required field missing"})
+	} else if plan, ok := s.plans[a.Plan.ID]; !ok || plan.State != paypal.PlanStateActive {
+		details = append(details, paypal.ErrorDetail{Field: "plan.id", Issue: "Plan is not found or not active"})
+	}
+
+	return details
+}
+
+func (s *Server) handleExecuteAgreement(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.agreementTokens[r.PathValue("token")]
+	switch {
+	case !ok:
+		writeNotFound(w)
+		return
+	case a.ID != "":
+		writeError(w, http.StatusBadRequest, "AGREEMENT_ALREADY_EXECUTED", "Agreement has already been executed")
+		return
+	case a.approvedBy == "":
+		writeError(w, http.StatusBadRequest, "PAYMENT_NOT_APPROVED_FOR_EXECUTION", "Payer has not approved the agreement")
+		return
+	}
+
+	a.ID = s.newID("I-")
+	a.State = paypal.AgreementStateActive
+	if a.Payer.PayerInfo == nil {
+		a.Payer.PayerInfo = &paypal.PayerInfo{}
+	}
+	a.Payer.PayerInfo.PayerID = a.approvedBy
+	a.Payer.Status = paypal.PayerStatusVerified
+	a.CreateTime = now()
+	a.UpdateTime = now()
+
+	currency := "USD"
+	cycles := ""
+	for _, def := range a.Plan.PaymentDefinitions {
+		if def.Amount != nil {
+			currency = def.Amount.Currency
+		}
+		if def.Type == paypal.PaymentDefinitionTypeRegular {
+			cycles = def.Cycles
+		}
+	}
+	a.AgreementDetails = &paypal.AgreementDetails{
+		OutstandingBalance: &paypal.Currency{Currency: currency, Value: "0.00"},
+		CyclesRemaining:    cycles,
+		CyclesCompleted:    "0",
+		NextBillingDate:    a.StartDate,
+		FailedPaymentCount: "0",
+	}
+
+	path := "/payments/billing-agreements/" + a.ID
+	a.Links = []paypal.Links{
+		s.link("self", "GET", path),
+		s.link("suspend", "POST", path+"/suspend"),
+		s.link("re_activate", "POST", path+"/re-activate"),
+		s.link("cancel", "POST", path+"/cancel"),
+		s.link("bill_balance", "POST", path+"/bill-balance"),
+		s.link("set_balance", "POST", path+"/set-balance"),
+	}
+	a.addTransaction("Created", nil)
+	s.agreements[a.ID] = a
+
+	writeJSON(w, http.StatusOK, a.Agreement)
+}
+
+// addTransaction appends a transaction to the history of the agreement
+func (a *agreement) addTransaction(status string, amount *paypal.Currency) {
+	a.transactions = append(a.transactions, paypal.AgreementTransaction{
+		TransactionID:   a.ID,
+		Status:          status,
+		TransactionType: "Recurring Payment",
+		Amount:          amount,
+		NetAmount:       amount,
+		TimeStamp:       now(),
+		TimeZone:        "GMT",
+	})
+}
+
+// agreement returns the executed agreement the request is for, writing a
+// not found error and returning nil if it is unknown. Must be called with
+// s.mu held
+func (s *Server) agreement(w http.ResponseWriter, r *http.Request) *agreement {
+	a, ok := s.agreements[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return nil
+	}
+
+	return a
+}
+
+func (s *Server) handleGetAgreement(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if a := s.agreement(w, r); a != nil {
+		writeJSON(w, http.StatusOK, a.Agreement)
+	}
+}
+
+func (s *Server) handlePatchAgreement(w http.ResponseWriter, r *http.Request) {
+	var patches []paypal.Patch
+	if !decode(w, r, &patches) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.agreement(w, r)
+	if a == nil {
+		return
+	}
+
+	var patched paypal.Agreement
+	if err := applyPatch(&patched, &a.Agreement, patches); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "patch_request", Issue: err.Error()})
+		return
+	}
+	patched.ID = a.ID
+	patched.State = a.State
+	patched.Plan = a.Plan
+	patched.AgreementDetails = a.AgreementDetails
+	patched.CreateTime = a.CreateTime
+	patched.UpdateTime = now()
+	patched.Links = a.Links
+	a.Agreement = patched
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// agreementTransition handles a request moving an agreement from one of the
+// states in from to the state to
+func (s *Server) agreementTransition(status string, to paypal.AgreementState, from ...paypal.AgreementState) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var desc paypal.AgreementStateDescriptor
+		if !decode(w, r, &desc) {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		a := s.agreement(w, r)
+		if a == nil {
+			return
+		}
+		allowed := false
+		for _, state := range from {
+			allowed = allowed || a.State == state
+		}
+		if !allowed {
+			writeError(w, http.StatusBadRequest, "STATUS_INVALID", fmt.Sprintf("Invalid profile status for %s action; profile is %s", status, a.State))
+			return
+		}
+
+		a.State = to
+		a.UpdateTime = now()
+		a.addTransaction(status, nil)
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleSetAgreementBalance(w http.ResponseWriter, r *http.Request) {
+	var balance paypal.Currency
+	if !decode(w, r, &balance) {
+		return
+	}
+	if _, err := parseCents(balance.Value); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "value", Issue: "Value is invalid"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.agreement(w, r)
+	if a == nil {
+		return
+	}
+	if a.State != paypal.AgreementStateActive && a.State != paypal.AgreementStateSuspended {
+		writeError(w, http.StatusBadRequest, "STATUS_INVALID", "Invalid profile status for set-balance action")
+		return
+	}
+	a.AgreementDetails.OutstandingBalance = &balance
+	a.UpdateTime = now()
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleBillAgreementBalance(w http.ResponseWriter, r *http.Request) {
+	var desc paypal.AgreementStateDescriptor
+	if !decode(w, r, &desc) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.agreement(w, r)
+	if a == nil {
+		return
+	}
+	if a.State != paypal.AgreementStateActive {
+		writeError(w, http.StatusBadRequest, "STATUS_INVALID", "Invalid profile status for bill-balance action")
+		return
+	}
+
+	balance := a.AgreementDetails.OutstandingBalance
+	outstanding, _ := parseCents(balance.Value)
+	billed := outstanding
+	if desc.Amount != nil {
+		var err error
+		if billed, err = parseCents(desc.Amount.Value); err != nil || desc.Amount.Currency != balance.Currency {
+			writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+				paypal.ErrorDetail{Field: "amount", Issue: "Value is invalid"})
+			return
+		}
+	}
+	if billed == 0 || billed > outstanding {
+		writeError(w, http.StatusBadRequest, "AMOUNT_EXCEEDED", "Amount should not exceed the outstanding balance")
+		return
+	}
+
+	amount := &paypal.Currency{Currency: balance.Currency, Value: formatCents(billed)}
+	a.AgreementDetails.OutstandingBalance = &paypal.Currency{Currency: balance.Currency, Value: formatCents(outstanding - billed)}
+	a.AgreementDetails.LastPaymentAmount = amount
+	a.AgreementDetails.LastPaymentDate = now()
+	a.UpdateTime = now()
+	a.addTransaction("Completed", amount)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListAgreementTransactions(w http.ResponseWriter, r *http.Request) {
+	start, err := time.Parse("2006-01-02", r.URL.Query().Get("start_date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "start_date", Issue: "Value is invalid"})
+		return
+	}
+	end, err := time.Parse("2006-01-02", r.URL.Query().Get("end_date"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "end_date", Issue: "Value is invalid"})
+		return
+	}
+	end = end.AddDate(0, 0, 1)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a := s.agreement(w, r)
+	if a == nil {
+		return
+	}
+
+	resp := paypal.AgreementTransactions{AgreementTransactionList: []paypal.AgreementTransaction{}}
+	for _, t := range a.transactions {
+		if !t.TimeStamp.Before(start) && t.TimeStamp.Before(end) {
+			resp.AgreementTransactionList = append(resp.AgreementTransactionList, t)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
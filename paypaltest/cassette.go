@@ -0,0 +1,253 @@
+package paypaltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/leebenson/paypal"
+)
+
+type (
+	// Cassette is a recording of HTTP interactions with the Paypal API,
+	// which can be saved to a file and replayed by a Replayer
+	Cassette struct {
+		Interactions []Interaction `json:"interactions"`
+	}
+
+	// Interaction is a recorded request and its response
+	Interaction struct {
+		Request  RecordedRequest  `json:"request"`
+		Response RecordedResponse `json:"response"`
+	}
+
+	// RecordedRequest is the part of a request a Replayer matches on
+	RecordedRequest struct {
+		Method string `json:"method"`
+		Path   string `json:"path"`
+		Query  string `json:"query,omitempty"`
+		Body   string `json:"body,omitempty"`
+	}
+
+	// RecordedResponse is a response as sent by the API, with sensitive
+	// values redacted
+	RecordedResponse struct {
+		StatusCode int         `json:"status_code"`
+		Header     http.Header `json:"header,omitempty"`
+		Body       string      `json:"body,omitempty"`
+	}
+
+	// Recorder is an http.RoundTripper that sends requests through
+	// Transport and records each exchange into a Cassette. Authorization
+	// headers are not recorded, and bodies are passed through paypal.Redact
+	// so that card numbers, CVVs, tokens and secrets never reach the file
+	Recorder struct {
+		// Transport makes the actual requests. Defaults to
+		// http.DefaultTransport
+		Transport http.RoundTripper
+
+		mu       sync.Mutex
+		cassette Cassette
+	}
+
+	// Replayer is an http.RoundTripper answering requests from a Cassette,
+	// without network access. Requests are matched on their method, path
+	// and body, compared after redaction and JSON normalization
+	Replayer struct {
+		mu           sync.Mutex
+		interactions []Interaction
+		used         []bool
+	}
+)
+
+// formSecrets are the form fields redacted from recorded bodies
+var formSecrets = []string{"client_secret", "code", "refresh_token", "access_token", "password"}
+
+// NewRecorder returns a Recorder sending requests through transport
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	return &Recorder{Transport: transport}
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	// The body is buffered to be recorded, so a copy of req carrying the
+	// buffer is sent: a RoundTripper must not modify the request
+	var reqBody []byte
+	out := req.Clone(req.Context())
+	if req.Body != nil {
+		var err error
+		reqBody, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		out.Body = ioutil.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	header := resp.Header.Clone()
+	header.Del("Set-Cookie")
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method: req.Method,
+			Path:   req.URL.Path,
+			Query:  req.URL.RawQuery,
+			Body:   redactBody(reqBody),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       redactBody(respBody),
+		},
+	})
+	r.mu.Unlock()
+
+	return resp, nil
+}
+
+// Cassette returns a copy of what has been recorded so far
+func (r *Recorder) Cassette() Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return Cassette{Interactions: append([]Interaction(nil), r.cassette.Interactions...)}
+}
+
+// Save writes what has been recorded so far to the file at path
+func (r *Recorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Cassette(), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// NewReplayer returns a Replayer answering requests from c
+func NewReplayer(c Cassette) *Replayer {
+	return &Replayer{
+		interactions: c.Interactions,
+		used:         make([]bool, len(c.Interactions)),
+	}
+}
+
+// LoadReplayer returns a Replayer answering requests from the cassette
+// saved at path
+func LoadReplayer(path string) (*Replayer, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+
+	return NewReplayer(c), nil
+}
+
+// RoundTrip implements http.RoundTripper. Each interaction is replayed
+// once, in the order it was recorded; when all those matching a request
+// were replayed, the last one is replayed again
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+	}
+	want := normalizeBody(redactBody(body))
+
+	r.mu.Lock()
+	match := -1
+	for i, in := range r.interactions {
+		if in.Request.Method != req.Method || in.Request.Path != req.URL.Path ||
+			normalizeBody(in.Request.Body) != want {
+			continue
+		}
+		match = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if match >= 0 {
+		r.used[match] = true
+	}
+	r.mu.Unlock()
+
+	if match < 0 {
+		return nil, fmt.Errorf("paypaltest: no recorded interaction for %s %s", req.Method, req.URL.Path)
+	}
+
+	recorded := r.interactions[match].Response
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        recorded.Header.Clone(),
+		Body:          ioutil.NopCloser(strings.NewReader(recorded.Body)),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}, nil
+}
+
+// redactBody scrubs sensitive values from a JSON or form encoded body
+func redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if json.Valid(body) {
+		return string(paypal.Redact(body))
+	}
+
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		return string(body)
+	}
+	for _, k := range formSecrets {
+		if form.Get(k) != "" {
+			form.Set(k, "[REDACTED]")
+		}
+	}
+
+	return form.Encode()
+}
+
+// normalizeBody returns a canonical form of a JSON body, so that bodies
+// differing only by whitespace or key order compare equal
+func normalizeBody(body string) string {
+	var v interface{}
+	if err := json.Unmarshal([]byte(body), &v); err != nil {
+		return strings.TrimSpace(body)
+	}
+
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,91 @@
+package paypaltest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leebenson/paypal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCassette(t *testing.T) {
+	Convey("Recording interactions with the API", t, func() {
+		srv := NewServer()
+		rec := NewRecorder(http.DefaultTransport)
+		client := srv.Client(paypal.WithHTTPClient(&http.Client{Transport: rec}))
+
+		created, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentSale, "7.47"))
+		So(err, ShouldBeNil)
+		saleID := created.Transactions[0].RelatedResources[0].Sale.ID
+
+		refund, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "2.34"})
+		So(err, ShouldBeNil)
+
+		payment, err := client.GetPayment(created.ID)
+		So(err, ShouldBeNil)
+		srv.Close()
+
+		path := filepath.Join(t.TempDir(), "cassette.json")
+		So(rec.Save(path), ShouldBeNil)
+
+		Convey("Should not save card numbers, CVVs, tokens or credentials", func() {
+			data, err := ioutil.ReadFile(path)
+
+			So(err, ShouldBeNil)
+			So(string(data), ShouldNotContainSubstring, "4417119669820331")
+			So(string(data), ShouldNotContainSubstring, `"874"`)
+			So(string(data), ShouldNotContainSubstring, "A21AA")
+			So(string(data), ShouldNotContainSubstring, "Authorization")
+			So(strings.Count(string(data), "************0331"), ShouldEqual, 1)
+		})
+
+		Convey("Should replay them without the server", func() {
+			replayer, err := LoadReplayer(path)
+			So(err, ShouldBeNil)
+			client := paypal.NewClient(ClientID, Secret, srv.URL, paypal.WithHTTPClient(&http.Client{Transport: replayer}))
+
+			replayed, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentSale, "7.47"))
+			So(err, ShouldBeNil)
+			So(replayed.ID, ShouldEqual, created.ID)
+
+			replayedRefund, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "2.34"})
+			So(err, ShouldBeNil)
+			So(replayedRefund.ID, ShouldEqual, refund.ID)
+
+			replayedPayment, err := client.GetPayment(created.ID)
+			So(err, ShouldBeNil)
+			So(replayedPayment.Transactions, ShouldResemble, payment.Transactions)
+
+			_, err = client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "1.00"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestRecorderRoundTrip(t *testing.T) {
+	Convey("Recorder should not modify the request it sends", t, func() {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			data, _ := ioutil.ReadAll(r.Body)
+			w.Write(data)
+		}))
+		defer srv.Close()
+
+		body := ioutil.NopCloser(strings.NewReader(`{"id":"PAY-1"}`))
+		req, _ := http.NewRequest("POST", srv.URL+"/v1/payments/payment", body)
+		header := req.Header.Clone()
+
+		resp, err := NewRecorder(nil).RoundTrip(req)
+		So(err, ShouldBeNil)
+		defer resp.Body.Close()
+		data, err := ioutil.ReadAll(resp.Body)
+
+		So(err, ShouldBeNil)
+		So(string(data), ShouldEqual, `{"id":"PAY-1"}`)
+		So(req.Body == body, ShouldBeTrue)
+		So(req.Header, ShouldResemble, header)
+	})
+}
@@ -0,0 +1,192 @@
+package paypaltest
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+type (
+	// Rule scripts a fault for the requests matching Method and Path. A
+	// rule can delay the request, replace its response, or expire the
+	// access tokens before the request is handled
+	Rule struct {
+		// Method matches the request method. Empty matches any method
+		Method string
+
+		// Path matches the request path. Segments written as {name} match
+		// any value, so "/payments/sale/{id}/refund" matches the refund of
+		// any sale. Empty matches any path
+		Path string
+
+		// Times is the number of matching requests the rule applies to,
+		// after which it is discarded. Zero applies it to every request
+		Times int
+
+		// Delay is waited before the request is handled
+		Delay time.Duration
+
+		// Status, if set, replaces the response. The body is an error
+		// object made of Name and Message, unless Body is set
+		Status  int
+		Name    string
+		Message string
+
+		// Body, if set, is sent as is. It can be used to send malformed or
+		// truncated JSON, with Status defaulting to 200
+		Body string
+
+		// Header is added to the response, e.g. a Retry-After header
+		Header http.Header
+
+		// ExpireTokens expires every access token before the request is
+		// handled, as if its token had expired mid-session
+		ExpireTokens bool
+	}
+
+	// Request is a request received by the server
+	Request struct {
+		Method    string
+		Path      string
+		RequestID string
+		Header    http.Header
+	}
+)
+
+// AddRule scripts a fault. Rules are tried in the order they were added,
+// and only the first matching rule applies to a request
+func (s *Server) AddRule(r Rule) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = append(s.rules, &r)
+}
+
+// ClearRules removes all the rules added with AddRule
+func (s *Server) ClearRules() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.rules = nil
+}
+
+// Requests returns the requests received so far, in order
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Request(nil), s.requests...)
+}
+
+// CountRequests returns how many requests received so far match method and
+// path, which are matched like those of a Rule
+func (s *Server) CountRequests(method, path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	for _, r := range s.requests {
+		if matchRequest(method, path, r.Method, r.Path) {
+			n++
+		}
+	}
+
+	return n
+}
+
+// record saves r to the list returned by Requests, and returns the rule
+// that applies to it, if any
+func (s *Server) record(r *http.Request) *Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests = append(s.requests, Request{
+		Method:    r.Method,
+		Path:      r.URL.Path,
+		RequestID: r.Header.Get(paypal.RequestIDHeader),
+		Header:    r.Header.Clone(),
+	})
+
+	for i, rule := range s.rules {
+		if !matchRequest(rule.Method, rule.Path, r.Method, r.URL.Path) {
+			continue
+		}
+		if rule.Times > 0 {
+			if rule.Times--; rule.Times == 0 {
+				s.rules = append(s.rules[:i:i], s.rules[i+1:]...)
+			}
+		}
+		matched := *rule
+		return &matched
+	}
+
+	return nil
+}
+
+// applyRule carries out rule for r. It returns true if it wrote the
+// response, in which case the request must not be handled any further
+func (s *Server) applyRule(rule *Rule, w http.ResponseWriter, r *http.Request) bool {
+	if rule.Delay > 0 {
+		t := time.NewTimer(rule.Delay)
+		select {
+		case <-t.C:
+		case <-r.Context().Done():
+			t.Stop()
+			return true
+		}
+	}
+
+	if rule.ExpireTokens {
+		s.ExpireTokens()
+	}
+
+	for k, v := range rule.Header {
+		w.Header()[k] = v
+	}
+
+	switch {
+	case rule.Body != "":
+		status := rule.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(rule.Body))
+		return true
+	case rule.Status != 0:
+		writeError(w, rule.Status, rule.Name, rule.Message)
+		return true
+	}
+
+	return false
+}
+
+// matchRequest reports whether a request matches the method and path of a
+// rule
+func matchRequest(ruleMethod, rulePath, method, path string) bool {
+	if ruleMethod != "" && ruleMethod != method {
+		return false
+	}
+	if rulePath == "" {
+		return true
+	}
+
+	want := strings.Split(strings.Trim(rulePath, "/"), "/")
+	got := strings.Split(strings.Trim(path, "/"), "/")
+	if len(want) != len(got) {
+		return false
+	}
+	for i := range want {
+		if strings.HasPrefix(want[i], "{") && strings.HasSuffix(want[i], "}") {
+			continue
+		}
+		if want[i] != got[i] {
+			return false
+		}
+	}
+
+	return true
+}
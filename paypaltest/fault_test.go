@@ -0,0 +1,121 @@
+package paypaltest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/leebenson/paypal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFaults(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+	client.RetryPolicy = &paypal.RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}
+
+	created, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentSale, "7.47"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	saleID := created.Transactions[0].RelatedResources[0].Sale.ID
+	refundPath := "/payments/sale/{id}/refund"
+
+	Convey("With scripted faults", t, func() {
+		Reset(srv.ClearRules)
+
+		Convey("A refund failing with 500s should be retried with the same PayPal-Request-Id", func() {
+			srv.AddRule(Rule{Method: "POST", Path: refundPath, Times: 2, Status: http.StatusInternalServerError, Name: "INTERNAL_SERVICE_ERROR"})
+			before := len(srv.Requests())
+
+			refund, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "1.00"})
+
+			So(err, ShouldBeNil)
+			So(refund.State, ShouldEqual, paypal.RefundStateCompleted)
+
+			requests := srv.Requests()[before:]
+			So(requests, ShouldHaveLength, 3)
+			So(requests[0].RequestID, ShouldNotBeBlank)
+			So(requests[1].RequestID, ShouldEqual, requests[0].RequestID)
+			So(requests[2].RequestID, ShouldEqual, requests[0].RequestID)
+		})
+
+		Convey("A 429 should be retried after the Retry-After delay", func() {
+			srv.AddRule(Rule{Path: "/payments/sale/{id}", Times: 1, Status: http.StatusTooManyRequests,
+				Name: paypal.ErrorNameRateLimitReached, Header: http.Header{"Retry-After": {"1"}}})
+
+			start := time.Now()
+			_, err := client.GetSale(saleID)
+
+			So(err, ShouldBeNil)
+			So(time.Since(start), ShouldBeGreaterThanOrEqualTo, time.Second)
+		})
+
+		Convey("Giving up should report the number of attempts", func() {
+			srv.AddRule(Rule{Path: refundPath, Status: http.StatusServiceUnavailable})
+
+			_, err := client.RefundSale(saleID, nil)
+
+			var retryErr *paypal.RetryError
+			So(errors.As(err, &retryErr), ShouldBeTrue)
+			So(retryErr.Attempts, ShouldEqual, 3)
+			So(paypal.IsRetryable(err), ShouldBeTrue)
+		})
+
+		Convey("A declined instrument should not be retried and be classified", func() {
+			srv.AddRule(Rule{Method: "POST", Path: "/payments/payment", Status: http.StatusBadRequest,
+				Name: paypal.ErrorNameInstrumentDeclined, Message: "The instrument presented was either declined"})
+			before := srv.CountRequests("POST", "/payments/payment")
+
+			_, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentSale, "1.00"))
+
+			var declined *paypal.InstrumentDeclinedError
+			So(errors.As(err, &declined), ShouldBeTrue)
+			So(paypal.IsInstrumentDeclined(err), ShouldBeTrue)
+			So(srv.CountRequests("POST", "/payments/payment")-before, ShouldEqual, 1)
+		})
+
+		Convey("A slow response should be abandoned when the context deadline passes", func() {
+			srv.AddRule(Rule{Path: "/payments/sale/{id}", Times: 1, Delay: time.Second})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			_, err := client.GetSaleContext(ctx, saleID)
+
+			So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		})
+
+		Convey("Truncated JSON answering a POST should not be retried", func() {
+			srv.AddRule(Rule{Method: "POST", Path: refundPath, Times: 1, Body: `{"id": "`})
+			before := srv.CountRequests("POST", refundPath)
+
+			_, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "0.10"})
+
+			So(errors.Is(err, io.ErrUnexpectedEOF), ShouldBeTrue)
+			So(srv.CountRequests("POST", refundPath)-before, ShouldEqual, 1)
+		})
+
+		Convey("Truncated JSON should surface as a decoding error without retries", func() {
+			srv.AddRule(Rule{Path: "/payments/sale/{id}", Times: 1, Body: `{"id": "`})
+
+			_, err := client.GetSaleContext(paypal.WithRetryPolicy(context.Background(), nil), saleID)
+
+			So(errors.Is(err, io.ErrUnexpectedEOF), ShouldBeTrue)
+		})
+
+		Convey("A token expiring mid-session should be refreshed transparently", func() {
+			srv.AddRule(Rule{Path: "/payments/sale/{id}", Times: 1, ExpireTokens: true})
+			before := srv.CountRequests("POST", "/oauth2/token")
+
+			_, err := client.GetSale(saleID)
+
+			So(err, ShouldBeNil)
+			So(srv.CountRequests("POST", "/oauth2/token")-before, ShouldEqual, 1)
+		})
+	})
+}
@@ -0,0 +1,266 @@
+package paypaltest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// grant is what a user granted the client when logging in, bound to an
+// authorization code or to the tokens it was exchanged for
+type grant struct {
+	scopes      []string
+	redirectURI string
+	expires     time.Time
+}
+
+// defaultUser is the user logging in through the authorization endpoint
+// unless Server.User is changed
+var defaultUser = paypal.UserInfo{
+	UserID:          "https://www.paypal.com/webapps/auth/identity/user/paypaltest",
+	Name:            "Jane Doe",
+	GivenName:       "Jane",
+	FamilyName:      "Doe",
+	Email:           "buyer@example.com",
+	EmailVerified:   true,
+	Locale:          "en_US",
+	Zoneinfo:        "America/Los_Angeles",
+	PhoneNumber:     "4085551234",
+	VerifiedAccount: "true",
+	AccountType:     "PERSONAL",
+	AgeRange:        "31-35",
+	PayerID:         "PAYERPAYPALTEST",
+	Address: &paypal.UserAddress{
+		StreetAddress: "1 Main St",
+		Locality:      "San Jose",
+		Region:        "CA",
+		PostalCode:    "95131",
+		Country:       "US",
+	},
+}
+
+// handleConnect stands for the Log In with PayPal page: the user logs in
+// right away and is redirected with an authorization code
+func (s *Server) handleConnect(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	redirectURI := q.Get("redirect_uri")
+	scopes := strings.Fields(q.Get("scope"))
+
+	switch {
+	case q.Get("client_id") != s.ClientID:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_client",
+			"error_description": "Client Authentication failed",
+		})
+		return
+	case q.Get("response_type") != "code":
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "unsupported_response_type",
+			"error_description": "Response type is not supported",
+		})
+		return
+	case redirectURI == "":
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_request",
+			"error_description": "redirect_uri is missing",
+		})
+		return
+	case !hasScope(scopes, paypal.ScopeOpenID):
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_scope",
+			"error_description": "openid scope is required",
+		})
+		return
+	}
+
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_request",
+			"error_description": "redirect_uri is invalid",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	code := s.newID("C21AA")
+	s.codes[code] = &grant{
+		scopes:      scopes,
+		redirectURI: redirectURI,
+		expires:     time.Now().Add(10 * time.Minute),
+	}
+	s.mu.Unlock()
+
+	v := u.Query()
+	v.Set("code", code)
+	v.Set("scope", strings.Join(scopes, " "))
+	if state := q.Get("state"); state != "" {
+		v.Set("state", state)
+	}
+	u.RawQuery = v.Encode()
+
+	http.Redirect(w, r, u.String(), http.StatusFound)
+}
+
+// handleEndSession logs the user out and redirects them
+func (s *Server) handleEndSession(w http.ResponseWriter, r *http.Request) {
+	redirectURI := r.URL.Query().Get("redirect_uri")
+	if redirectURI == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_request",
+			"error_description": "redirect_uri is missing",
+		})
+		return
+	}
+
+	http.Redirect(w, r, redirectURI, http.StatusFound)
+}
+
+func (s *Server) handleIdentityToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != s.ClientID || secret != s.Secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "invalid_client",
+			"error_description": "Client Authentication failed",
+		})
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_request",
+			"error_description": "Request body is invalid",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var (
+		g            *grant
+		refreshToken string
+	)
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		code := r.PostForm.Get("code")
+		g = s.codes[code]
+		delete(s.codes, code)
+		if g == nil || time.Now().After(g.expires) || g.redirectURI != r.PostForm.Get("redirect_uri") {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "Invalid authorization code",
+			})
+			return
+		}
+		refreshToken = s.newID("R23AA")
+		s.refreshTokens[refreshToken] = g
+	case "refresh_token":
+		if g = s.refreshTokens[r.PostForm.Get("refresh_token")]; g == nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{
+				"error":             "invalid_grant",
+				"error_description": "Invalid refresh token",
+			})
+			return
+		}
+	default:
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "unsupported_grant_type",
+			"error_description": "Grant Type is NULL",
+		})
+		return
+	}
+
+	token := s.newID("A23AA")
+	s.userTokens[token] = &grant{scopes: g.scopes, expires: time.Now().Add(s.TokenTTL)}
+
+	writeJSON(w, http.StatusOK, paypal.IdentityToken{
+		Scope:        strings.Join(g.scopes, " "),
+		Token:        token,
+		RefreshToken: refreshToken,
+		IDToken:      s.idToken(),
+		Type:         "Bearer",
+		ExpiresIn:    int(s.TokenTTL / time.Second),
+	})
+}
+
+// idToken returns an unsigned ID token for the user. Must be called with
+// s.mu held
+func (s *Server) idToken() string {
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss": s.URL,
+		"sub": s.User.UserID,
+		"aud": s.ClientID,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(s.TokenTTL).Unix(),
+	})
+
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString(claims) + "."
+}
+
+func (s *Server) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	g, ok := s.userTokens[token]
+	if !ok || time.Now().After(g.expires) {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "invalid_token",
+			"error_description": "Token signature verification failed",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, userInfo(s.User, g.scopes))
+}
+
+// userInfo returns the attributes of u released by scopes
+func userInfo(u paypal.UserInfo, scopes []string) paypal.UserInfo {
+	info := paypal.UserInfo{UserID: u.UserID, Sub: u.UserID}
+	if hasScope(scopes, paypal.ScopeProfile) {
+		info.Name = u.Name
+		info.GivenName = u.GivenName
+		info.FamilyName = u.FamilyName
+		info.MiddleName = u.MiddleName
+		info.Picture = u.Picture
+		info.Gender = u.Gender
+		info.Birthdate = u.Birthdate
+		info.Zoneinfo = u.Zoneinfo
+		info.Locale = u.Locale
+	}
+	if hasScope(scopes, paypal.ScopeEmail) {
+		info.Email = u.Email
+		info.EmailVerified = u.EmailVerified
+	}
+	if hasScope(scopes, paypal.ScopeAddress) {
+		info.Address = u.Address
+	}
+	if hasScope(scopes, paypal.ScopePhone) {
+		info.PhoneNumber = u.PhoneNumber
+	}
+	if hasScope(scopes, paypal.ScopeAccountInfo) {
+		info.VerifiedAccount = u.VerifiedAccount
+		info.AccountType = u.AccountType
+		info.AgeRange = u.AgeRange
+		info.PayerID = u.PayerID
+	}
+
+	return info
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,514 @@
+package paypaltest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// invoice is an invoice along with the amounts the server tracks in cents
+type invoice struct {
+	paypal.Invoice
+	total    int64
+	paid     int64
+	refunded int64
+}
+
+// invoiceDate returns the current date in the format of invoices
+func invoiceDate() string {
+	return time.Now().UTC().Format("2006-01-02 MST")
+}
+
+func (s *Server) handleCreateInvoice(w http.ResponseWriter, r *http.Request) {
+	inv := &invoice{}
+	if !decode(w, r, &inv.Invoice) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.applyInvoiceTemplate(&inv.Invoice) {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "template_id", Issue: "Value is invalid"})
+		return
+	}
+	if details := s.validateInvoice(inv); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	inv.ID = s.newID("INV2-")
+	if inv.Number == "" {
+		inv.Number = s.nextInvoiceNumber()
+	}
+	s.invoiceNumbers[inv.Number] = true
+	inv.Status = paypal.InvoiceStatusDraft
+	if inv.InvoiceDate == "" {
+		inv.InvoiceDate = invoiceDate()
+	}
+	inv.Payments, inv.Refunds, inv.PaidAmount, inv.RefundedAmount = nil, nil, nil, nil
+	inv.Metadata = &paypal.InvoiceMetadata{
+		CreatedDate:  invoiceDate(),
+		CreatedBy:    "web_service",
+		PayerViewURL: s.URL + "/invoice/payerView/details/" + inv.ID,
+	}
+	inv.Links = []paypal.Links{
+		s.link("self", "GET", "/invoicing/invoices/"+inv.ID),
+	}
+	s.invoices[inv.ID] = inv
+	s.invoiceOrder = append(s.invoiceOrder, inv.ID)
+
+	writeJSON(w, http.StatusCreated, inv.Invoice)
+}
+
+// validateInvoice returns the issues with an invoice to create or update,
+// computing its total on success. Must be called with s.mu held
+func (s *Server) validateInvoice(inv *invoice) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+	if inv.MerchantInfo == nil || inv.MerchantInfo.Email == "" {
+		details = append(details, paypal.ErrorDetail{Field: "merchant_info.email", Issue: "Required field missing"})
+	}
+	if inv.Number != "" && s.invoiceNumbers[inv.Number] {
+		if existing, ok := s.invoices[inv.ID]; !ok || existing.Number != inv.Number {
+			details = append(details, paypal.ErrorDetail{Field: "number", Issue: "Invoice number already in use"})
+		}
+	}
+	if len(details) > 0 {
+		return details
+	}
+
+	total, currency, err := invoiceTotal(&inv.Invoice)
+	if err != nil {
+		return []paypal.ErrorDetail{{Field: err.Error(), Issue: "Value is invalid"}}
+	}
+	inv.total = total
+	inv.TotalAmount = &paypal.Currency{Currency: currency, Value: formatCents(total)}
+
+	return nil
+}
+
+// invoiceTotal computes the total of an invoice from its items, discounts,
+// taxes and shipping cost. The error names the invalid field
+func invoiceTotal(inv *paypal.Invoice) (int64, string, error) {
+	var (
+		subtotal, taxes int64
+		currency        string
+	)
+	for i, item := range inv.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.Name == "" {
+			return 0, "", fmt.Errorf("%s.name", field)
+		}
+		if item.Quantity <= 0 {
+			return 0, "", fmt.Errorf("%s.quantity", field)
+		}
+		if item.UnitPrice == nil {
+			return 0, "", fmt.Errorf("%s.unit_price", field)
+		}
+		price, err := parseCents(item.UnitPrice.Value)
+		if err != nil || (currency != "" && item.UnitPrice.Currency != currency) {
+			return 0, "", fmt.Errorf("%s.unit_price", field)
+		}
+		currency = item.UnitPrice.Currency
+
+		line := int64(math.Round(item.Quantity * float64(price)))
+		discount, err := discountCents(item.Discount, line)
+		if err != nil {
+			return 0, "", fmt.Errorf("%s.discount", field)
+		}
+		line -= discount
+		if item.Tax != nil {
+			taxes += int64(math.Round(float64(line) * item.Tax.Percent / 100))
+		}
+		subtotal += line
+	}
+	if len(inv.Items) == 0 {
+		return 0, "", fmt.Errorf("items")
+	}
+
+	discount, err := discountCents(inv.Discount, subtotal)
+	if err != nil {
+		return 0, "", fmt.Errorf("discount")
+	}
+	total := subtotal - discount + taxes
+	if inv.ShippingCost != nil && inv.ShippingCost.Amount != nil {
+		shipping, err := parseCents(inv.ShippingCost.Amount.Value)
+		if err != nil {
+			return 0, "", fmt.Errorf("shipping_cost.amount")
+		}
+		total += shipping
+	}
+	if total < 0 {
+		return 0, "", fmt.Errorf("discount")
+	}
+
+	return total, currency, nil
+}
+
+// discountCents returns the amount of d applied to amount
+func discountCents(d *paypal.Discount, amount int64) (int64, error) {
+	switch {
+	case d == nil:
+		return 0, nil
+	case d.Amount != nil:
+		return parseCents(d.Amount.Value)
+	case d.Percent < 0 || d.Percent > 100:
+		return 0, fmt.Errorf("invalid percent %v", d.Percent)
+	}
+
+	return int64(math.Round(float64(amount) * d.Percent / 100)), nil
+}
+
+// nextInvoiceNumber returns the first invoice number not in use. Must be
+// called with s.mu held
+func (s *Server) nextInvoiceNumber() string {
+	for n := len(s.invoiceNumbers) + 1; ; n++ {
+		if number := fmt.Sprintf("%04d", n); !s.invoiceNumbers[number] {
+			return number
+		}
+	}
+}
+
+func (s *Server) invoice(w http.ResponseWriter, r *http.Request) *invoice {
+	inv, ok := s.invoices[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return nil
+	}
+
+	return inv
+}
+
+// invoiceStatusIn checks that the status of inv is one of statuses, writing
+// an error naming action otherwise
+func invoiceStatusIn(w http.ResponseWriter, inv *invoice, action string, statuses ...paypal.InvoiceStatus) bool {
+	for _, status := range statuses {
+		if inv.Status == status {
+			return true
+		}
+	}
+
+	writeError(w, http.StatusBadRequest, "STATUS_INVALID", fmt.Sprintf("Invalid status for %s action; invoice is %s", action, inv.Status))
+	return false
+}
+
+func (s *Server) handleGetInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if inv := s.invoice(w, r); inv != nil {
+		writeJSON(w, http.StatusOK, inv.Invoice)
+	}
+}
+
+func (s *Server) handleUpdateInvoice(w http.ResponseWriter, r *http.Request) {
+	var update paypal.Invoice
+	if !decode(w, r, &update) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "update", paypal.InvoiceStatusDraft, paypal.InvoiceStatusSent) {
+		return
+	}
+
+	updated := &invoice{Invoice: update}
+	updated.ID = inv.ID
+	if updated.Number == "" {
+		updated.Number = inv.Number
+	}
+	if details := s.validateInvoice(updated); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	delete(s.invoiceNumbers, inv.Number)
+	s.invoiceNumbers[updated.Number] = true
+	updated.URI = inv.URI
+	updated.Status = inv.Status
+	if updated.InvoiceDate == "" {
+		updated.InvoiceDate = inv.InvoiceDate
+	}
+	updated.Payments, updated.Refunds = inv.Payments, inv.Refunds
+	updated.PaidAmount, updated.RefundedAmount = inv.PaidAmount, inv.RefundedAmount
+	metadata := *inv.Metadata
+	metadata.LastUpdatedDate = invoiceDate()
+	metadata.LastUpdatedBy = "web_service"
+	updated.Metadata = &metadata
+	updated.Links = inv.Links
+	*inv = *updated
+
+	writeJSON(w, http.StatusOK, inv.Invoice)
+}
+
+func (s *Server) handleDeleteInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "delete", paypal.InvoiceStatusDraft) {
+		return
+	}
+
+	delete(s.invoices, inv.ID)
+	delete(s.invoiceNumbers, inv.Number)
+	for i, id := range s.invoiceOrder {
+		if id == inv.ID {
+			s.invoiceOrder = append(s.invoiceOrder[:i], s.invoiceOrder[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSendInvoice(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "send", paypal.InvoiceStatusDraft) {
+		return
+	}
+	if len(inv.BillingInfo) == 0 || inv.BillingInfo[0].Email == "" {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "billing_info[0].email", Issue: "Required field missing"})
+		return
+	}
+
+	inv.Status = paypal.InvoiceStatusSent
+	inv.Metadata.FirstSentDate = invoiceDate()
+	inv.Metadata.LastSentDate = inv.Metadata.FirstSentDate
+	inv.Metadata.LastSentBy = inv.MerchantInfo.Email
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleRemindInvoice(w http.ResponseWriter, r *http.Request) {
+	var n paypal.InvoiceNotification
+	if !decode(w, r, &n) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "remind", paypal.InvoiceStatusSent, paypal.InvoiceStatusPartiallyPaid) {
+		return
+	}
+	inv.Metadata.LastSentDate = invoiceDate()
+	inv.Metadata.LastSentBy = inv.MerchantInfo.Email
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleCancelInvoice(w http.ResponseWriter, r *http.Request) {
+	var n paypal.InvoiceNotification
+	if !decode(w, r, &n) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "cancel", paypal.InvoiceStatusSent, paypal.InvoiceStatusPartiallyPaid) {
+		return
+	}
+	inv.Status = paypal.InvoiceStatusCancelled
+	inv.Metadata.CancelledDate = invoiceDate()
+	inv.Metadata.CancelledBy = inv.MerchantInfo.Email
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRecordInvoicePayment(w http.ResponseWriter, r *http.Request) {
+	var p paypal.InvoicePaymentDetail
+	if !decode(w, r, &p) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "record-payment", paypal.InvoiceStatusSent, paypal.InvoiceStatusPartiallyPaid) {
+		return
+	}
+
+	var details []paypal.ErrorDetail
+	if p.Method == "" {
+		details = append(details, paypal.ErrorDetail{Field: "method", Issue: "Required field missing"})
+	}
+	amount := inv.total - inv.paid
+	if p.Amount != nil {
+		cents, err := parseCents(p.Amount.Value)
+		if err != nil || cents == 0 || cents > inv.total-inv.paid ||
+			(p.Amount.Currency != "" && p.Amount.Currency != inv.TotalAmount.Currency) {
+			details = append(details, paypal.ErrorDetail{Field: "amount", Issue: "Value is invalid"})
+		}
+		amount = cents
+	}
+	if len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	inv.paid += amount
+	if inv.paid == inv.total {
+		inv.Status = paypal.InvoiceStatusMarkedAsPaid
+	} else {
+		inv.Status = paypal.InvoiceStatusPartiallyPaid
+	}
+	if p.Date == "" {
+		p.Date = invoiceDate()
+	}
+	p.Type = "EXTERNAL"
+	p.Amount = &paypal.Currency{Currency: inv.TotalAmount.Currency, Value: formatCents(amount)}
+	inv.Payments = append(inv.Payments, p)
+	inv.PaidAmount = &paypal.InvoicePaidAmount{
+		Other: &paypal.Currency{Currency: inv.TotalAmount.Currency, Value: formatCents(inv.paid)},
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRecordInvoiceRefund(w http.ResponseWriter, r *http.Request) {
+	var refund paypal.InvoiceRefundDetail
+	if !decode(w, r, &refund) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "record-refund",
+		paypal.InvoiceStatusMarkedAsPaid, paypal.InvoiceStatusPartiallyPaid, paypal.InvoiceStatusPartiallyRefunded) {
+		return
+	}
+
+	amount := inv.paid - inv.refunded
+	if refund.Amount != nil {
+		cents, err := parseCents(refund.Amount.Value)
+		if err != nil || cents == 0 || cents > inv.paid-inv.refunded ||
+			(refund.Amount.Currency != "" && refund.Amount.Currency != inv.TotalAmount.Currency) {
+			writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+				paypal.ErrorDetail{Field: "amount", Issue: "Value is invalid"})
+			return
+		}
+		amount = cents
+	}
+
+	inv.refunded += amount
+	if inv.refunded == inv.paid {
+		inv.Status = paypal.InvoiceStatusMarkedAsRefunded
+	} else {
+		inv.Status = paypal.InvoiceStatusPartiallyRefunded
+	}
+	if refund.Date == "" {
+		refund.Date = invoiceDate()
+	}
+	refund.Type = "EXTERNAL"
+	refund.Amount = &paypal.Currency{Currency: inv.TotalAmount.Currency, Value: formatCents(amount)}
+	inv.Refunds = append(inv.Refunds, refund)
+	inv.RefundedAmount = &paypal.InvoicePaidAmount{
+		Other: &paypal.Currency{Currency: inv.TotalAmount.Currency, Value: formatCents(inv.refunded)},
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSearchInvoices(w http.ResponseWriter, r *http.Request) {
+	var search paypal.InvoiceSearch
+	if !decode(w, r, &search) {
+		return
+	}
+	if search.PageSize <= 0 {
+		search.PageSize = 20
+	}
+	lower, upper := int64(0), int64(math.MaxInt64)
+	if search.LowerTotalAmount != nil {
+		lower, _ = parseCents(search.LowerTotalAmount.Value)
+	}
+	if search.UpperTotalAmount != nil {
+		if cents, err := parseCents(search.UpperTotalAmount.Value); err == nil {
+			upper = cents
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	matching := []paypal.Invoice{}
+	for _, id := range s.invoiceOrder {
+		inv := s.invoices[id]
+		if inv.total < lower || inv.total > upper ||
+			(search.Number != "" && inv.Number != search.Number) ||
+			(len(search.Status) > 0 && !hasInvoiceStatus(search.Status, inv.Status)) ||
+			!matchesRecipient(inv, search) {
+			continue
+		}
+		matching = append(matching, inv.Invoice)
+	}
+
+	resp := paypal.SearchInvoicesResp{Invoices: []paypal.Invoice{}}
+	if start := search.Page * search.PageSize; start >= 0 && start < len(matching) {
+		end := start + search.PageSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		resp.Invoices = matching[start:end]
+	}
+	if search.TotalCountRequired {
+		resp.TotalCount = len(matching)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func hasInvoiceStatus(statuses []paypal.InvoiceStatus, status paypal.InvoiceStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesRecipient tells whether the billing info of inv matches the
+// recipient fields of search
+func matchesRecipient(inv *invoice, search paypal.InvoiceSearch) bool {
+	if search.Email == "" && search.RecipientFirstName == "" &&
+		search.RecipientLastName == "" && search.RecipientBusinessName == "" {
+		return true
+	}
+
+	for _, b := range inv.BillingInfo {
+		if (search.Email == "" || strings.EqualFold(b.Email, search.Email)) &&
+			(search.RecipientFirstName == "" || b.FirstName == search.RecipientFirstName) &&
+			(search.RecipientLastName == "" || b.LastName == search.RecipientLastName) &&
+			(search.RecipientBusinessName == "" || b.BusinessName == search.RecipientBusinessName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) handleNextInvoiceNumber(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, map[string]string{"number": s.nextInvoiceNumber()})
+}
@@ -0,0 +1,1336 @@
+package paypaltest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+type (
+	// Mock implements paypal.API for testing code that uses a paypal.Client,
+	// without a server. Each method calls the matching ...Func field, whose
+	// return values it returns, and records the call. Methods whose field is
+	// nil return an error wrapping ErrNotStubbed.
+	//
+	//	m := &paypaltest.Mock{
+	//		GetSaleFunc: func(ctx context.Context, saleID string) (*paypal.Sale, error) {
+	//			return &paypal.Sale{ID: saleID, State: paypal.SaleStateCompleted}, nil
+	//		},
+	//	}
+	//
+	// Both variants of a method, e.g. GetSale and GetSaleContext, share the
+	// same field and are recorded under the name of the former
+	Mock struct {
+		// PaymentsAPI
+		CreatePaymentFunc  func(ctx context.Context, p paypal.Payment) (*paypal.CreatePaymentResp, error)
+		ExecutePaymentFunc func(ctx context.Context, paymentID, payerID string, transactions []paypal.Transaction) (*paypal.ExecutePaymentResp, error)
+		GetPaymentFunc     func(ctx context.Context, id string) (*paypal.Payment, error)
+		ListPaymentsFunc   func(ctx context.Context, filter map[string]string) ([]paypal.Payment, error)
+
+		// SalesAPI
+		GetSaleFunc    func(ctx context.Context, saleID string) (*paypal.Sale, error)
+		RefundSaleFunc func(ctx context.Context, saleID string, a *paypal.Amount) (*paypal.Refund, error)
+
+		// AuthorizationsAPI
+		GetAuthorizationFunc         func(ctx context.Context, authID string) (*paypal.Authorization, error)
+		CaptureAuthorizationFunc     func(ctx context.Context, authID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error)
+		VoidAuthorizationFunc        func(ctx context.Context, authID string) (*paypal.Authorization, error)
+		ReauthorizeAuthorizationFunc func(ctx context.Context, authID string, a *paypal.Amount) (*paypal.Authorization, error)
+
+		// OrdersAPI
+		GetOrderFunc       func(ctx context.Context, orderID string) (*paypal.Order, error)
+		AuthorizeOrderFunc func(ctx context.Context, orderID string, a *paypal.Amount) (*paypal.Authorization, error)
+		CaptureOrderFunc   func(ctx context.Context, orderID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error)
+		VoidOrderFunc      func(ctx context.Context, orderID string) (*paypal.Order, error)
+		RefundOrderFunc    func(ctx context.Context, orderID string, a *paypal.Amount) (*paypal.Refund, error)
+
+		// CapturesAPI
+		GetCaptureFunc    func(ctx context.Context, captureID string) (*paypal.Capture, error)
+		RefundCaptureFunc func(ctx context.Context, captureID string, a *paypal.Amount) (*paypal.Refund, error)
+
+		// RefundsAPI
+		GetRefundFunc func(ctx context.Context, refundID string) (*paypal.Refund, error)
+
+		// VaultAPI
+		StoreInVaultFunc           func(ctx context.Context, cc paypal.VaultRequest) (*paypal.VaultResponse, error)
+		GetStoredCreditCardFunc    func(ctx context.Context, cardID string) (*paypal.VaultResponse, error)
+		ListStoredCreditCardsFunc  func(ctx context.Context, filter map[string]string) (*paypal.ListStoredCreditCardsResp, error)
+		UpdateStoredCreditCardFunc func(ctx context.Context, cardID string, patches []paypal.Patch) (*paypal.VaultResponse, error)
+		DeleteStoredCreditCardFunc func(ctx context.Context, cardID string) error
+
+		// PlansAPI
+		CreatePlanFunc     func(ctx context.Context, p paypal.Plan) (*paypal.Plan, error)
+		GetPlanFunc        func(ctx context.Context, planID string) (*paypal.Plan, error)
+		ListPlansFunc      func(ctx context.Context, filter map[string]string) (*paypal.ListPlansResp, error)
+		UpdatePlanFunc     func(ctx context.Context, planID string, patches []paypal.Patch) error
+		ActivatePlanFunc   func(ctx context.Context, planID string) error
+		DeactivatePlanFunc func(ctx context.Context, planID string) error
+
+		// AgreementsAPI
+		CreateAgreementFunc           func(ctx context.Context, a paypal.Agreement) (*paypal.CreateAgreementResp, error)
+		ExecuteAgreementFunc          func(ctx context.Context, token string) (*paypal.Agreement, error)
+		GetAgreementFunc              func(ctx context.Context, agreementID string) (*paypal.Agreement, error)
+		UpdateAgreementFunc           func(ctx context.Context, agreementID string, patches []paypal.Patch) error
+		SuspendAgreementFunc          func(ctx context.Context, agreementID, note string) error
+		ReactivateAgreementFunc       func(ctx context.Context, agreementID, note string) error
+		CancelAgreementFunc           func(ctx context.Context, agreementID, note string) error
+		SetAgreementBalanceFunc       func(ctx context.Context, agreementID string, balance paypal.Currency) error
+		BillAgreementBalanceFunc      func(ctx context.Context, agreementID, note string, amount *paypal.Currency) error
+		ListAgreementTransactionsFunc func(ctx context.Context, agreementID string, start, end time.Time) ([]paypal.AgreementTransaction, error)
+
+		// WebhooksAPI
+		CreateWebhookFunc           func(ctx context.Context, url string, eventTypes []paypal.EventType) (*paypal.Webhook, error)
+		ListWebhooksFunc            func(ctx context.Context) ([]paypal.Webhook, error)
+		GetWebhookFunc              func(ctx context.Context, webhookID string) (*paypal.Webhook, error)
+		UpdateWebhookFunc           func(ctx context.Context, webhookID string, patches []paypal.Patch) (*paypal.Webhook, error)
+		DeleteWebhookFunc           func(ctx context.Context, webhookID string) error
+		ListWebhookEventTypesFunc   func(ctx context.Context, webhookID string) ([]paypal.WebhookEventType, error)
+		ListAvailableEventTypesFunc func(ctx context.Context) ([]paypal.WebhookEventType, error)
+		ListWebhookEventsFunc       func(ctx context.Context, filter map[string]string) (*paypal.ListWebhookEventsResp, error)
+		GetWebhookEventFunc         func(ctx context.Context, eventID string) (*paypal.WebhookEvent, error)
+		ResendWebhookEventFunc      func(ctx context.Context, eventID string, webhookIDs []string) (*paypal.WebhookEvent, error)
+		SimulateWebhookEventFunc    func(ctx context.Context, s paypal.SimulateWebhookEventReq) (*paypal.WebhookEvent, error)
+		VerifyWebhookSignatureFunc  func(ctx context.Context, v paypal.VerifyWebhookSignatureReq) (*paypal.VerifyWebhookSignatureResp, error)
+
+		// IdentityAPI
+		ExchangeAuthorizationCodeFunc func(ctx context.Context, code, redirectURI string) (*paypal.IdentityToken, error)
+		RefreshIdentityTokenFunc      func(ctx context.Context, refreshToken string) (*paypal.IdentityToken, error)
+		GetUserInfoFunc               func(ctx context.Context, accessToken string) (*paypal.UserInfo, error)
+
+		// InvoicesAPI
+		CreateInvoiceFunc         func(ctx context.Context, i paypal.Invoice) (*paypal.Invoice, error)
+		GetInvoiceFunc            func(ctx context.Context, invoiceID string) (*paypal.Invoice, error)
+		UpdateInvoiceFunc         func(ctx context.Context, invoiceID string, i paypal.Invoice) (*paypal.Invoice, error)
+		DeleteInvoiceFunc         func(ctx context.Context, invoiceID string) error
+		SendInvoiceFunc           func(ctx context.Context, invoiceID string, notifyMerchant bool) error
+		RemindInvoiceFunc         func(ctx context.Context, invoiceID string, n paypal.InvoiceNotification) error
+		CancelInvoiceFunc         func(ctx context.Context, invoiceID string, n paypal.InvoiceNotification) error
+		RecordInvoicePaymentFunc  func(ctx context.Context, invoiceID string, p paypal.InvoicePaymentDetail) error
+		RecordInvoiceRefundFunc   func(ctx context.Context, invoiceID string, r paypal.InvoiceRefundDetail) error
+		SearchInvoicesFunc        func(ctx context.Context, s paypal.InvoiceSearch) (*paypal.SearchInvoicesResp, error)
+		GenerateInvoiceNumberFunc func(ctx context.Context) (string, error)
+		GetInvoiceQRCodeFunc      func(ctx context.Context, invoiceID string, width, height int) ([]byte, error)
+		WriteInvoiceQRCodeFunc    func(ctx context.Context, w io.Writer, invoiceID string, width, height int) error
+		CreateInvoiceTemplateFunc func(ctx context.Context, t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error)
+		ListInvoiceTemplatesFunc  func(ctx context.Context) (*paypal.ListInvoiceTemplatesResp, error)
+		GetInvoiceTemplateFunc    func(ctx context.Context, templateID string) (*paypal.InvoiceTemplate, error)
+		UpdateInvoiceTemplateFunc func(ctx context.Context, templateID string, t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error)
+		DeleteInvoiceTemplateFunc func(ctx context.Context, templateID string) error
+
+		// WebProfilesAPI
+		CreateWebProfileFunc func(ctx context.Context, p paypal.WebProfile) (*paypal.WebProfile, error)
+		ListWebProfilesFunc  func(ctx context.Context) ([]paypal.WebProfile, error)
+		GetWebProfileFunc    func(ctx context.Context, profileID string) (*paypal.WebProfile, error)
+		UpdateWebProfileFunc func(ctx context.Context, profileID string, p paypal.WebProfile) error
+		PatchWebProfileFunc  func(ctx context.Context, profileID string, patches []paypal.Patch) error
+		DeleteWebProfileFunc func(ctx context.Context, profileID string) error
+
+		// PayoutsAPI
+		CreatePayoutFunc     func(ctx context.Context, p paypal.Payout, syncMode bool) (*paypal.PayoutBatch, error)
+		GetPayoutBatchFunc   func(ctx context.Context, payoutBatchID string, filter map[string]string) (*paypal.PayoutBatch, error)
+		GetPayoutItemFunc    func(ctx context.Context, payoutItemID string) (*paypal.PayoutItemDetail, error)
+		CancelPayoutItemFunc func(ctx context.Context, payoutItemID string) (*paypal.PayoutItemDetail, error)
+
+		mu    sync.Mutex
+		calls []Call
+	}
+
+	// Call is a call made to a Mock
+	Call struct {
+		Method string
+		Args   []interface{}
+	}
+)
+
+// ErrNotStubbed is returned by the methods of a Mock whose function field
+// is not set
+var ErrNotStubbed = errors.New("paypaltest: method not stubbed")
+
+var _ paypal.API = (*Mock)(nil)
+
+// Calls returns the calls made to the mock so far, in order
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Call(nil), m.calls...)
+}
+
+// CallsTo returns the calls made so far to method, in order
+func (m *Mock) CallsTo(method string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var calls []Call
+	for _, c := range m.calls {
+		if c.Method == method {
+			calls = append(calls, c)
+		}
+	}
+
+	return calls
+}
+
+func (m *Mock) record(method string, args ...interface{}) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = append(m.calls, Call{Method: method, Args: args})
+}
+
+func notStubbed(method string) error {
+	return fmt.Errorf("%w: %s", ErrNotStubbed, method)
+}
+
+// CreatePayment implements paypal.PaymentsAPI
+func (m *Mock) CreatePayment(p paypal.Payment) (*paypal.CreatePaymentResp, error) {
+	return m.CreatePaymentContext(context.Background(), p)
+}
+
+// CreatePaymentContext implements paypal.PaymentsAPI
+func (m *Mock) CreatePaymentContext(ctx context.Context, p paypal.Payment) (*paypal.CreatePaymentResp, error) {
+	m.record("CreatePayment", p)
+	if m.CreatePaymentFunc == nil {
+		return nil, notStubbed("CreatePayment")
+	}
+	return m.CreatePaymentFunc(ctx, p)
+}
+
+// ExecutePayment implements paypal.PaymentsAPI
+func (m *Mock) ExecutePayment(paymentID, payerID string, transactions []paypal.Transaction) (*paypal.ExecutePaymentResp, error) {
+	return m.ExecutePaymentContext(context.Background(), paymentID, payerID, transactions)
+}
+
+// ExecutePaymentContext implements paypal.PaymentsAPI
+func (m *Mock) ExecutePaymentContext(ctx context.Context, paymentID, payerID string, transactions []paypal.Transaction) (*paypal.ExecutePaymentResp, error) {
+	m.record("ExecutePayment", paymentID, payerID, transactions)
+	if m.ExecutePaymentFunc == nil {
+		return nil, notStubbed("ExecutePayment")
+	}
+	return m.ExecutePaymentFunc(ctx, paymentID, payerID, transactions)
+}
+
+// GetPayment implements paypal.PaymentsAPI
+func (m *Mock) GetPayment(id string) (*paypal.Payment, error) {
+	return m.GetPaymentContext(context.Background(), id)
+}
+
+// GetPaymentContext implements paypal.PaymentsAPI
+func (m *Mock) GetPaymentContext(ctx context.Context, id string) (*paypal.Payment, error) {
+	m.record("GetPayment", id)
+	if m.GetPaymentFunc == nil {
+		return nil, notStubbed("GetPayment")
+	}
+	return m.GetPaymentFunc(ctx, id)
+}
+
+// ListPayments implements paypal.PaymentsAPI
+func (m *Mock) ListPayments(filter map[string]string) ([]paypal.Payment, error) {
+	return m.ListPaymentsContext(context.Background(), filter)
+}
+
+// ListPaymentsContext implements paypal.PaymentsAPI
+func (m *Mock) ListPaymentsContext(ctx context.Context, filter map[string]string) ([]paypal.Payment, error) {
+	m.record("ListPayments", filter)
+	if m.ListPaymentsFunc == nil {
+		return nil, notStubbed("ListPayments")
+	}
+	return m.ListPaymentsFunc(ctx, filter)
+}
+
+// GetSale implements paypal.SalesAPI
+func (m *Mock) GetSale(saleID string) (*paypal.Sale, error) {
+	return m.GetSaleContext(context.Background(), saleID)
+}
+
+// GetSaleContext implements paypal.SalesAPI
+func (m *Mock) GetSaleContext(ctx context.Context, saleID string) (*paypal.Sale, error) {
+	m.record("GetSale", saleID)
+	if m.GetSaleFunc == nil {
+		return nil, notStubbed("GetSale")
+	}
+	return m.GetSaleFunc(ctx, saleID)
+}
+
+// RefundSale implements paypal.SalesAPI
+func (m *Mock) RefundSale(saleID string, a *paypal.Amount) (*paypal.Refund, error) {
+	return m.RefundSaleContext(context.Background(), saleID, a)
+}
+
+// RefundSaleContext implements paypal.SalesAPI
+func (m *Mock) RefundSaleContext(ctx context.Context, saleID string, a *paypal.Amount) (*paypal.Refund, error) {
+	m.record("RefundSale", saleID, a)
+	if m.RefundSaleFunc == nil {
+		return nil, notStubbed("RefundSale")
+	}
+	return m.RefundSaleFunc(ctx, saleID, a)
+}
+
+// GetAuthorization implements paypal.AuthorizationsAPI
+func (m *Mock) GetAuthorization(authID string) (*paypal.Authorization, error) {
+	return m.GetAuthorizationContext(context.Background(), authID)
+}
+
+// GetAuthorizationContext implements paypal.AuthorizationsAPI
+func (m *Mock) GetAuthorizationContext(ctx context.Context, authID string) (*paypal.Authorization, error) {
+	m.record("GetAuthorization", authID)
+	if m.GetAuthorizationFunc == nil {
+		return nil, notStubbed("GetAuthorization")
+	}
+	return m.GetAuthorizationFunc(ctx, authID)
+}
+
+// CaptureAuthorization implements paypal.AuthorizationsAPI
+func (m *Mock) CaptureAuthorization(authID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error) {
+	return m.CaptureAuthorizationContext(context.Background(), authID, a, isFinalCapture)
+}
+
+// CaptureAuthorizationContext implements paypal.AuthorizationsAPI
+func (m *Mock) CaptureAuthorizationContext(ctx context.Context, authID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error) {
+	m.record("CaptureAuthorization", authID, a, isFinalCapture)
+	if m.CaptureAuthorizationFunc == nil {
+		return nil, notStubbed("CaptureAuthorization")
+	}
+	return m.CaptureAuthorizationFunc(ctx, authID, a, isFinalCapture)
+}
+
+// VoidAuthorization implements paypal.AuthorizationsAPI
+func (m *Mock) VoidAuthorization(authID string) (*paypal.Authorization, error) {
+	return m.VoidAuthorizationContext(context.Background(), authID)
+}
+
+// VoidAuthorizationContext implements paypal.AuthorizationsAPI
+func (m *Mock) VoidAuthorizationContext(ctx context.Context, authID string) (*paypal.Authorization, error) {
+	m.record("VoidAuthorization", authID)
+	if m.VoidAuthorizationFunc == nil {
+		return nil, notStubbed("VoidAuthorization")
+	}
+	return m.VoidAuthorizationFunc(ctx, authID)
+}
+
+// ReauthorizeAuthorization implements paypal.AuthorizationsAPI
+func (m *Mock) ReauthorizeAuthorization(authID string, a *paypal.Amount) (*paypal.Authorization, error) {
+	return m.ReauthorizeAuthorizationContext(context.Background(), authID, a)
+}
+
+// ReauthorizeAuthorizationContext implements paypal.AuthorizationsAPI
+func (m *Mock) ReauthorizeAuthorizationContext(ctx context.Context, authID string, a *paypal.Amount) (*paypal.Authorization, error) {
+	m.record("ReauthorizeAuthorization", authID, a)
+	if m.ReauthorizeAuthorizationFunc == nil {
+		return nil, notStubbed("ReauthorizeAuthorization")
+	}
+	return m.ReauthorizeAuthorizationFunc(ctx, authID, a)
+}
+
+// GetOrder implements paypal.OrdersAPI
+func (m *Mock) GetOrder(orderID string) (*paypal.Order, error) {
+	return m.GetOrderContext(context.Background(), orderID)
+}
+
+// GetOrderContext implements paypal.OrdersAPI
+func (m *Mock) GetOrderContext(ctx context.Context, orderID string) (*paypal.Order, error) {
+	m.record("GetOrder", orderID)
+	if m.GetOrderFunc == nil {
+		return nil, notStubbed("GetOrder")
+	}
+	return m.GetOrderFunc(ctx, orderID)
+}
+
+// AuthorizeOrder implements paypal.OrdersAPI
+func (m *Mock) AuthorizeOrder(orderID string, a *paypal.Amount) (*paypal.Authorization, error) {
+	return m.AuthorizeOrderContext(context.Background(), orderID, a)
+}
+
+// AuthorizeOrderContext implements paypal.OrdersAPI
+func (m *Mock) AuthorizeOrderContext(ctx context.Context, orderID string, a *paypal.Amount) (*paypal.Authorization, error) {
+	m.record("AuthorizeOrder", orderID, a)
+	if m.AuthorizeOrderFunc == nil {
+		return nil, notStubbed("AuthorizeOrder")
+	}
+	return m.AuthorizeOrderFunc(ctx, orderID, a)
+}
+
+// CaptureOrder implements paypal.OrdersAPI
+func (m *Mock) CaptureOrder(orderID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error) {
+	return m.CaptureOrderContext(context.Background(), orderID, a, isFinalCapture)
+}
+
+// CaptureOrderContext implements paypal.OrdersAPI
+func (m *Mock) CaptureOrderContext(ctx context.Context, orderID string, a *paypal.Amount, isFinalCapture bool) (*paypal.Capture, error) {
+	m.record("CaptureOrder", orderID, a, isFinalCapture)
+	if m.CaptureOrderFunc == nil {
+		return nil, notStubbed("CaptureOrder")
+	}
+	return m.CaptureOrderFunc(ctx, orderID, a, isFinalCapture)
+}
+
+// VoidOrder implements paypal.OrdersAPI
+func (m *Mock) VoidOrder(orderID string) (*paypal.Order, error) {
+	return m.VoidOrderContext(context.Background(), orderID)
+}
+
+// VoidOrderContext implements paypal.OrdersAPI
+func (m *Mock) VoidOrderContext(ctx context.Context, orderID string) (*paypal.Order, error) {
+	m.record("VoidOrder", orderID)
+	if m.VoidOrderFunc == nil {
+		return nil, notStubbed("VoidOrder")
+	}
+	return m.VoidOrderFunc(ctx, orderID)
+}
+
+// RefundOrder implements paypal.OrdersAPI
+func (m *Mock) RefundOrder(orderID string, a *paypal.Amount) (*paypal.Refund, error) {
+	return m.RefundOrderContext(context.Background(), orderID, a)
+}
+
+// RefundOrderContext implements paypal.OrdersAPI
+func (m *Mock) RefundOrderContext(ctx context.Context, orderID string, a *paypal.Amount) (*paypal.Refund, error) {
+	m.record("RefundOrder", orderID, a)
+	if m.RefundOrderFunc == nil {
+		return nil, notStubbed("RefundOrder")
+	}
+	return m.RefundOrderFunc(ctx, orderID, a)
+}
+
+// GetCapture implements paypal.CapturesAPI
+func (m *Mock) GetCapture(captureID string) (*paypal.Capture, error) {
+	return m.GetCaptureContext(context.Background(), captureID)
+}
+
+// GetCaptureContext implements paypal.CapturesAPI
+func (m *Mock) GetCaptureContext(ctx context.Context, captureID string) (*paypal.Capture, error) {
+	m.record("GetCapture", captureID)
+	if m.GetCaptureFunc == nil {
+		return nil, notStubbed("GetCapture")
+	}
+	return m.GetCaptureFunc(ctx, captureID)
+}
+
+// RefundCapture implements paypal.CapturesAPI
+func (m *Mock) RefundCapture(captureID string, a *paypal.Amount) (*paypal.Refund, error) {
+	return m.RefundCaptureContext(context.Background(), captureID, a)
+}
+
+// RefundCaptureContext implements paypal.CapturesAPI
+func (m *Mock) RefundCaptureContext(ctx context.Context, captureID string, a *paypal.Amount) (*paypal.Refund, error) {
+	m.record("RefundCapture", captureID, a)
+	if m.RefundCaptureFunc == nil {
+		return nil, notStubbed("RefundCapture")
+	}
+	return m.RefundCaptureFunc(ctx, captureID, a)
+}
+
+// GetRefund implements paypal.RefundsAPI
+func (m *Mock) GetRefund(refundID string) (*paypal.Refund, error) {
+	return m.GetRefundContext(context.Background(), refundID)
+}
+
+// GetRefundContext implements paypal.RefundsAPI
+func (m *Mock) GetRefundContext(ctx context.Context, refundID string) (*paypal.Refund, error) {
+	m.record("GetRefund", refundID)
+	if m.GetRefundFunc == nil {
+		return nil, notStubbed("GetRefund")
+	}
+	return m.GetRefundFunc(ctx, refundID)
+}
+
+// StoreInVault implements paypal.VaultAPI
+func (m *Mock) StoreInVault(cc paypal.VaultRequest) (*paypal.VaultResponse, error) {
+	return m.StoreInVaultContext(context.Background(), cc)
+}
+
+// StoreInVaultContext implements paypal.VaultAPI
+func (m *Mock) StoreInVaultContext(ctx context.Context, cc paypal.VaultRequest) (*paypal.VaultResponse, error) {
+	m.record("StoreInVault", cc)
+	if m.StoreInVaultFunc == nil {
+		return nil, notStubbed("StoreInVault")
+	}
+	return m.StoreInVaultFunc(ctx, cc)
+}
+
+// GetStoredCreditCard implements paypal.VaultAPI
+func (m *Mock) GetStoredCreditCard(cardID string) (*paypal.VaultResponse, error) {
+	return m.GetStoredCreditCardContext(context.Background(), cardID)
+}
+
+// GetStoredCreditCardContext implements paypal.VaultAPI
+func (m *Mock) GetStoredCreditCardContext(ctx context.Context, cardID string) (*paypal.VaultResponse, error) {
+	m.record("GetStoredCreditCard", cardID)
+	if m.GetStoredCreditCardFunc == nil {
+		return nil, notStubbed("GetStoredCreditCard")
+	}
+	return m.GetStoredCreditCardFunc(ctx, cardID)
+}
+
+// ListStoredCreditCards implements paypal.VaultAPI
+func (m *Mock) ListStoredCreditCards(filter map[string]string) (*paypal.ListStoredCreditCardsResp, error) {
+	return m.ListStoredCreditCardsContext(context.Background(), filter)
+}
+
+// ListStoredCreditCardsContext implements paypal.VaultAPI
+func (m *Mock) ListStoredCreditCardsContext(ctx context.Context, filter map[string]string) (*paypal.ListStoredCreditCardsResp, error) {
+	m.record("ListStoredCreditCards", filter)
+	if m.ListStoredCreditCardsFunc == nil {
+		return nil, notStubbed("ListStoredCreditCards")
+	}
+	return m.ListStoredCreditCardsFunc(ctx, filter)
+}
+
+// UpdateStoredCreditCard implements paypal.VaultAPI
+func (m *Mock) UpdateStoredCreditCard(cardID string, patches []paypal.Patch) (*paypal.VaultResponse, error) {
+	return m.UpdateStoredCreditCardContext(context.Background(), cardID, patches)
+}
+
+// UpdateStoredCreditCardContext implements paypal.VaultAPI
+func (m *Mock) UpdateStoredCreditCardContext(ctx context.Context, cardID string, patches []paypal.Patch) (*paypal.VaultResponse, error) {
+	m.record("UpdateStoredCreditCard", cardID, patches)
+	if m.UpdateStoredCreditCardFunc == nil {
+		return nil, notStubbed("UpdateStoredCreditCard")
+	}
+	return m.UpdateStoredCreditCardFunc(ctx, cardID, patches)
+}
+
+// DeleteStoredCreditCard implements paypal.VaultAPI
+func (m *Mock) DeleteStoredCreditCard(cardID string) error {
+	return m.DeleteStoredCreditCardContext(context.Background(), cardID)
+}
+
+// DeleteStoredCreditCardContext implements paypal.VaultAPI
+func (m *Mock) DeleteStoredCreditCardContext(ctx context.Context, cardID string) error {
+	m.record("DeleteStoredCreditCard", cardID)
+	if m.DeleteStoredCreditCardFunc == nil {
+		return notStubbed("DeleteStoredCreditCard")
+	}
+	return m.DeleteStoredCreditCardFunc(ctx, cardID)
+}
+
+// CreatePlan implements paypal.PlansAPI
+func (m *Mock) CreatePlan(p paypal.Plan) (*paypal.Plan, error) {
+	return m.CreatePlanContext(context.Background(), p)
+}
+
+// CreatePlanContext implements paypal.PlansAPI
+func (m *Mock) CreatePlanContext(ctx context.Context, p paypal.Plan) (*paypal.Plan, error) {
+	m.record("CreatePlan", p)
+	if m.CreatePlanFunc == nil {
+		return nil, notStubbed("CreatePlan")
+	}
+	return m.CreatePlanFunc(ctx, p)
+}
+
+// GetPlan implements paypal.PlansAPI
+func (m *Mock) GetPlan(planID string) (*paypal.Plan, error) {
+	return m.GetPlanContext(context.Background(), planID)
+}
+
+// GetPlanContext implements paypal.PlansAPI
+func (m *Mock) GetPlanContext(ctx context.Context, planID string) (*paypal.Plan, error) {
+	m.record("GetPlan", planID)
+	if m.GetPlanFunc == nil {
+		return nil, notStubbed("GetPlan")
+	}
+	return m.GetPlanFunc(ctx, planID)
+}
+
+// ListPlans implements paypal.PlansAPI
+func (m *Mock) ListPlans(filter map[string]string) (*paypal.ListPlansResp, error) {
+	return m.ListPlansContext(context.Background(), filter)
+}
+
+// ListPlansContext implements paypal.PlansAPI
+func (m *Mock) ListPlansContext(ctx context.Context, filter map[string]string) (*paypal.ListPlansResp, error) {
+	m.record("ListPlans", filter)
+	if m.ListPlansFunc == nil {
+		return nil, notStubbed("ListPlans")
+	}
+	return m.ListPlansFunc(ctx, filter)
+}
+
+// UpdatePlan implements paypal.PlansAPI
+func (m *Mock) UpdatePlan(planID string, patches []paypal.Patch) error {
+	return m.UpdatePlanContext(context.Background(), planID, patches)
+}
+
+// UpdatePlanContext implements paypal.PlansAPI
+func (m *Mock) UpdatePlanContext(ctx context.Context, planID string, patches []paypal.Patch) error {
+	m.record("UpdatePlan", planID, patches)
+	if m.UpdatePlanFunc == nil {
+		return notStubbed("UpdatePlan")
+	}
+	return m.UpdatePlanFunc(ctx, planID, patches)
+}
+
+// ActivatePlan implements paypal.PlansAPI
+func (m *Mock) ActivatePlan(planID string) error {
+	return m.ActivatePlanContext(context.Background(), planID)
+}
+
+// ActivatePlanContext implements paypal.PlansAPI
+func (m *Mock) ActivatePlanContext(ctx context.Context, planID string) error {
+	m.record("ActivatePlan", planID)
+	if m.ActivatePlanFunc == nil {
+		return notStubbed("ActivatePlan")
+	}
+	return m.ActivatePlanFunc(ctx, planID)
+}
+
+// DeactivatePlan implements paypal.PlansAPI
+func (m *Mock) DeactivatePlan(planID string) error {
+	return m.DeactivatePlanContext(context.Background(), planID)
+}
+
+// DeactivatePlanContext implements paypal.PlansAPI
+func (m *Mock) DeactivatePlanContext(ctx context.Context, planID string) error {
+	m.record("DeactivatePlan", planID)
+	if m.DeactivatePlanFunc == nil {
+		return notStubbed("DeactivatePlan")
+	}
+	return m.DeactivatePlanFunc(ctx, planID)
+}
+
+// CreateAgreement implements paypal.AgreementsAPI
+func (m *Mock) CreateAgreement(a paypal.Agreement) (*paypal.CreateAgreementResp, error) {
+	return m.CreateAgreementContext(context.Background(), a)
+}
+
+// CreateAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) CreateAgreementContext(ctx context.Context, a paypal.Agreement) (*paypal.CreateAgreementResp, error) {
+	m.record("CreateAgreement", a)
+	if m.CreateAgreementFunc == nil {
+		return nil, notStubbed("CreateAgreement")
+	}
+	return m.CreateAgreementFunc(ctx, a)
+}
+
+// ExecuteAgreement implements paypal.AgreementsAPI
+func (m *Mock) ExecuteAgreement(token string) (*paypal.Agreement, error) {
+	return m.ExecuteAgreementContext(context.Background(), token)
+}
+
+// ExecuteAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) ExecuteAgreementContext(ctx context.Context, token string) (*paypal.Agreement, error) {
+	m.record("ExecuteAgreement", token)
+	if m.ExecuteAgreementFunc == nil {
+		return nil, notStubbed("ExecuteAgreement")
+	}
+	return m.ExecuteAgreementFunc(ctx, token)
+}
+
+// GetAgreement implements paypal.AgreementsAPI
+func (m *Mock) GetAgreement(agreementID string) (*paypal.Agreement, error) {
+	return m.GetAgreementContext(context.Background(), agreementID)
+}
+
+// GetAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) GetAgreementContext(ctx context.Context, agreementID string) (*paypal.Agreement, error) {
+	m.record("GetAgreement", agreementID)
+	if m.GetAgreementFunc == nil {
+		return nil, notStubbed("GetAgreement")
+	}
+	return m.GetAgreementFunc(ctx, agreementID)
+}
+
+// UpdateAgreement implements paypal.AgreementsAPI
+func (m *Mock) UpdateAgreement(agreementID string, patches []paypal.Patch) error {
+	return m.UpdateAgreementContext(context.Background(), agreementID, patches)
+}
+
+// UpdateAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) UpdateAgreementContext(ctx context.Context, agreementID string, patches []paypal.Patch) error {
+	m.record("UpdateAgreement", agreementID, patches)
+	if m.UpdateAgreementFunc == nil {
+		return notStubbed("UpdateAgreement")
+	}
+	return m.UpdateAgreementFunc(ctx, agreementID, patches)
+}
+
+// SuspendAgreement implements paypal.AgreementsAPI
+func (m *Mock) SuspendAgreement(agreementID, note string) error {
+	return m.SuspendAgreementContext(context.Background(), agreementID, note)
+}
+
+// SuspendAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) SuspendAgreementContext(ctx context.Context, agreementID, note string) error {
+	m.record("SuspendAgreement", agreementID, note)
+	if m.SuspendAgreementFunc == nil {
+		return notStubbed("SuspendAgreement")
+	}
+	return m.SuspendAgreementFunc(ctx, agreementID, note)
+}
+
+// ReactivateAgreement implements paypal.AgreementsAPI
+func (m *Mock) ReactivateAgreement(agreementID, note string) error {
+	return m.ReactivateAgreementContext(context.Background(), agreementID, note)
+}
+
+// ReactivateAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) ReactivateAgreementContext(ctx context.Context, agreementID, note string) error {
+	m.record("ReactivateAgreement", agreementID, note)
+	if m.ReactivateAgreementFunc == nil {
+		return notStubbed("ReactivateAgreement")
+	}
+	return m.ReactivateAgreementFunc(ctx, agreementID, note)
+}
+
+// CancelAgreement implements paypal.AgreementsAPI
+func (m *Mock) CancelAgreement(agreementID, note string) error {
+	return m.CancelAgreementContext(context.Background(), agreementID, note)
+}
+
+// CancelAgreementContext implements paypal.AgreementsAPI
+func (m *Mock) CancelAgreementContext(ctx context.Context, agreementID, note string) error {
+	m.record("CancelAgreement", agreementID, note)
+	if m.CancelAgreementFunc == nil {
+		return notStubbed("CancelAgreement")
+	}
+	return m.CancelAgreementFunc(ctx, agreementID, note)
+}
+
+// SetAgreementBalance implements paypal.AgreementsAPI
+func (m *Mock) SetAgreementBalance(agreementID string, balance paypal.Currency) error {
+	return m.SetAgreementBalanceContext(context.Background(), agreementID, balance)
+}
+
+// SetAgreementBalanceContext implements paypal.AgreementsAPI
+func (m *Mock) SetAgreementBalanceContext(ctx context.Context, agreementID string, balance paypal.Currency) error {
+	m.record("SetAgreementBalance", agreementID, balance)
+	if m.SetAgreementBalanceFunc == nil {
+		return notStubbed("SetAgreementBalance")
+	}
+	return m.SetAgreementBalanceFunc(ctx, agreementID, balance)
+}
+
+// BillAgreementBalance implements paypal.AgreementsAPI
+func (m *Mock) BillAgreementBalance(agreementID, note string, amount *paypal.Currency) error {
+	return m.BillAgreementBalanceContext(context.Background(), agreementID, note, amount)
+}
+
+// BillAgreementBalanceContext implements paypal.AgreementsAPI
+func (m *Mock) BillAgreementBalanceContext(ctx context.Context, agreementID, note string, amount *paypal.Currency) error {
+	m.record("BillAgreementBalance", agreementID, note, amount)
+	if m.BillAgreementBalanceFunc == nil {
+		return notStubbed("BillAgreementBalance")
+	}
+	return m.BillAgreementBalanceFunc(ctx, agreementID, note, amount)
+}
+
+// ListAgreementTransactions implements paypal.AgreementsAPI
+func (m *Mock) ListAgreementTransactions(agreementID string, start, end time.Time) ([]paypal.AgreementTransaction, error) {
+	return m.ListAgreementTransactionsContext(context.Background(), agreementID, start, end)
+}
+
+// ListAgreementTransactionsContext implements paypal.AgreementsAPI
+func (m *Mock) ListAgreementTransactionsContext(ctx context.Context, agreementID string, start, end time.Time) ([]paypal.AgreementTransaction, error) {
+	m.record("ListAgreementTransactions", agreementID, start, end)
+	if m.ListAgreementTransactionsFunc == nil {
+		return nil, notStubbed("ListAgreementTransactions")
+	}
+	return m.ListAgreementTransactionsFunc(ctx, agreementID, start, end)
+}
+
+// CreateWebhook implements paypal.WebhooksAPI
+func (m *Mock) CreateWebhook(url string, eventTypes []paypal.EventType) (*paypal.Webhook, error) {
+	return m.CreateWebhookContext(context.Background(), url, eventTypes)
+}
+
+// CreateWebhookContext implements paypal.WebhooksAPI
+func (m *Mock) CreateWebhookContext(ctx context.Context, url string, eventTypes []paypal.EventType) (*paypal.Webhook, error) {
+	m.record("CreateWebhook", url, eventTypes)
+	if m.CreateWebhookFunc == nil {
+		return nil, notStubbed("CreateWebhook")
+	}
+	return m.CreateWebhookFunc(ctx, url, eventTypes)
+}
+
+// ListWebhooks implements paypal.WebhooksAPI
+func (m *Mock) ListWebhooks() ([]paypal.Webhook, error) {
+	return m.ListWebhooksContext(context.Background())
+}
+
+// ListWebhooksContext implements paypal.WebhooksAPI
+func (m *Mock) ListWebhooksContext(ctx context.Context) ([]paypal.Webhook, error) {
+	m.record("ListWebhooks")
+	if m.ListWebhooksFunc == nil {
+		return nil, notStubbed("ListWebhooks")
+	}
+	return m.ListWebhooksFunc(ctx)
+}
+
+// GetWebhook implements paypal.WebhooksAPI
+func (m *Mock) GetWebhook(webhookID string) (*paypal.Webhook, error) {
+	return m.GetWebhookContext(context.Background(), webhookID)
+}
+
+// GetWebhookContext implements paypal.WebhooksAPI
+func (m *Mock) GetWebhookContext(ctx context.Context, webhookID string) (*paypal.Webhook, error) {
+	m.record("GetWebhook", webhookID)
+	if m.GetWebhookFunc == nil {
+		return nil, notStubbed("GetWebhook")
+	}
+	return m.GetWebhookFunc(ctx, webhookID)
+}
+
+// UpdateWebhook implements paypal.WebhooksAPI
+func (m *Mock) UpdateWebhook(webhookID string, patches []paypal.Patch) (*paypal.Webhook, error) {
+	return m.UpdateWebhookContext(context.Background(), webhookID, patches)
+}
+
+// UpdateWebhookContext implements paypal.WebhooksAPI
+func (m *Mock) UpdateWebhookContext(ctx context.Context, webhookID string, patches []paypal.Patch) (*paypal.Webhook, error) {
+	m.record("UpdateWebhook", webhookID, patches)
+	if m.UpdateWebhookFunc == nil {
+		return nil, notStubbed("UpdateWebhook")
+	}
+	return m.UpdateWebhookFunc(ctx, webhookID, patches)
+}
+
+// DeleteWebhook implements paypal.WebhooksAPI
+func (m *Mock) DeleteWebhook(webhookID string) error {
+	return m.DeleteWebhookContext(context.Background(), webhookID)
+}
+
+// DeleteWebhookContext implements paypal.WebhooksAPI
+func (m *Mock) DeleteWebhookContext(ctx context.Context, webhookID string) error {
+	m.record("DeleteWebhook", webhookID)
+	if m.DeleteWebhookFunc == nil {
+		return notStubbed("DeleteWebhook")
+	}
+	return m.DeleteWebhookFunc(ctx, webhookID)
+}
+
+// ListWebhookEventTypes implements paypal.WebhooksAPI
+func (m *Mock) ListWebhookEventTypes(webhookID string) ([]paypal.WebhookEventType, error) {
+	return m.ListWebhookEventTypesContext(context.Background(), webhookID)
+}
+
+// ListWebhookEventTypesContext implements paypal.WebhooksAPI
+func (m *Mock) ListWebhookEventTypesContext(ctx context.Context, webhookID string) ([]paypal.WebhookEventType, error) {
+	m.record("ListWebhookEventTypes", webhookID)
+	if m.ListWebhookEventTypesFunc == nil {
+		return nil, notStubbed("ListWebhookEventTypes")
+	}
+	return m.ListWebhookEventTypesFunc(ctx, webhookID)
+}
+
+// ListAvailableEventTypes implements paypal.WebhooksAPI
+func (m *Mock) ListAvailableEventTypes() ([]paypal.WebhookEventType, error) {
+	return m.ListAvailableEventTypesContext(context.Background())
+}
+
+// ListAvailableEventTypesContext implements paypal.WebhooksAPI
+func (m *Mock) ListAvailableEventTypesContext(ctx context.Context) ([]paypal.WebhookEventType, error) {
+	m.record("ListAvailableEventTypes")
+	if m.ListAvailableEventTypesFunc == nil {
+		return nil, notStubbed("ListAvailableEventTypes")
+	}
+	return m.ListAvailableEventTypesFunc(ctx)
+}
+
+// ListWebhookEvents implements paypal.WebhooksAPI
+func (m *Mock) ListWebhookEvents(filter map[string]string) (*paypal.ListWebhookEventsResp, error) {
+	return m.ListWebhookEventsContext(context.Background(), filter)
+}
+
+// ListWebhookEventsContext implements paypal.WebhooksAPI
+func (m *Mock) ListWebhookEventsContext(ctx context.Context, filter map[string]string) (*paypal.ListWebhookEventsResp, error) {
+	m.record("ListWebhookEvents", filter)
+	if m.ListWebhookEventsFunc == nil {
+		return nil, notStubbed("ListWebhookEvents")
+	}
+	return m.ListWebhookEventsFunc(ctx, filter)
+}
+
+// GetWebhookEvent implements paypal.WebhooksAPI
+func (m *Mock) GetWebhookEvent(eventID string) (*paypal.WebhookEvent, error) {
+	return m.GetWebhookEventContext(context.Background(), eventID)
+}
+
+// GetWebhookEventContext implements paypal.WebhooksAPI
+func (m *Mock) GetWebhookEventContext(ctx context.Context, eventID string) (*paypal.WebhookEvent, error) {
+	m.record("GetWebhookEvent", eventID)
+	if m.GetWebhookEventFunc == nil {
+		return nil, notStubbed("GetWebhookEvent")
+	}
+	return m.GetWebhookEventFunc(ctx, eventID)
+}
+
+// ResendWebhookEvent implements paypal.WebhooksAPI
+func (m *Mock) ResendWebhookEvent(eventID string, webhookIDs []string) (*paypal.WebhookEvent, error) {
+	return m.ResendWebhookEventContext(context.Background(), eventID, webhookIDs)
+}
+
+// ResendWebhookEventContext implements paypal.WebhooksAPI
+func (m *Mock) ResendWebhookEventContext(ctx context.Context, eventID string, webhookIDs []string) (*paypal.WebhookEvent, error) {
+	m.record("ResendWebhookEvent", eventID, webhookIDs)
+	if m.ResendWebhookEventFunc == nil {
+		return nil, notStubbed("ResendWebhookEvent")
+	}
+	return m.ResendWebhookEventFunc(ctx, eventID, webhookIDs)
+}
+
+// SimulateWebhookEvent implements paypal.WebhooksAPI
+func (m *Mock) SimulateWebhookEvent(s paypal.SimulateWebhookEventReq) (*paypal.WebhookEvent, error) {
+	return m.SimulateWebhookEventContext(context.Background(), s)
+}
+
+// SimulateWebhookEventContext implements paypal.WebhooksAPI
+func (m *Mock) SimulateWebhookEventContext(ctx context.Context, s paypal.SimulateWebhookEventReq) (*paypal.WebhookEvent, error) {
+	m.record("SimulateWebhookEvent", s)
+	if m.SimulateWebhookEventFunc == nil {
+		return nil, notStubbed("SimulateWebhookEvent")
+	}
+	return m.SimulateWebhookEventFunc(ctx, s)
+}
+
+// VerifyWebhookSignature implements paypal.WebhooksAPI
+func (m *Mock) VerifyWebhookSignature(v paypal.VerifyWebhookSignatureReq) (*paypal.VerifyWebhookSignatureResp, error) {
+	return m.VerifyWebhookSignatureContext(context.Background(), v)
+}
+
+// VerifyWebhookSignatureContext implements paypal.WebhooksAPI
+func (m *Mock) VerifyWebhookSignatureContext(ctx context.Context, v paypal.VerifyWebhookSignatureReq) (*paypal.VerifyWebhookSignatureResp, error) {
+	m.record("VerifyWebhookSignature", v)
+	if m.VerifyWebhookSignatureFunc == nil {
+		return nil, notStubbed("VerifyWebhookSignature")
+	}
+	return m.VerifyWebhookSignatureFunc(ctx, v)
+}
+
+// ExchangeAuthorizationCode implements paypal.IdentityAPI
+func (m *Mock) ExchangeAuthorizationCode(code, redirectURI string) (*paypal.IdentityToken, error) {
+	return m.ExchangeAuthorizationCodeContext(context.Background(), code, redirectURI)
+}
+
+// ExchangeAuthorizationCodeContext implements paypal.IdentityAPI
+func (m *Mock) ExchangeAuthorizationCodeContext(ctx context.Context, code, redirectURI string) (*paypal.IdentityToken, error) {
+	m.record("ExchangeAuthorizationCode", code, redirectURI)
+	if m.ExchangeAuthorizationCodeFunc == nil {
+		return nil, notStubbed("ExchangeAuthorizationCode")
+	}
+	return m.ExchangeAuthorizationCodeFunc(ctx, code, redirectURI)
+}
+
+// RefreshIdentityToken implements paypal.IdentityAPI
+func (m *Mock) RefreshIdentityToken(refreshToken string) (*paypal.IdentityToken, error) {
+	return m.RefreshIdentityTokenContext(context.Background(), refreshToken)
+}
+
+// RefreshIdentityTokenContext implements paypal.IdentityAPI
+func (m *Mock) RefreshIdentityTokenContext(ctx context.Context, refreshToken string) (*paypal.IdentityToken, error) {
+	m.record("RefreshIdentityToken", refreshToken)
+	if m.RefreshIdentityTokenFunc == nil {
+		return nil, notStubbed("RefreshIdentityToken")
+	}
+	return m.RefreshIdentityTokenFunc(ctx, refreshToken)
+}
+
+// GetUserInfo implements paypal.IdentityAPI
+func (m *Mock) GetUserInfo(accessToken string) (*paypal.UserInfo, error) {
+	return m.GetUserInfoContext(context.Background(), accessToken)
+}
+
+// GetUserInfoContext implements paypal.IdentityAPI
+func (m *Mock) GetUserInfoContext(ctx context.Context, accessToken string) (*paypal.UserInfo, error) {
+	m.record("GetUserInfo", accessToken)
+	if m.GetUserInfoFunc == nil {
+		return nil, notStubbed("GetUserInfo")
+	}
+	return m.GetUserInfoFunc(ctx, accessToken)
+}
+
+// CreateInvoice implements paypal.InvoicesAPI
+func (m *Mock) CreateInvoice(i paypal.Invoice) (*paypal.Invoice, error) {
+	return m.CreateInvoiceContext(context.Background(), i)
+}
+
+// CreateInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) CreateInvoiceContext(ctx context.Context, i paypal.Invoice) (*paypal.Invoice, error) {
+	m.record("CreateInvoice", i)
+	if m.CreateInvoiceFunc == nil {
+		return nil, notStubbed("CreateInvoice")
+	}
+	return m.CreateInvoiceFunc(ctx, i)
+}
+
+// GetInvoice implements paypal.InvoicesAPI
+func (m *Mock) GetInvoice(invoiceID string) (*paypal.Invoice, error) {
+	return m.GetInvoiceContext(context.Background(), invoiceID)
+}
+
+// GetInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) GetInvoiceContext(ctx context.Context, invoiceID string) (*paypal.Invoice, error) {
+	m.record("GetInvoice", invoiceID)
+	if m.GetInvoiceFunc == nil {
+		return nil, notStubbed("GetInvoice")
+	}
+	return m.GetInvoiceFunc(ctx, invoiceID)
+}
+
+// UpdateInvoice implements paypal.InvoicesAPI
+func (m *Mock) UpdateInvoice(invoiceID string, i paypal.Invoice) (*paypal.Invoice, error) {
+	return m.UpdateInvoiceContext(context.Background(), invoiceID, i)
+}
+
+// UpdateInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) UpdateInvoiceContext(ctx context.Context, invoiceID string, i paypal.Invoice) (*paypal.Invoice, error) {
+	m.record("UpdateInvoice", invoiceID, i)
+	if m.UpdateInvoiceFunc == nil {
+		return nil, notStubbed("UpdateInvoice")
+	}
+	return m.UpdateInvoiceFunc(ctx, invoiceID, i)
+}
+
+// DeleteInvoice implements paypal.InvoicesAPI
+func (m *Mock) DeleteInvoice(invoiceID string) error {
+	return m.DeleteInvoiceContext(context.Background(), invoiceID)
+}
+
+// DeleteInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) DeleteInvoiceContext(ctx context.Context, invoiceID string) error {
+	m.record("DeleteInvoice", invoiceID)
+	if m.DeleteInvoiceFunc == nil {
+		return notStubbed("DeleteInvoice")
+	}
+	return m.DeleteInvoiceFunc(ctx, invoiceID)
+}
+
+// SendInvoice implements paypal.InvoicesAPI
+func (m *Mock) SendInvoice(invoiceID string, notifyMerchant bool) error {
+	return m.SendInvoiceContext(context.Background(), invoiceID, notifyMerchant)
+}
+
+// SendInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) SendInvoiceContext(ctx context.Context, invoiceID string, notifyMerchant bool) error {
+	m.record("SendInvoice", invoiceID, notifyMerchant)
+	if m.SendInvoiceFunc == nil {
+		return notStubbed("SendInvoice")
+	}
+	return m.SendInvoiceFunc(ctx, invoiceID, notifyMerchant)
+}
+
+// RemindInvoice implements paypal.InvoicesAPI
+func (m *Mock) RemindInvoice(invoiceID string, n paypal.InvoiceNotification) error {
+	return m.RemindInvoiceContext(context.Background(), invoiceID, n)
+}
+
+// RemindInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) RemindInvoiceContext(ctx context.Context, invoiceID string, n paypal.InvoiceNotification) error {
+	m.record("RemindInvoice", invoiceID, n)
+	if m.RemindInvoiceFunc == nil {
+		return notStubbed("RemindInvoice")
+	}
+	return m.RemindInvoiceFunc(ctx, invoiceID, n)
+}
+
+// CancelInvoice implements paypal.InvoicesAPI
+func (m *Mock) CancelInvoice(invoiceID string, n paypal.InvoiceNotification) error {
+	return m.CancelInvoiceContext(context.Background(), invoiceID, n)
+}
+
+// CancelInvoiceContext implements paypal.InvoicesAPI
+func (m *Mock) CancelInvoiceContext(ctx context.Context, invoiceID string, n paypal.InvoiceNotification) error {
+	m.record("CancelInvoice", invoiceID, n)
+	if m.CancelInvoiceFunc == nil {
+		return notStubbed("CancelInvoice")
+	}
+	return m.CancelInvoiceFunc(ctx, invoiceID, n)
+}
+
+// RecordInvoicePayment implements paypal.InvoicesAPI
+func (m *Mock) RecordInvoicePayment(invoiceID string, p paypal.InvoicePaymentDetail) error {
+	return m.RecordInvoicePaymentContext(context.Background(), invoiceID, p)
+}
+
+// RecordInvoicePaymentContext implements paypal.InvoicesAPI
+func (m *Mock) RecordInvoicePaymentContext(ctx context.Context, invoiceID string, p paypal.InvoicePaymentDetail) error {
+	m.record("RecordInvoicePayment", invoiceID, p)
+	if m.RecordInvoicePaymentFunc == nil {
+		return notStubbed("RecordInvoicePayment")
+	}
+	return m.RecordInvoicePaymentFunc(ctx, invoiceID, p)
+}
+
+// RecordInvoiceRefund implements paypal.InvoicesAPI
+func (m *Mock) RecordInvoiceRefund(invoiceID string, r paypal.InvoiceRefundDetail) error {
+	return m.RecordInvoiceRefundContext(context.Background(), invoiceID, r)
+}
+
+// RecordInvoiceRefundContext implements paypal.InvoicesAPI
+func (m *Mock) RecordInvoiceRefundContext(ctx context.Context, invoiceID string, r paypal.InvoiceRefundDetail) error {
+	m.record("RecordInvoiceRefund", invoiceID, r)
+	if m.RecordInvoiceRefundFunc == nil {
+		return notStubbed("RecordInvoiceRefund")
+	}
+	return m.RecordInvoiceRefundFunc(ctx, invoiceID, r)
+}
+
+// SearchInvoices implements paypal.InvoicesAPI
+func (m *Mock) SearchInvoices(s paypal.InvoiceSearch) (*paypal.SearchInvoicesResp, error) {
+	return m.SearchInvoicesContext(context.Background(), s)
+}
+
+// SearchInvoicesContext implements paypal.InvoicesAPI
+func (m *Mock) SearchInvoicesContext(ctx context.Context, s paypal.InvoiceSearch) (*paypal.SearchInvoicesResp, error) {
+	m.record("SearchInvoices", s)
+	if m.SearchInvoicesFunc == nil {
+		return nil, notStubbed("SearchInvoices")
+	}
+	return m.SearchInvoicesFunc(ctx, s)
+}
+
+// GenerateInvoiceNumber implements paypal.InvoicesAPI
+func (m *Mock) GenerateInvoiceNumber() (string, error) {
+	return m.GenerateInvoiceNumberContext(context.Background())
+}
+
+// GenerateInvoiceNumberContext implements paypal.InvoicesAPI
+func (m *Mock) GenerateInvoiceNumberContext(ctx context.Context) (string, error) {
+	m.record("GenerateInvoiceNumber")
+	if m.GenerateInvoiceNumberFunc == nil {
+		return "", notStubbed("GenerateInvoiceNumber")
+	}
+	return m.GenerateInvoiceNumberFunc(ctx)
+}
+
+// GetInvoiceQRCode implements paypal.InvoicesAPI
+func (m *Mock) GetInvoiceQRCode(invoiceID string, width, height int) ([]byte, error) {
+	return m.GetInvoiceQRCodeContext(context.Background(), invoiceID, width, height)
+}
+
+// GetInvoiceQRCodeContext implements paypal.InvoicesAPI
+func (m *Mock) GetInvoiceQRCodeContext(ctx context.Context, invoiceID string, width, height int) ([]byte, error) {
+	m.record("GetInvoiceQRCode", invoiceID, width, height)
+	if m.GetInvoiceQRCodeFunc == nil {
+		return nil, notStubbed("GetInvoiceQRCode")
+	}
+	return m.GetInvoiceQRCodeFunc(ctx, invoiceID, width, height)
+}
+
+// WriteInvoiceQRCode implements paypal.InvoicesAPI
+func (m *Mock) WriteInvoiceQRCode(w io.Writer, invoiceID string, width, height int) error {
+	return m.WriteInvoiceQRCodeContext(context.Background(), w, invoiceID, width, height)
+}
+
+// WriteInvoiceQRCodeContext implements paypal.InvoicesAPI
+func (m *Mock) WriteInvoiceQRCodeContext(ctx context.Context, w io.Writer, invoiceID string, width, height int) error {
+	m.record("WriteInvoiceQRCode", w, invoiceID, width, height)
+	if m.WriteInvoiceQRCodeFunc == nil {
+		return notStubbed("WriteInvoiceQRCode")
+	}
+	return m.WriteInvoiceQRCodeFunc(ctx, w, invoiceID, width, height)
+}
+
+// CreateInvoiceTemplate implements paypal.InvoicesAPI
+func (m *Mock) CreateInvoiceTemplate(t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error) {
+	return m.CreateInvoiceTemplateContext(context.Background(), t)
+}
+
+// CreateInvoiceTemplateContext implements paypal.InvoicesAPI
+func (m *Mock) CreateInvoiceTemplateContext(ctx context.Context, t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error) {
+	m.record("CreateInvoiceTemplate", t)
+	if m.CreateInvoiceTemplateFunc == nil {
+		return nil, notStubbed("CreateInvoiceTemplate")
+	}
+	return m.CreateInvoiceTemplateFunc(ctx, t)
+}
+
+// ListInvoiceTemplates implements paypal.InvoicesAPI
+func (m *Mock) ListInvoiceTemplates() (*paypal.ListInvoiceTemplatesResp, error) {
+	return m.ListInvoiceTemplatesContext(context.Background())
+}
+
+// ListInvoiceTemplatesContext implements paypal.InvoicesAPI
+func (m *Mock) ListInvoiceTemplatesContext(ctx context.Context) (*paypal.ListInvoiceTemplatesResp, error) {
+	m.record("ListInvoiceTemplates")
+	if m.ListInvoiceTemplatesFunc == nil {
+		return nil, notStubbed("ListInvoiceTemplates")
+	}
+	return m.ListInvoiceTemplatesFunc(ctx)
+}
+
+// GetInvoiceTemplate implements paypal.InvoicesAPI
+func (m *Mock) GetInvoiceTemplate(templateID string) (*paypal.InvoiceTemplate, error) {
+	return m.GetInvoiceTemplateContext(context.Background(), templateID)
+}
+
+// GetInvoiceTemplateContext implements paypal.InvoicesAPI
+func (m *Mock) GetInvoiceTemplateContext(ctx context.Context, templateID string) (*paypal.InvoiceTemplate, error) {
+	m.record("GetInvoiceTemplate", templateID)
+	if m.GetInvoiceTemplateFunc == nil {
+		return nil, notStubbed("GetInvoiceTemplate")
+	}
+	return m.GetInvoiceTemplateFunc(ctx, templateID)
+}
+
+// UpdateInvoiceTemplate implements paypal.InvoicesAPI
+func (m *Mock) UpdateInvoiceTemplate(templateID string, t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error) {
+	return m.UpdateInvoiceTemplateContext(context.Background(), templateID, t)
+}
+
+// UpdateInvoiceTemplateContext implements paypal.InvoicesAPI
+func (m *Mock) UpdateInvoiceTemplateContext(ctx context.Context, templateID string, t paypal.InvoiceTemplate) (*paypal.InvoiceTemplate, error) {
+	m.record("UpdateInvoiceTemplate", templateID, t)
+	if m.UpdateInvoiceTemplateFunc == nil {
+		return nil, notStubbed("UpdateInvoiceTemplate")
+	}
+	return m.UpdateInvoiceTemplateFunc(ctx, templateID, t)
+}
+
+// DeleteInvoiceTemplate implements paypal.InvoicesAPI
+func (m *Mock) DeleteInvoiceTemplate(templateID string) error {
+	return m.DeleteInvoiceTemplateContext(context.Background(), templateID)
+}
+
+// DeleteInvoiceTemplateContext implements paypal.InvoicesAPI
+func (m *Mock) DeleteInvoiceTemplateContext(ctx context.Context, templateID string) error {
+	m.record("DeleteInvoiceTemplate", templateID)
+	if m.DeleteInvoiceTemplateFunc == nil {
+		return notStubbed("DeleteInvoiceTemplate")
+	}
+	return m.DeleteInvoiceTemplateFunc(ctx, templateID)
+}
+
+// CreateWebProfile implements paypal.WebProfilesAPI
+func (m *Mock) CreateWebProfile(p paypal.WebProfile) (*paypal.WebProfile, error) {
+	return m.CreateWebProfileContext(context.Background(), p)
+}
+
+// CreateWebProfileContext implements paypal.WebProfilesAPI
+func (m *Mock) CreateWebProfileContext(ctx context.Context, p paypal.WebProfile) (*paypal.WebProfile, error) {
+	m.record("CreateWebProfile", p)
+	if m.CreateWebProfileFunc == nil {
+		return nil, notStubbed("CreateWebProfile")
+	}
+	return m.CreateWebProfileFunc(ctx, p)
+}
+
+// ListWebProfiles implements paypal.WebProfilesAPI
+func (m *Mock) ListWebProfiles() ([]paypal.WebProfile, error) {
+	return m.ListWebProfilesContext(context.Background())
+}
+
+// ListWebProfilesContext implements paypal.WebProfilesAPI
+func (m *Mock) ListWebProfilesContext(ctx context.Context) ([]paypal.WebProfile, error) {
+	m.record("ListWebProfiles")
+	if m.ListWebProfilesFunc == nil {
+		return nil, notStubbed("ListWebProfiles")
+	}
+	return m.ListWebProfilesFunc(ctx)
+}
+
+// GetWebProfile implements paypal.WebProfilesAPI
+func (m *Mock) GetWebProfile(profileID string) (*paypal.WebProfile, error) {
+	return m.GetWebProfileContext(context.Background(), profileID)
+}
+
+// GetWebProfileContext implements paypal.WebProfilesAPI
+func (m *Mock) GetWebProfileContext(ctx context.Context, profileID string) (*paypal.WebProfile, error) {
+	m.record("GetWebProfile", profileID)
+	if m.GetWebProfileFunc == nil {
+		return nil, notStubbed("GetWebProfile")
+	}
+	return m.GetWebProfileFunc(ctx, profileID)
+}
+
+// UpdateWebProfile implements paypal.WebProfilesAPI
+func (m *Mock) UpdateWebProfile(profileID string, p paypal.WebProfile) error {
+	return m.UpdateWebProfileContext(context.Background(), profileID, p)
+}
+
+// UpdateWebProfileContext implements paypal.WebProfilesAPI
+func (m *Mock) UpdateWebProfileContext(ctx context.Context, profileID string, p paypal.WebProfile) error {
+	m.record("UpdateWebProfile", profileID, p)
+	if m.UpdateWebProfileFunc == nil {
+		return notStubbed("UpdateWebProfile")
+	}
+	return m.UpdateWebProfileFunc(ctx, profileID, p)
+}
+
+// PatchWebProfile implements paypal.WebProfilesAPI
+func (m *Mock) PatchWebProfile(profileID string, patches []paypal.Patch) error {
+	return m.PatchWebProfileContext(context.Background(), profileID, patches)
+}
+
+// PatchWebProfileContext implements paypal.WebProfilesAPI
+func (m *Mock) PatchWebProfileContext(ctx context.Context, profileID string, patches []paypal.Patch) error {
+	m.record("PatchWebProfile", profileID, patches)
+	if m.PatchWebProfileFunc == nil {
+		return notStubbed("PatchWebProfile")
+	}
+	return m.PatchWebProfileFunc(ctx, profileID, patches)
+}
+
+// DeleteWebProfile implements paypal.WebProfilesAPI
+func (m *Mock) DeleteWebProfile(profileID string) error {
+	return m.DeleteWebProfileContext(context.Background(), profileID)
+}
+
+// DeleteWebProfileContext implements paypal.WebProfilesAPI
+func (m *Mock) DeleteWebProfileContext(ctx context.Context, profileID string) error {
+	m.record("DeleteWebProfile", profileID)
+	if m.DeleteWebProfileFunc == nil {
+		return notStubbed("DeleteWebProfile")
+	}
+	return m.DeleteWebProfileFunc(ctx, profileID)
+}
+
+// CreatePayout implements paypal.PayoutsAPI
+func (m *Mock) CreatePayout(p paypal.Payout, syncMode bool) (*paypal.PayoutBatch, error) {
+	return m.CreatePayoutContext(context.Background(), p, syncMode)
+}
+
+// CreatePayoutContext implements paypal.PayoutsAPI
+func (m *Mock) CreatePayoutContext(ctx context.Context, p paypal.Payout, syncMode bool) (*paypal.PayoutBatch, error) {
+	m.record("CreatePayout", p, syncMode)
+	if m.CreatePayoutFunc == nil {
+		return nil, notStubbed("CreatePayout")
+	}
+	return m.CreatePayoutFunc(ctx, p, syncMode)
+}
+
+// GetPayoutBatch implements paypal.PayoutsAPI
+func (m *Mock) GetPayoutBatch(payoutBatchID string, filter map[string]string) (*paypal.PayoutBatch, error) {
+	return m.GetPayoutBatchContext(context.Background(), payoutBatchID, filter)
+}
+
+// GetPayoutBatchContext implements paypal.PayoutsAPI
+func (m *Mock) GetPayoutBatchContext(ctx context.Context, payoutBatchID string, filter map[string]string) (*paypal.PayoutBatch, error) {
+	m.record("GetPayoutBatch", payoutBatchID, filter)
+	if m.GetPayoutBatchFunc == nil {
+		return nil, notStubbed("GetPayoutBatch")
+	}
+	return m.GetPayoutBatchFunc(ctx, payoutBatchID, filter)
+}
+
+// GetPayoutItem implements paypal.PayoutsAPI
+func (m *Mock) GetPayoutItem(payoutItemID string) (*paypal.PayoutItemDetail, error) {
+	return m.GetPayoutItemContext(context.Background(), payoutItemID)
+}
+
+// GetPayoutItemContext implements paypal.PayoutsAPI
+func (m *Mock) GetPayoutItemContext(ctx context.Context, payoutItemID string) (*paypal.PayoutItemDetail, error) {
+	m.record("GetPayoutItem", payoutItemID)
+	if m.GetPayoutItemFunc == nil {
+		return nil, notStubbed("GetPayoutItem")
+	}
+	return m.GetPayoutItemFunc(ctx, payoutItemID)
+}
+
+// CancelPayoutItem implements paypal.PayoutsAPI
+func (m *Mock) CancelPayoutItem(payoutItemID string) (*paypal.PayoutItemDetail, error) {
+	return m.CancelPayoutItemContext(context.Background(), payoutItemID)
+}
+
+// CancelPayoutItemContext implements paypal.PayoutsAPI
+func (m *Mock) CancelPayoutItemContext(ctx context.Context, payoutItemID string) (*paypal.PayoutItemDetail, error) {
+	m.record("CancelPayoutItem", payoutItemID)
+	if m.CancelPayoutItemFunc == nil {
+		return nil, notStubbed("CancelPayoutItem")
+	}
+	return m.CancelPayoutItemFunc(ctx, payoutItemID)
+}
@@ -0,0 +1,45 @@
+package paypaltest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/leebenson/paypal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// refundOrder stands for application code depending on an interface rather
+// than on *paypal.Client
+func refundOrder(sales paypal.SalesAPI, saleID string) (paypal.RefundState, error) {
+	refund, err := sales.RefundSale(saleID, nil)
+	if err != nil {
+		return "", err
+	}
+	return refund.State, nil
+}
+
+func TestMock(t *testing.T) {
+	Convey("With a mock", t, func() {
+		m := &Mock{}
+
+		Convey("Stubbed methods should return canned values and record calls", func() {
+			m.RefundSaleFunc = func(ctx context.Context, saleID string, a *paypal.Amount) (*paypal.Refund, error) {
+				return &paypal.Refund{ID: "R1", State: paypal.RefundStateCompleted}, nil
+			}
+
+			state, err := refundOrder(m, "S1")
+
+			So(err, ShouldBeNil)
+			So(state, ShouldEqual, paypal.RefundStateCompleted)
+			So(m.CallsTo("RefundSale"), ShouldResemble, []Call{{Method: "RefundSale", Args: []interface{}{"S1", (*paypal.Amount)(nil)}}})
+		})
+
+		Convey("Methods that are not stubbed should fail", func() {
+			_, err := m.GetCapture("C1")
+
+			So(errors.Is(err, ErrNotStubbed), ShouldBeTrue)
+			So(m.Calls(), ShouldHaveLength, 1)
+		})
+	})
+}
@@ -0,0 +1,202 @@
+package paypaltest
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// certPath is the path of the certificate the server signs webhook
+// notifications with
+const certPath = "/certs/CERT-360caa42-fca2a594-paypaltest"
+
+type (
+	// signer holds the key webhook notifications are signed with, and its
+	// certificate issued by a root generated for the server
+	signer struct {
+		key     *rsa.PrivateKey
+		certPEM []byte
+		roots   *x509.CertPool
+	}
+
+	// delivery is a webhook notification to send
+	delivery struct {
+		url       string
+		webhookID string
+	}
+)
+
+// CertPool returns a pool holding the root of the certificate the server
+// signs webhook notifications with, to verify them with a
+// paypal.OfflineVerifier. As the default FetchCert only fetches
+// certificates from paypal.com, the verifier must use the FetchCert of the
+// server:
+//
+//	verifier := &paypal.OfflineVerifier{
+//		Roots:     srv.CertPool(),
+//		FetchCert: srv.FetchCert,
+//	}
+func (s *Server) CertPool() *x509.CertPool {
+	return s.signer().roots
+}
+
+// FetchCert returns the PEM encoded certificate at certURL, which must be
+// the certificate URL of a notification sent by the server
+func (s *Server) FetchCert(ctx context.Context, certURL string) ([]byte, error) {
+	if certURL != s.URL+certPath {
+		return nil, fmt.Errorf("paypaltest: no certificate at %s", certURL)
+	}
+
+	return s.signer().certPEM, nil
+}
+
+// signer returns the signer of the server, generating it on first use
+func (s *Server) signer() *signer {
+	s.signerOnce.Do(func() {
+		var err error
+		if s.sign, err = newSigner(); err != nil {
+			panic(fmt.Sprintf("paypaltest: generating webhook certificates: %v", err))
+		}
+	})
+
+	return s.sign
+}
+
+func newSigner() (*signer, error) {
+	rootKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, err
+	}
+
+	notBefore := time.Now().Add(-time.Hour)
+	rootTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "paypaltest root"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.AddDate(1, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	rootDER, err := x509.CreateCertificate(rand.Reader, rootTemplate, rootTemplate, &rootKey.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+	root, err := x509.ParseCertificate(rootDER)
+	if err != nil {
+		return nil, err
+	}
+
+	certDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "messageverificationcerts.sandbox.paypal.com"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.AddDate(1, 0, 0),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}, root, &key.PublicKey, rootKey)
+	if err != nil {
+		return nil, err
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+
+	return &signer{
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		roots:   roots,
+	}, nil
+}
+
+// signed returns the hash of what is signed for a notification, as
+// described by the verify-webhook-signature API
+func signed(transmissionID, transmissionTime, webhookID string, body []byte) []byte {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%s|%d", transmissionID, transmissionTime, webhookID, crc32.ChecksumIEEE(body))))
+	return sum[:]
+}
+
+func (s *Server) handleCert(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/x-pem-file")
+	w.Write(s.signer().certPEM)
+}
+
+// deliver sends e to each of deliveries in the background, as Paypal sends
+// webhook notifications. Close waits for the deliveries in progress
+func (s *Server) deliver(e paypal.WebhookEvent, deliveries []delivery) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return
+	}
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		client := &http.Client{Timeout: 10 * time.Second}
+		for _, d := range deliveries {
+			s.mu.Lock()
+			transmissionID := s.newID("TRANSMISSION-")
+			s.mu.Unlock()
+			transmissionTime := time.Now().UTC().Format(time.RFC3339)
+
+			sig, err := rsa.SignPKCS1v15(rand.Reader, s.signer().key, crypto.SHA256,
+				signed(transmissionID, transmissionTime, d.webhookID, body))
+			if err != nil {
+				continue
+			}
+
+			req, err := http.NewRequest("POST", d.url, bytes.NewReader(body))
+			if err != nil {
+				continue
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(paypal.TransmissionIDHeader, transmissionID)
+			req.Header.Set(paypal.TransmissionTimeHeader, transmissionTime)
+			req.Header.Set(paypal.TransmissionSigHeader, base64.StdEncoding.EncodeToString(sig))
+			req.Header.Set(paypal.CertURLHeader, s.URL+certPath)
+			req.Header.Set(paypal.AuthAlgoHeader, "SHA256withRSA")
+
+			if resp, err := client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}()
+}
+
+func (s *Server) handleVerifyWebhookSignature(w http.ResponseWriter, r *http.Request) {
+	var req paypal.VerifyWebhookSignatureReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	status := paypal.VerificationStatusFailure
+	sig, err := base64.StdEncoding.DecodeString(req.TransmissionSig)
+	if err == nil && req.AuthAlgo == "SHA256withRSA" && req.CertURL == s.URL+certPath {
+		hash := signed(req.TransmissionID, req.TransmissionTime, req.WebhookID, req.WebhookEvent)
+		if rsa.VerifyPKCS1v15(&s.signer().key.PublicKey, crypto.SHA256, hash, sig) == nil {
+			status = paypal.VerificationStatusSuccess
+		}
+	}
+
+	writeJSON(w, http.StatusOK, paypal.VerifyWebhookSignatureResp{VerificationStatus: status})
+}
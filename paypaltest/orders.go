@@ -0,0 +1,181 @@
+package paypaltest
+
+import (
+	"net/http"
+
+	"github.com/leebenson/paypal"
+)
+
+// newOrder creates the order of a transaction of payment p. Must be called
+// with s.mu held
+func (s *Server) newOrder(p *payment, amount *paypal.Amount) *paypal.Order {
+	order := &paypal.Order{
+		ID:                    s.newID("O-"),
+		Amount:                amount,
+		CreateTime:            now(),
+		UpdateTime:            now(),
+		State:                 paypal.OrderStatePending,
+		PendingReason:         paypal.PendingReasonOrder,
+		ParentPayment:         p.ID,
+		ProtectionEligibility: string(paypal.ProtectionEligibilityEligible),
+	}
+	order.Links = []paypal.Links{
+		s.link("self", "GET", "/payments/orders/"+order.ID),
+		s.link("authorization", "POST", "/payments/orders/"+order.ID+"/authorize"),
+		s.link("capture", "POST", "/payments/orders/"+order.ID+"/capture"),
+		s.link("void", "POST", "/payments/orders/"+order.ID+"/do-void"),
+		s.link("parent_payment", "GET", "/payments/payment/"+p.ID),
+	}
+	s.orders[order.ID] = order
+
+	return order
+}
+
+func (s *Server) handleGetOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+// pendingOrder returns the order the request is for, writing an error and
+// returning nil if it is unknown or no longer pending. Must be called with
+// s.mu held
+func (s *Server) pendingOrder(w http.ResponseWriter, r *http.Request) *paypal.Order {
+	order, ok := s.orders[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return nil
+	}
+	if order.State != paypal.OrderStatePending {
+		writeError(w, http.StatusBadRequest, "ORDER_ALREADY_COMPLETED", "Order has already been completed")
+		return nil
+	}
+
+	return order
+}
+
+func (s *Server) handleAuthorizeOrder(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := s.pendingOrder(w, r)
+	if order == nil {
+		return
+	}
+
+	total, _ := parseCents(order.Amount.Total)
+	amount, ok := s.amount(w, order.Amount, req.Amount, total-s.settled("order/"+order.ID))
+	if !ok {
+		return
+	}
+
+	p := s.payments[order.ParentPayment]
+	auth := s.newAuthorization(p, amount)
+	s.addRelatedResource(order.ParentPayment, paypal.Resource{Authorization: auth}, func(r paypal.Resource) bool {
+		return r.Order == order
+	})
+
+	writeJSON(w, http.StatusCreated, auth)
+}
+
+func (s *Server) handleCaptureOrder(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := s.pendingOrder(w, r)
+	if order == nil {
+		return
+	}
+
+	total, _ := parseCents(order.Amount.Total)
+	captured := s.settled("order/" + order.ID)
+	amount, ok := s.amount(w, order.Amount, req.Amount, total-captured)
+	if !ok {
+		return
+	}
+
+	capture := s.newCapture(order.ParentPayment, amount, req.IsFinalCapture)
+	cents, _ := parseCents(amount.Total)
+	s.settle("order/"+order.ID, cents)
+
+	order.UpdateTime = now()
+	if req.IsFinalCapture || captured+cents == total {
+		order.State = paypal.OrderStateCompleted
+	}
+	s.addRelatedResource(order.ParentPayment, paypal.Resource{Capture: capture}, func(r paypal.Resource) bool {
+		return r.Order == order
+	})
+
+	writeJSON(w, http.StatusOK, capture)
+}
+
+func (s *Server) handleVoidOrder(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order := s.pendingOrder(w, r)
+	if order == nil {
+		return
+	}
+
+	order.State = paypal.OrderStateVoided
+	order.UpdateTime = now()
+
+	writeJSON(w, http.StatusOK, order)
+}
+
+func (s *Server) handleRefundOrder(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	captured := s.settled("order/" + order.ID)
+	if captured == 0 || order.State == paypal.OrderStateRefunded || order.State == paypal.OrderStateVoided {
+		writeError(w, http.StatusBadRequest, "TRANSACTION_REFUSED", "The order has no captured amount to refund")
+		return
+	}
+
+	paid := &paypal.Amount{Currency: order.Amount.Currency, Total: formatCents(captured)}
+	refund, status, full := s.refund(w, paid, req.Amount, "order-refund/"+order.ID)
+	if refund == nil {
+		return
+	}
+	refund.ParentPayment = order.ParentPayment
+
+	order.UpdateTime = now()
+	order.State = paypal.OrderStatePartiallyRefunded
+	if full {
+		order.State = paypal.OrderStateRefunded
+	}
+	s.addRelatedResource(order.ParentPayment, paypal.Resource{Refund: refund}, func(r paypal.Resource) bool {
+		return r.Order == order
+	})
+
+	writeJSON(w, status, refund)
+}
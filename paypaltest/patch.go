@@ -0,0 +1,134 @@
+package paypaltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/leebenson/paypal"
+)
+
+// applyPatch applies a JSON Patch to the resource src, through its JSON
+// representation, and stores the result in dst. As Paypal does, replacing
+// the root path merges the fields of the value into the resource rather
+// than replacing it
+func applyPatch(dst, src interface{}, patches []paypal.Patch) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	for _, p := range patches {
+		value, err := patchValue(p.Value)
+		if err != nil {
+			return err
+		}
+		if doc, err = patchDocument(doc, p, value); err != nil {
+			return err
+		}
+	}
+
+	if data, err = json.Marshal(doc); err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
+
+// patchValue returns value as decoded from JSON, so that it can be stored
+// in a document
+func patchValue(value interface{}) (interface{}, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(data, &v)
+
+	return v, err
+}
+
+func patchDocument(doc interface{}, p paypal.Patch, value interface{}) (interface{}, error) {
+	if p.Path == "/" || p.Path == "" {
+		if p.Operation != "replace" && p.Operation != "add" {
+			return nil, fmt.Errorf("unsupported operation %q on %q", p.Operation, p.Path)
+		}
+		fields, ok := value.(map[string]interface{})
+		root, isObject := doc.(map[string]interface{})
+		if !ok || !isObject {
+			return nil, fmt.Errorf("invalid value for %q", p.Path)
+		}
+		for k, v := range fields {
+			root[k] = v
+		}
+		return root, nil
+	}
+
+	tokens := strings.Split(strings.TrimPrefix(p.Path, "/"), "/")
+	parent := doc
+	for _, t := range tokens[:len(tokens)-1] {
+		next, ok := child(parent, t)
+		if !ok {
+			return nil, fmt.Errorf("path %q not found", p.Path)
+		}
+		parent = next
+	}
+	last := tokens[len(tokens)-1]
+
+	switch container := parent.(type) {
+	case map[string]interface{}:
+		_, exists := container[last]
+		switch p.Operation {
+		case "add":
+			container[last] = value
+		case "replace":
+			if !exists {
+				return nil, fmt.Errorf("path %q not found", p.Path)
+			}
+			container[last] = value
+		case "remove":
+			if !exists {
+				return nil, fmt.Errorf("path %q not found", p.Path)
+			}
+			delete(container, last)
+		default:
+			return nil, fmt.Errorf("unsupported operation %q", p.Operation)
+		}
+	case []interface{}:
+		i, err := strconv.Atoi(last)
+		if err != nil || i < 0 || i >= len(container) {
+			return nil, fmt.Errorf("path %q not found", p.Path)
+		}
+		if p.Operation != "replace" {
+			return nil, fmt.Errorf("unsupported operation %q on %q", p.Operation, p.Path)
+		}
+		container[i] = value
+	default:
+		return nil, fmt.Errorf("path %q not found", p.Path)
+	}
+
+	return doc, nil
+}
+
+// child returns the member of an object or array named by a JSON Pointer
+// token
+func child(v interface{}, token string) (interface{}, bool) {
+	switch container := v.(type) {
+	case map[string]interface{}:
+		c, ok := container[token]
+		return c, ok
+	case []interface{}:
+		i, err := strconv.Atoi(token)
+		if err != nil || i < 0 || i >= len(container) {
+			return nil, false
+		}
+		return container[i], true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,733 @@
+package paypaltest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// payment is a payment as stored by the server
+type payment struct {
+	paypal.Payment
+	Links []paypal.Links `json:"links,omitempty"`
+
+	// approvedBy is the ID of the payer who approved the payment
+	approvedBy string
+}
+
+// amountReq maps to the bodies of the refund, capture and reauthorize calls
+type amountReq struct {
+	Amount         *paypal.Amount `json:"amount"`
+	IsFinalCapture bool           `json:"is_final_capture"`
+}
+
+func (s *Server) routes() {
+	s.route("POST /payments/payment", s.handleCreatePayment)
+	s.route("GET /payments/payment", s.handleListPayments)
+	s.route("GET /payments/payment/{$}", s.handleListPayments)
+	s.route("GET /payments/payment/{id}", s.handleGetPayment)
+	s.route("POST /payments/payment/{id}/execute", s.handleExecutePayment)
+
+	s.route("GET /payments/sale/{id}", s.handleGetSale)
+	s.route("POST /payments/sale/{id}/refund", s.handleRefundSale)
+
+	s.route("GET /payments/authorization/{id}", s.handleGetAuthorization)
+	s.route("POST /payments/authorization/{id}/capture", s.handleCaptureAuthorization)
+	s.route("POST /payments/authorization/{id}/void", s.handleVoidAuthorization)
+	s.route("POST /payments/authorization/{id}/reauthorize", s.handleReauthorizeAuthorization)
+
+	s.route("GET /payments/orders/{id}", s.handleGetOrder)
+	s.route("POST /payments/orders/{id}/authorize", s.handleAuthorizeOrder)
+	s.route("POST /payments/orders/{id}/capture", s.handleCaptureOrder)
+	s.route("POST /payments/orders/{id}/do-void", s.handleVoidOrder)
+	s.route("POST /payments/orders/{id}/refund", s.handleRefundOrder)
+
+	s.route("GET /payments/capture/{id}", s.handleGetCapture)
+	s.route("POST /payments/capture/{id}/refund", s.handleRefundCapture)
+
+	s.route("GET /payments/refund/{id}", s.handleGetRefund)
+
+	s.route("POST /vault/credit-cards", s.handleStoreCreditCard)
+	s.route("GET /vault/credit-cards", s.handleListCreditCards)
+	s.route("GET /vault/credit-cards/{id}", s.handleGetCreditCard)
+	s.route("PATCH /vault/credit-cards/{id}", s.handlePatchCreditCard)
+	s.route("DELETE /vault/credit-cards/{id}", s.handleDeleteCreditCard)
+
+	s.route("POST /payments/billing-plans", s.handleCreatePlan)
+	s.route("GET /payments/billing-plans", s.handleListPlans)
+	s.route("GET /payments/billing-plans/{id}", s.handleGetPlan)
+	s.route("PATCH /payments/billing-plans/{id}", s.handlePatchPlan)
+
+	s.route("POST /payments/billing-agreements", s.handleCreateAgreement)
+	s.route("POST /payments/billing-agreements/{token}/agreement-execute", s.handleExecuteAgreement)
+	s.route("GET /payments/billing-agreements/{id}", s.handleGetAgreement)
+	s.route("PATCH /payments/billing-agreements/{id}", s.handlePatchAgreement)
+	s.route("POST /payments/billing-agreements/{id}/suspend",
+		s.agreementTransition("Suspended", paypal.AgreementStateSuspended, paypal.AgreementStateActive))
+	s.route("POST /payments/billing-agreements/{id}/re-activate",
+		s.agreementTransition("Reactivated", paypal.AgreementStateActive, paypal.AgreementStateSuspended))
+	s.route("POST /payments/billing-agreements/{id}/cancel",
+		s.agreementTransition("Canceled", paypal.AgreementStateCancelled, paypal.AgreementStateActive, paypal.AgreementStateSuspended))
+	s.route("POST /payments/billing-agreements/{id}/set-balance", s.handleSetAgreementBalance)
+	s.route("POST /payments/billing-agreements/{id}/bill-balance", s.handleBillAgreementBalance)
+	s.route("GET /payments/billing-agreements/{id}/transactions", s.handleListAgreementTransactions)
+
+	s.route("POST /notifications/webhooks", s.handleCreateWebhook)
+	s.route("GET /notifications/webhooks", s.handleListWebhooks)
+	s.route("GET /notifications/webhooks/{id}", s.handleGetWebhook)
+	s.route("PATCH /notifications/webhooks/{id}", s.handlePatchWebhook)
+	s.route("DELETE /notifications/webhooks/{id}", s.handleDeleteWebhook)
+	s.route("GET /notifications/webhooks/{id}/event-types", s.handleListWebhookEventTypes)
+	s.route("GET /notifications/webhooks-event-types", s.handleListAvailableEventTypes)
+	s.route("GET /notifications/webhooks-events", s.handleListWebhookEvents)
+	s.route("GET /notifications/webhooks-events/{id}", s.handleGetWebhookEvent)
+	s.route("POST /notifications/webhooks-events/{id}/resend", s.handleResendWebhookEvent)
+	s.route("POST /notifications/simulate-event", s.handleSimulateWebhookEvent)
+	s.route("POST /notifications/verify-webhook-signature", s.handleVerifyWebhookSignature)
+
+	s.route("POST /invoicing/invoices", s.handleCreateInvoice)
+	s.route("POST /invoicing/invoices/next-invoice-number", s.handleNextInvoiceNumber)
+	s.route("GET /invoicing/invoices/{id}", s.handleGetInvoice)
+	s.route("PUT /invoicing/invoices/{id}", s.handleUpdateInvoice)
+	s.route("DELETE /invoicing/invoices/{id}", s.handleDeleteInvoice)
+	s.route("POST /invoicing/invoices/{id}/send", s.handleSendInvoice)
+	s.route("POST /invoicing/invoices/{id}/remind", s.handleRemindInvoice)
+	s.route("POST /invoicing/invoices/{id}/cancel", s.handleCancelInvoice)
+	s.route("POST /invoicing/invoices/{id}/record-payment", s.handleRecordInvoicePayment)
+	s.route("POST /invoicing/invoices/{id}/record-refund", s.handleRecordInvoiceRefund)
+	s.route("GET /invoicing/invoices/{id}/qr-code", s.handleInvoiceQRCode)
+	s.route("POST /invoicing/search", s.handleSearchInvoices)
+	s.route("POST /invoicing/templates", s.handleCreateInvoiceTemplate)
+	s.route("GET /invoicing/templates", s.handleListInvoiceTemplates)
+	s.route("GET /invoicing/templates/{id}", s.handleGetInvoiceTemplate)
+	s.route("PUT /invoicing/templates/{id}", s.handleUpdateInvoiceTemplate)
+	s.route("DELETE /invoicing/templates/{id}", s.handleDeleteInvoiceTemplate)
+
+	s.route("POST /payment-experience/web-profiles", s.handleCreateWebProfile)
+	s.route("GET /payment-experience/web-profiles", s.handleListWebProfiles)
+	s.route("GET /payment-experience/web-profiles/{id}", s.handleGetWebProfile)
+	s.route("PUT /payment-experience/web-profiles/{id}", s.handleUpdateWebProfile)
+	s.route("PATCH /payment-experience/web-profiles/{id}", s.handlePatchWebProfile)
+	s.route("DELETE /payment-experience/web-profiles/{id}", s.handleDeleteWebProfile)
+
+	s.route("POST /payments/payouts", s.handleCreatePayout)
+	s.route("GET /payments/payouts/{id}", s.handleGetPayoutBatch)
+	s.route("GET /payments/payouts-item/{id}", s.handleGetPayoutItem)
+	s.route("POST /payments/payouts-item/{id}/cancel", s.handleCancelPayoutItem)
+}
+
+// ApprovePayment simulates the payer approving a payment made with
+// PaymentMethodPaypal on the Paypal website, after which it can be
+// executed with payerID
+func (s *Server) ApprovePayment(paymentID, payerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[paymentID]
+	if !ok {
+		return fmt.Errorf("paypaltest: no payment %q", paymentID)
+	}
+	if p.State != paypal.PaymentStateCreated {
+		return fmt.Errorf("paypaltest: payment %q is %s", paymentID, p.State)
+	}
+	p.approvedBy = payerID
+
+	return nil
+}
+
+func (s *Server) handleCreatePayment(w http.ResponseWriter, r *http.Request) {
+	p := &payment{}
+	if !decode(w, r, &p.Payment) {
+		return
+	}
+	if details := validatePayment(&p.Payment); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.webProfiles[p.ExperienceProfileID]; p.ExperienceProfileID != "" && !ok {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "experience_profile_id", Issue: "Value is invalid"})
+		return
+	}
+
+	for _, fi := range p.Payer.FundingInstruments {
+		if fi.CreditCard != nil {
+			fi.CreditCard.Number = maskNumber(fi.CreditCard.Number)
+			fi.CreditCard.CVV2 = ""
+		}
+	}
+
+	p.ID = s.newID("PAY-")
+	p.CreateTime = now()
+	p.UpdateTime = p.CreateTime
+	p.Links = []paypal.Links{s.link("self", "GET", "/payments/payment/"+p.ID)}
+
+	if p.Payer.PaymentMethod == paypal.PaymentMethodCreditCard {
+		s.completePayment(p)
+	} else {
+		p.State = paypal.PaymentStateCreated
+		p.Links = append(p.Links,
+			paypal.Links{Href: s.URL + "/checkoutnow?token=EC-" + p.ID, Rel: "approval_url", Method: "REDIRECT"},
+			s.link("execute", "POST", "/payments/payment/"+p.ID+"/execute"))
+	}
+
+	s.payments[p.ID] = p
+	s.paymentOrder = append(s.paymentOrder, p.ID)
+
+	writeJSON(w, http.StatusCreated, p)
+}
+
+// validatePayment returns the issues that make p invalid, if any
+func validatePayment(p *paypal.Payment) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+
+	switch p.Intent {
+	case paypal.PaymentIntentSale, paypal.PaymentIntentAuthorize, paypal.PaymentIntentOrder:
+	default:
+		details = append(details, paypal.ErrorDetail{Field: "intent", Issue: "Value is invalid"})
+	}
+
+	switch {
+	case p.Payer == nil:
+		details = append(details, paypal.ErrorDetail{Field: "payer", Issue: "Required field missing"})
+	case p.Payer.PaymentMethod == paypal.PaymentMethodCreditCard:
+		if p.Intent == paypal.PaymentIntentOrder {
+			details = append(details, paypal.ErrorDetail{Field: "intent", Issue: "Order intent is only supported for paypal payments"})
+		}
+		if len(p.Payer.FundingInstruments) == 0 {
+			details = append(details, paypal.ErrorDetail{Field: "payer.funding_instruments", Issue: "Required field missing"})
+		}
+		for i, fi := range p.Payer.FundingInstruments {
+			if fi.CreditCard != nil && !luhn(fi.CreditCard.Number) {
+				details = append(details, paypal.ErrorDetail{
+					Field: fmt.Sprintf("payer.funding_instruments[%d].credit_card.number", i),
+					Issue: "Value is invalid",
+				})
+			}
+		}
+	case p.Payer.PaymentMethod == paypal.PaymentMethodPaypal:
+		if p.RedirectURLs == nil || p.RedirectURLs.ReturnURL == "" || p.RedirectURLs.CancelURL == "" {
+			details = append(details, paypal.ErrorDetail{Field: "redirect_urls", Issue: "Required field missing"})
+		}
+	default:
+		details = append(details, paypal.ErrorDetail{Field: "payer.payment_method", Issue: "Value is invalid"})
+	}
+
+	if len(p.Transactions) == 0 {
+		details = append(details, paypal.ErrorDetail{Field: "transactions", Issue: "Required field missing"})
+	}
+	for i, t := range p.Transactions {
+		if t.Amount == nil || t.Amount.Currency == "" {
+			details = append(details, paypal.ErrorDetail{Field: fmt.Sprintf("transactions[%d].amount.currency", i), Issue: "Required field missing"})
+		}
+		if t.Amount == nil {
+			continue
+		}
+		if _, err := parseCents(t.Amount.Total); err != nil {
+			details = append(details, paypal.ErrorDetail{Field: fmt.Sprintf("transactions[%d].amount.total", i), Issue: "Value is invalid"})
+		}
+	}
+
+	return details
+}
+
+// luhn reports whether number passes the Luhn checksum of card numbers
+func luhn(number string) bool {
+	if len(number) < 12 {
+		return false
+	}
+
+	sum := 0
+	for i := range number {
+		d := int(number[len(number)-1-i] - '0')
+		if d < 0 || d > 9 {
+			return false
+		}
+		if i%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+// maskNumber masks a card number the way the API does in its responses
+func maskNumber(number string) string {
+	return strings.Repeat("x", len(number)-4) + number[len(number)-4:]
+}
+
+// completePayment approves p and creates the sale or authorization of each
+// of its transactions. Must be called with s.mu held
+func (s *Server) completePayment(p *payment) {
+	p.State = paypal.PaymentStateApproved
+	p.UpdateTime = now()
+
+	for i := range p.Transactions {
+		t := &p.Transactions[i]
+		amount := *t.Amount
+
+		switch p.Intent {
+		case paypal.PaymentIntentSale:
+			sale := &paypal.Sale{
+				ID:                    s.newID(""),
+				Amount:                &amount,
+				Description:           t.Description,
+				CreateTime:            now(),
+				UpdateTime:            now(),
+				State:                 paypal.SaleStateCompleted,
+				ParentPayment:         p.ID,
+				PaymentMode:           paypal.SalePaymentModeInstantTransfer,
+				ProtectionEligibility: paypal.ProtectionEligibilityEligible,
+			}
+			sale.Links = []paypal.Links{
+				s.link("self", "GET", "/payments/sale/"+sale.ID),
+				s.link("refund", "POST", "/payments/sale/"+sale.ID+"/refund"),
+				s.link("parent_payment", "GET", "/payments/payment/"+p.ID),
+			}
+			s.sales[sale.ID] = sale
+			t.RelatedResources = append(t.RelatedResources, paypal.Resource{Sale: sale})
+
+		case paypal.PaymentIntentAuthorize:
+			auth := s.newAuthorization(p, &amount)
+			t.RelatedResources = append(t.RelatedResources, paypal.Resource{Authorization: auth})
+
+		case paypal.PaymentIntentOrder:
+			order := s.newOrder(p, &amount)
+			t.RelatedResources = append(t.RelatedResources, paypal.Resource{Order: order})
+		}
+	}
+}
+
+// newAuthorization creates an authorization of amount for payment p. Must
+// be called with s.mu held
+func (s *Server) newAuthorization(p *payment, amount *paypal.Amount) *paypal.Authorization {
+	validUntil := time.Now().UTC().Add(29 * 24 * time.Hour).Truncate(time.Second)
+	auth := &paypal.Authorization{
+		ID:            s.newID(""),
+		Amount:        amount,
+		CreateTime:    now(),
+		UpdateTime:    now(),
+		State:         paypal.AuthorizationStateAuthorized,
+		ParentPayment: p.ID,
+		ValidUntil:    &validUntil,
+	}
+	auth.Links = []paypal.Links{
+		s.link("self", "GET", "/payments/authorization/"+auth.ID),
+		s.link("capture", "POST", "/payments/authorization/"+auth.ID+"/capture"),
+		s.link("void", "POST", "/payments/authorization/"+auth.ID+"/void"),
+		s.link("parent_payment", "GET", "/payments/payment/"+p.ID),
+	}
+	s.authorizations[auth.ID] = auth
+
+	return auth
+}
+
+// newCapture creates a completed capture of amount for the payment
+// paymentID. Must be called with s.mu held
+func (s *Server) newCapture(paymentID string, amount *paypal.Amount, isFinal bool) *paypal.Capture {
+	capture := &paypal.Capture{
+		ID:             s.newID(""),
+		Amount:         amount,
+		IsFinalCapture: isFinal,
+		CreateTime:     now(),
+		UpdateTime:     now(),
+		State:          paypal.CaptureStateCompleted,
+		ParentPayment:  paymentID,
+	}
+	capture.Links = []paypal.Links{
+		s.link("self", "GET", "/payments/capture/"+capture.ID),
+		s.link("refund", "POST", "/payments/capture/"+capture.ID+"/refund"),
+		s.link("parent_payment", "GET", "/payments/payment/"+paymentID),
+	}
+	s.captures[capture.ID] = capture
+
+	return capture
+}
+
+// addRelatedResource appends res to the transaction of the payment paymentID
+// that holds the resource matching match. Must be called with s.mu held
+func (s *Server) addRelatedResource(paymentID string, res paypal.Resource, match func(paypal.Resource) bool) {
+	p, ok := s.payments[paymentID]
+	if !ok {
+		return
+	}
+
+	for i := range p.Transactions {
+		t := &p.Transactions[i]
+		for _, r := range t.RelatedResources {
+			if match(r) {
+				t.RelatedResources = append(t.RelatedResources, res)
+				p.UpdateTime = now()
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) handleListPayments(w http.ResponseWriter, r *http.Request) {
+	count := 10
+	if c, err := strconv.Atoi(r.URL.Query().Get("count")); err == nil && c > 0 {
+		count = c
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	payments := []*payment{}
+	for i := len(s.paymentOrder) - 1; i >= 0 && len(payments) < count; i-- {
+		payments = append(payments, s.payments[s.paymentOrder[i]])
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"payments": payments,
+		"count":    len(payments),
+	})
+}
+
+func (s *Server) handleGetPayment(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleExecutePayment(w http.ResponseWriter, r *http.Request) {
+	var req paypal.PaymentExecution
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.payments[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if p.State != paypal.PaymentStateCreated || p.approvedBy == "" {
+		writeError(w, http.StatusBadRequest, "PAYMENT_NOT_APPROVED_FOR_EXECUTION", "Payer has not approved payment")
+		return
+	}
+	if req.PayerID != p.approvedBy {
+		writeError(w, http.StatusBadRequest, "PAYER_ID_INVALID", "Payer ID is invalid",
+			paypal.ErrorDetail{Field: "payer_id", Issue: "Value is invalid"})
+		return
+	}
+
+	if p.Payer.PayerInfo == nil {
+		p.Payer.PayerInfo = &paypal.PayerInfo{}
+	}
+	p.Payer.PayerInfo.PayerID = req.PayerID
+	p.Payer.Status = paypal.PayerStatusVerified
+	s.completePayment(p)
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleGetSale(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sale, ok := s.sales[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, sale)
+}
+
+func (s *Server) handleRefundSale(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sale, ok := s.sales[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if sale.State != paypal.SaleStateCompleted && sale.State != paypal.SaleStatePartiallyRefunded {
+		writeError(w, http.StatusBadRequest, "TRANSACTION_ALREADY_REFUNDED", "Requested resource has already been refunded")
+		return
+	}
+
+	refund, status, full := s.refund(w, sale.Amount, req.Amount, "sale/"+sale.ID)
+	if refund == nil {
+		return
+	}
+	refund.ParentPayment = sale.ParentPayment
+
+	sale.UpdateTime = now()
+	sale.State = paypal.SaleStatePartiallyRefunded
+	if full {
+		sale.State = paypal.SaleStateRefunded
+	}
+	s.addRelatedResource(sale.ParentPayment, paypal.Resource{Refund: refund}, func(r paypal.Resource) bool {
+		return r.Sale == sale
+	})
+
+	writeJSON(w, status, refund)
+}
+
+func (s *Server) handleGetAuthorization(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, ok := s.authorizations[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, auth)
+}
+
+func (s *Server) handleCaptureAuthorization(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, ok := s.authorizations[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if auth.State != paypal.AuthorizationStateAuthorized && auth.State != paypal.AuthorizationStatePartiallyCaptured {
+		writeError(w, http.StatusBadRequest, "AUTHORIZATION_ALREADY_COMPLETED", "Authorization has already been completed")
+		return
+	}
+
+	total, _ := parseCents(auth.Amount.Total)
+	captured := s.settled("authorization/" + auth.ID)
+	amount, ok := s.amount(w, auth.Amount, req.Amount, total-captured)
+	if !ok {
+		return
+	}
+
+	capture := s.newCapture(auth.ParentPayment, amount, req.IsFinalCapture)
+	capture.Links = append(capture.Links, s.link("authorization", "GET", "/payments/authorization/"+auth.ID))
+
+	cents, _ := parseCents(amount.Total)
+	s.settle("authorization/"+auth.ID, cents)
+
+	auth.UpdateTime = now()
+	auth.State = paypal.AuthorizationStatePartiallyCaptured
+	if req.IsFinalCapture || captured+cents == total {
+		auth.State = paypal.AuthorizationStateCaptured
+	}
+	s.addRelatedResource(auth.ParentPayment, paypal.Resource{Capture: capture}, func(r paypal.Resource) bool {
+		return r.Authorization == auth
+	})
+
+	writeJSON(w, http.StatusOK, capture)
+}
+
+func (s *Server) handleVoidAuthorization(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, ok := s.authorizations[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if auth.State != paypal.AuthorizationStateAuthorized && auth.State != paypal.AuthorizationStatePartiallyCaptured {
+		writeError(w, http.StatusBadRequest, "AUTHORIZATION_ALREADY_COMPLETED", "Authorization has already been completed")
+		return
+	}
+
+	auth.State = paypal.AuthorizationStateVoided
+	auth.UpdateTime = now()
+
+	writeJSON(w, http.StatusOK, auth)
+}
+
+func (s *Server) handleReauthorizeAuthorization(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	auth, ok := s.authorizations[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if auth.State != paypal.AuthorizationStateAuthorized {
+		writeError(w, http.StatusBadRequest, "AUTHORIZATION_ALREADY_COMPLETED", "Authorization has already been completed")
+		return
+	}
+
+	total, _ := parseCents(auth.Amount.Total)
+	amount, ok := s.amount(w, auth.Amount, req.Amount, total)
+	if !ok {
+		return
+	}
+
+	validUntil := time.Now().UTC().Add(29 * 24 * time.Hour).Truncate(time.Second)
+	auth.Amount = amount
+	auth.ValidUntil = &validUntil
+	auth.UpdateTime = now()
+
+	writeJSON(w, http.StatusCreated, auth)
+}
+
+func (s *Server) handleGetCapture(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capture, ok := s.captures[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, capture)
+}
+
+func (s *Server) handleRefundCapture(w http.ResponseWriter, r *http.Request) {
+	var req amountReq
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	capture, ok := s.captures[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if capture.State != paypal.CaptureStateCompleted && capture.State != paypal.CaptureStatePartiallyRefunded {
+		writeError(w, http.StatusBadRequest, "TRANSACTION_ALREADY_REFUNDED", "Requested resource has already been refunded")
+		return
+	}
+
+	refund, status, full := s.refund(w, capture.Amount, req.Amount, "capture/"+capture.ID)
+	if refund == nil {
+		return
+	}
+	refund.CaptureID = capture.ID
+	refund.ParentPayment = capture.ParentPayment
+
+	capture.UpdateTime = now()
+	capture.State = paypal.CaptureStatePartiallyRefunded
+	if full {
+		capture.State = paypal.CaptureStateRefunded
+	}
+	s.addRelatedResource(capture.ParentPayment, paypal.Resource{Refund: refund}, func(r paypal.Resource) bool {
+		return r.Capture == capture
+	})
+
+	writeJSON(w, status, refund)
+}
+
+func (s *Server) handleGetRefund(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	refund, ok := s.refunds[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, refund)
+}
+
+// refund creates a refund of requested, or of whatever remains of paid if
+// requested is nil. key identifies the refunded resource. It writes an
+// error and returns nil if the refund is not possible. Must be called with
+// s.mu held
+func (s *Server) refund(w http.ResponseWriter, paid, requested *paypal.Amount, key string) (refund *paypal.Refund, status int, full bool) {
+	total, _ := parseCents(paid.Total)
+	refunded := s.settled(key)
+
+	amount, ok := s.amount(w, paid, requested, total-refunded)
+	if !ok {
+		return nil, 0, false
+	}
+	cents, _ := parseCents(amount.Total)
+	s.settle(key, cents)
+
+	refund = &paypal.Refund{
+		ID:         s.newID(""),
+		Amount:     amount,
+		CreateTime: now(),
+		UpdateTime: now(),
+		State:      paypal.RefundStateCompleted,
+	}
+	s.refunds[refund.ID] = refund
+
+	return refund, http.StatusCreated, refunded+cents == total
+}
+
+// settled returns the amount, in cents, refunded or captured so far from
+// the resource identified by key. Must be called with s.mu held
+func (s *Server) settled(key string) int64 {
+	return s.settledAmounts[key]
+}
+
+// settle records that cents were refunded or captured from the resource
+// identified by key. Must be called with s.mu held
+func (s *Server) settle(key string, cents int64) {
+	s.settledAmounts[key] += cents
+}
+
+// amount validates requested against the original amount and what remains
+// of it, returning the amount to use. A nil requested amount means all that
+// remains. It writes an error and returns false if requested is invalid
+func (s *Server) amount(w http.ResponseWriter, original, requested *paypal.Amount, remaining int64) (*paypal.Amount, bool) {
+	if requested == nil {
+		return &paypal.Amount{Currency: original.Currency, Total: formatCents(remaining)}, true
+	}
+
+	cents, err := parseCents(requested.Total)
+	if err != nil || cents == 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "amount.total", Issue: "Value is invalid"})
+		return nil, false
+	}
+	if requested.Currency != original.Currency {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "amount.currency", Issue: "Currency does not match the original transaction"})
+		return nil, false
+	}
+	if cents > remaining {
+		writeError(w, http.StatusBadRequest, "AMOUNT_EXCEEDED", "The requested amount exceeds the amount remaining")
+		return nil, false
+	}
+
+	return &paypal.Amount{Currency: requested.Currency, Total: formatCents(cents)}, true
+}
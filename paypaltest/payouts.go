@@ -0,0 +1,240 @@
+package paypaltest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/leebenson/paypal"
+)
+
+// maxPayoutItems is the number of items a payout batch can hold
+const maxPayoutItems = 15000
+
+// payoutBatch is a payout batch along with the IDs of its items
+type payoutBatch struct {
+	header paypal.PayoutBatchHeader
+	items  []string
+}
+
+// AddUnclaimedReceiver makes the payout items sent to receiver stay
+// UNCLAIMED, as if they had no Paypal account, so that they can be
+// cancelled
+func (s *Server) AddUnclaimedReceiver(receiver string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.unclaimedReceivers[receiver] = true
+}
+
+func (s *Server) handleCreatePayout(w http.ResponseWriter, r *http.Request) {
+	var p paypal.Payout
+	if !decode(w, r, &p) {
+		return
+	}
+	total, details := validatePayout(&p)
+	if len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.senderBatchIDs[p.SenderBatchHeader.SenderBatchID] {
+		writeError(w, http.StatusBadRequest, "USER_BUSINESS_ERROR", "Batch with given sender_batch_id already exists")
+		return
+	}
+	s.senderBatchIDs[p.SenderBatchHeader.SenderBatchID] = true
+
+	currency := p.Items[0].Amount.Currency
+	batch := &payoutBatch{header: paypal.PayoutBatchHeader{
+		PayoutBatchID:     s.newID("PB-"),
+		BatchStatus:       paypal.PayoutBatchStatusSuccess,
+		TimeCreated:       now(),
+		TimeCompleted:     now(),
+		SenderBatchHeader: p.SenderBatchHeader,
+		Amount:            &paypal.Currency{Currency: currency, Value: formatCents(total)},
+		Fees:              &paypal.Currency{Currency: currency, Value: "0.00"},
+	}}
+	for i := range p.Items {
+		item := p.Items[i]
+		if item.RecipientType == "" {
+			item.RecipientType = p.SenderBatchHeader.RecipientType
+		}
+		if item.RecipientType == "" {
+			item.RecipientType = paypal.PayoutRecipientTypeEmail
+		}
+
+		detail := &paypal.PayoutItemDetail{
+			PayoutItemID:      s.newID("PI-"),
+			TransactionID:     s.newID("TX-"),
+			TransactionStatus: paypal.PayoutItemStatusSuccess,
+			PayoutItemFee:     &paypal.Currency{Currency: currency, Value: "0.00"},
+			PayoutBatchID:     batch.header.PayoutBatchID,
+			SenderBatchID:     p.SenderBatchHeader.SenderBatchID,
+			PayoutItem:        &item,
+			TimeProcessed:     now(),
+		}
+		if s.unclaimedReceivers[item.Receiver] {
+			detail.TransactionStatus = paypal.PayoutItemStatusUnclaimed
+		}
+		detail.Links = []paypal.Links{
+			s.link("item", "GET", "/payments/payouts-item/"+detail.PayoutItemID),
+		}
+		s.payoutItems[detail.PayoutItemID] = detail
+		batch.items = append(batch.items, detail.PayoutItemID)
+	}
+	s.payoutBatches[batch.header.PayoutBatchID] = batch
+
+	if sync, _ := strconv.ParseBool(r.URL.Query().Get("sync_mode")); sync {
+		writeJSON(w, http.StatusCreated, s.payoutBatch(batch, 1, len(batch.items), false))
+		return
+	}
+
+	// Without sync_mode, the batch is processed after the response
+	header := batch.header
+	header.BatchStatus = paypal.PayoutBatchStatusPending
+	header.TimeCompleted = nil
+	writeJSON(w, http.StatusCreated, paypal.PayoutBatch{
+		BatchHeader: &header,
+		Links:       []paypal.Links{s.link("self", "GET", "/payments/payouts/"+header.PayoutBatchID)},
+	})
+}
+
+// validatePayout returns the total of a payout in cents, or the issues
+// that make it invalid
+func validatePayout(p *paypal.Payout) (int64, []paypal.ErrorDetail) {
+	var details []paypal.ErrorDetail
+	if p.SenderBatchHeader == nil || p.SenderBatchHeader.SenderBatchID == "" {
+		details = append(details, paypal.ErrorDetail{Field: "sender_batch_header.sender_batch_id", Issue: "Required field missing"})
+	}
+	if len(p.Items) == 0 || len(p.Items) > maxPayoutItems {
+		details = append(details, paypal.ErrorDetail{Field: "items", Issue: "Value is invalid"})
+	}
+	if len(details) > 0 {
+		return 0, details
+	}
+
+	var total int64
+	for i, item := range p.Items {
+		field := fmt.Sprintf("items[%d]", i)
+		if item.Amount == nil {
+			details = append(details, paypal.ErrorDetail{Field: field + ".amount", Issue: "Required field missing"})
+		} else if cents, err := parseCents(item.Amount.Value); err != nil || cents == 0 ||
+			item.Amount.Currency != p.Items[0].Amount.Currency {
+			details = append(details, paypal.ErrorDetail{Field: field + ".amount", Issue: "Value is invalid"})
+		} else {
+			total += cents
+		}
+
+		recipientType := item.RecipientType
+		if recipientType == "" {
+			recipientType = p.SenderBatchHeader.RecipientType
+		}
+		if !validReceiver(recipientType, item.Receiver) {
+			details = append(details, paypal.ErrorDetail{Field: field + ".receiver", Issue: "Value is invalid"})
+		}
+	}
+
+	return total, details
+}
+
+// validReceiver tells whether receiver is valid for a recipient type,
+// defaulting to an email
+func validReceiver(recipientType paypal.PayoutRecipientType, receiver string) bool {
+	switch recipientType {
+	case "", paypal.PayoutRecipientTypeEmail:
+		return strings.Contains(receiver, "@")
+	case paypal.PayoutRecipientTypePhone:
+		return receiver != "" && strings.Trim(receiver, "+0123456789 -") == ""
+	case paypal.PayoutRecipientTypePaypalID:
+		return receiver != ""
+	}
+
+	return false
+}
+
+// payoutBatch returns a page of the items of batch, pages starting at 1.
+// Must be called with s.mu held
+func (s *Server) payoutBatch(batch *payoutBatch, page, pageSize int, totalRequired bool) paypal.PayoutBatch {
+	header := batch.header
+	resp := paypal.PayoutBatch{
+		BatchHeader: &header,
+		Items:       []paypal.PayoutItemDetail{},
+		Links:       []paypal.Links{s.link("self", "GET", "/payments/payouts/"+header.PayoutBatchID)},
+	}
+	if start := (page - 1) * pageSize; start >= 0 && start < len(batch.items) {
+		end := start + pageSize
+		if end > len(batch.items) {
+			end = len(batch.items)
+		}
+		for _, id := range batch.items[start:end] {
+			resp.Items = append(resp.Items, *s.payoutItems[id])
+		}
+	}
+	if totalRequired {
+		resp.TotalItems = len(batch.items)
+		resp.TotalPages = (len(batch.items) + pageSize - 1) / pageSize
+	}
+
+	return resp
+}
+
+func (s *Server) handleGetPayoutBatch(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page <= 0 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(q.Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 1000
+	}
+	totalRequired, _ := strconv.ParseBool(q.Get("total_required"))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	batch, ok := s.payoutBatches[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, s.payoutBatch(batch, page, pageSize, totalRequired))
+}
+
+func (s *Server) handleGetPayoutItem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.payoutItems[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, item)
+}
+
+func (s *Server) handleCancelPayoutItem(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, ok := s.payoutItems[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if item.TransactionStatus != paypal.PayoutItemStatusUnclaimed {
+		writeError(w, http.StatusBadRequest, "ITEM_INCORRECT_STATUS",
+			fmt.Sprintf("Item cannot be cancelled; item is %s", item.TransactionStatus))
+		return
+	}
+	item.TransactionStatus = paypal.PayoutItemStatusReturned
+	item.TimeProcessed = now()
+
+	writeJSON(w, http.StatusOK, item)
+}
@@ -0,0 +1,161 @@
+package paypaltest
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/leebenson/paypal"
+)
+
+func (s *Server) handleCreatePlan(w http.ResponseWriter, r *http.Request) {
+	var plan paypal.Plan
+	if !decode(w, r, &plan) {
+		return
+	}
+	if details := validatePlan(&plan); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan.ID = s.newID("P-")
+	plan.State = paypal.PlanStateCreated
+	plan.CreateTime = now()
+	plan.UpdateTime = now()
+	for i := range plan.PaymentDefinitions {
+		def := &plan.PaymentDefinitions[i]
+		def.ID = s.newID("PD-")
+		for j := range def.ChargeModels {
+			def.ChargeModels[j].ID = s.newID("CHM-")
+		}
+	}
+	if plan.MerchantPreferences != nil {
+		plan.MerchantPreferences.ID = s.newID("MERCHANTPREFERENCE-")
+	}
+	plan.Links = []paypal.Links{
+		s.link("self", "GET", "/payments/billing-plans/"+plan.ID),
+	}
+	s.plans[plan.ID] = &plan
+	s.planOrder = append(s.planOrder, plan.ID)
+
+	writeJSON(w, http.StatusCreated, plan)
+}
+
+// validatePlan returns the issues with a plan to create
+func validatePlan(plan *paypal.Plan) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+	if plan.Name == "" {
+		details = append(details, paypal.ErrorDetail{Field: "name", Issue: "Required field missing"})
+	}
+	if plan.Description == "" {
+		details = append(details, paypal.ErrorDetail{Field: "description", Issue: "Required field missing"})
+	}
+	if plan.Type != paypal.PlanTypeFixed && plan.Type != paypal.PlanTypeInfinite {
+		details = append(details, paypal.ErrorDetail{Field: "type", Issue: "Value is invalid"})
+	}
+	if len(plan.PaymentDefinitions) == 0 {
+		details = append(details, paypal.ErrorDetail{Field: "payment_definitions", Issue: "Required field missing"})
+	}
+	for _, def := range plan.PaymentDefinitions {
+		if def.Amount == nil {
+			details = append(details, paypal.ErrorDetail{Field: "payment_definitions.amount", Issue: "Required field missing"})
+		} else if _, err := parseCents(def.Amount.Value); err != nil {
+			details = append(details, paypal.ErrorDetail{Field: "payment_definitions.amount.value", Issue: "Value is invalid"})
+		}
+	}
+
+	return details
+}
+
+func (s *Server) handleGetPlan(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, ok := s.plans[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, plan)
+}
+
+func (s *Server) handleListPlans(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	status := paypal.PlanState(q.Get("status"))
+	if status == "" {
+		status = paypal.PlanStateCreated
+	}
+	page, _ := strconv.Atoi(q.Get("page"))
+	pageSize, err := strconv.Atoi(q.Get("page_size"))
+	if err != nil || pageSize <= 0 {
+		pageSize = 10
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matching []paypal.Plan
+	for _, id := range s.planOrder {
+		if plan := s.plans[id]; plan.State == status {
+			matching = append(matching, *plan)
+		}
+	}
+
+	resp := paypal.ListPlansResp{Plans: []paypal.Plan{}}
+	if start := page * pageSize; start >= 0 && start < len(matching) {
+		end := start + pageSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		resp.Plans = matching[start:end]
+	}
+	if q.Get("total_required") == "yes" {
+		resp.TotalItems = strconv.Itoa(len(matching))
+		resp.TotalPages = strconv.Itoa((len(matching) + pageSize - 1) / pageSize)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePatchPlan(w http.ResponseWriter, r *http.Request) {
+	var patches []paypal.Patch
+	if !decode(w, r, &patches) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	plan, ok := s.plans[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	var patched paypal.Plan
+	if err := applyPatch(&patched, plan, patches); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "patch_request", Issue: err.Error()})
+		return
+	}
+	switch patched.State {
+	case paypal.PlanStateCreated, paypal.PlanStateActive, paypal.PlanStateInactive, paypal.PlanStateDeleted:
+	default:
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "state", Issue: "Value is invalid"})
+		return
+	}
+	if patched.State == paypal.PlanStateCreated && plan.State != paypal.PlanStateCreated {
+		writeError(w, http.StatusBadRequest, "BUSINESS_VALIDATION_ERROR", "Plan cannot be moved back to the CREATED state")
+		return
+	}
+	patched.ID = plan.ID
+	patched.CreateTime = plan.CreateTime
+	patched.UpdateTime = now()
+	*plan = patched
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,379 @@
+// Package paypaltest provides a fake Paypal REST API running in-process, so
+// that code using the paypal package can be tested without network access
+// or sandbox credentials. Faults such as errors, slow responses or expired
+// tokens can be scripted with Server.AddRule.
+//
+//	srv := paypaltest.NewServer()
+//	defer srv.Close()
+//
+//	client := srv.Client()
+//	payment, err := client.CreatePayment(p)
+package paypaltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+const (
+	// ClientID is the client ID the Server accepts by default
+	ClientID = "paypaltest-client-id"
+
+	// Secret is the secret the Server accepts by default
+	Secret = "paypaltest-secret"
+)
+
+type (
+	// Server is a fake Paypal REST API. It implements the OAuth2 token,
+	// payment, sale, authorization, order, capture, refund, vault, billing
+	// plan, billing agreement, webhook, Log In with PayPal, invoice,
+	// invoice template, web profile and payout endpoints, keeping resources
+	// in memory and moving them through the same states as Paypal does
+	Server struct {
+		// URL is the API base to create clients with, in place of
+		// paypal.APIBaseSandBox
+		URL string
+
+		// ClientID and Secret are the credentials the server accepts
+		ClientID string
+		Secret   string
+
+		// TokenTTL is the lifetime of the access tokens the server issues
+		TokenTTL time.Duration
+
+		// User is the user who logs in when redirected to the
+		// authorization URL of a client
+		User paypal.UserInfo
+
+		srv *httptest.Server
+		mux *http.ServeMux
+
+		mu                 sync.Mutex
+		seq                int
+		tokens             map[string]time.Time
+		responses          map[string]cachedResponse
+		payments           map[string]*payment
+		paymentOrder       []string
+		sales              map[string]*paypal.Sale
+		authorizations     map[string]*paypal.Authorization
+		captures           map[string]*paypal.Capture
+		orders             map[string]*paypal.Order
+		refunds            map[string]*paypal.Refund
+		cards              map[string]*paypal.VaultResponse
+		cardOrder          []string
+		plans              map[string]*paypal.Plan
+		planOrder          []string
+		agreements         map[string]*agreement
+		agreementTokens    map[string]*agreement
+		webhooks           map[string]*paypal.Webhook
+		webhookOrder       []string
+		events             map[string]*paypal.WebhookEvent
+		eventOrder         []string
+		settledAmounts     map[string]int64
+		deliveries         map[string][]string
+		codes              map[string]*grant
+		refreshTokens      map[string]*grant
+		userTokens         map[string]*grant
+		invoices           map[string]*invoice
+		invoiceOrder       []string
+		invoiceNumbers     map[string]bool
+		templates          map[string]*paypal.InvoiceTemplate
+		templateOrder      []string
+		webProfiles        map[string]*paypal.WebProfile
+		webProfileOrder    []string
+		payoutBatches      map[string]*payoutBatch
+		payoutItems        map[string]*paypal.PayoutItemDetail
+		senderBatchIDs     map[string]bool
+		unclaimedReceivers map[string]bool
+		rules              []*Rule
+		requests           []Request
+
+		signerOnce sync.Once
+		sign       *signer
+		wg         sync.WaitGroup
+	}
+
+	// cachedResponse is the response to a POST, replayed when a request
+	// with the same PayPal-Request-Id is received
+	cachedResponse struct {
+		status int
+		body   []byte
+	}
+
+	// errorBody maps to the error object returned by the API
+	errorBody struct {
+		Name    string               `json:"name"`
+		Message string               `json:"message"`
+		DebugID string               `json:"debug_id"`
+		Details []paypal.ErrorDetail `json:"details,omitempty"`
+	}
+)
+
+// NewServer starts and returns a new Server. The caller should call Close
+// when finished, to shut it down
+func NewServer() *Server {
+	s := &Server{
+		ClientID:           ClientID,
+		Secret:             Secret,
+		TokenTTL:           9 * time.Hour,
+		User:               defaultUser,
+		mux:                http.NewServeMux(),
+		tokens:             make(map[string]time.Time),
+		responses:          make(map[string]cachedResponse),
+		payments:           make(map[string]*payment),
+		sales:              make(map[string]*paypal.Sale),
+		authorizations:     make(map[string]*paypal.Authorization),
+		captures:           make(map[string]*paypal.Capture),
+		orders:             make(map[string]*paypal.Order),
+		refunds:            make(map[string]*paypal.Refund),
+		cards:              make(map[string]*paypal.VaultResponse),
+		plans:              make(map[string]*paypal.Plan),
+		agreements:         make(map[string]*agreement),
+		agreementTokens:    make(map[string]*agreement),
+		webhooks:           make(map[string]*paypal.Webhook),
+		events:             make(map[string]*paypal.WebhookEvent),
+		deliveries:         make(map[string][]string),
+		codes:              make(map[string]*grant),
+		refreshTokens:      make(map[string]*grant),
+		userTokens:         make(map[string]*grant),
+		invoices:           make(map[string]*invoice),
+		invoiceNumbers:     make(map[string]bool),
+		templates:          make(map[string]*paypal.InvoiceTemplate),
+		webProfiles:        make(map[string]*paypal.WebProfile),
+		payoutBatches:      make(map[string]*payoutBatch),
+		payoutItems:        make(map[string]*paypal.PayoutItemDetail),
+		senderBatchIDs:     make(map[string]bool),
+		unclaimedReceivers: make(map[string]bool),
+		settledAmounts:     make(map[string]int64),
+	}
+
+	s.mux.HandleFunc("POST /oauth2/token", s.handleToken)
+	s.mux.HandleFunc("GET "+certPath, s.handleCert)
+	s.mux.HandleFunc("GET /connect", s.handleConnect)
+	s.mux.HandleFunc("GET /webapps/auth/protocol/openidconnect/v1/endsession", s.handleEndSession)
+	s.mux.HandleFunc("POST /identity/openidconnect/tokenservice", s.handleIdentityToken)
+	s.mux.HandleFunc("GET /identity/openidconnect/userinfo/", s.handleUserInfo)
+	s.routes()
+
+	s.srv = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	s.URL = s.srv.URL
+
+	return s
+}
+
+// Close waits for the webhook notifications being sent, then shuts down
+// the server
+func (s *Server) Close() {
+	s.wg.Wait()
+	s.srv.Close()
+}
+
+// Client returns a paypal.Client pointed at the server, with its
+// credentials
+func (s *Server) Client(opts ...paypal.ClientOption) *paypal.Client {
+	return paypal.NewClient(s.ClientID, s.Secret, s.URL, opts...)
+}
+
+// ExpireTokens makes every access token issued so far invalid, as if they
+// had expired
+func (s *Server) ExpireTokens() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for t := range s.tokens {
+		s.tokens[t] = time.Time{}
+	}
+}
+
+// route registers h for pattern, behind bearer token authentication
+func (s *Server) route(pattern string, h http.HandlerFunc) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if !s.authorized(r) {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{
+				"error":             "invalid_token",
+				"error_description": "Token signature verification failed",
+			})
+			return
+		}
+		h(w, r)
+	})
+}
+
+func (s *Server) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(paypal.DebugIDHeader, s.debugID())
+
+	if rule := s.record(r); rule != nil && s.applyRule(rule, w, r) {
+		return
+	}
+
+	id := r.Header.Get(paypal.RequestIDHeader)
+	if r.Method != "POST" || id == "" || r.URL.Path == "/oauth2/token" || r.URL.Path == "/identity/openidconnect/tokenservice" {
+		s.mux.ServeHTTP(w, r)
+		return
+	}
+
+	key := r.URL.Path + "\n" + id
+	s.mu.Lock()
+	cached, ok := s.responses[key]
+	s.mu.Unlock()
+	if ok {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(cached.status)
+		w.Write(cached.body)
+		return
+	}
+
+	rec := httptest.NewRecorder()
+	rec.Header().Set(paypal.DebugIDHeader, w.Header().Get(paypal.DebugIDHeader))
+	s.mux.ServeHTTP(rec, r)
+	if rec.Code < 500 && rec.Code != http.StatusUnauthorized {
+		s.mu.Lock()
+		s.responses[key] = cachedResponse{rec.Code, rec.Body.Bytes()}
+		s.mu.Unlock()
+	}
+
+	for k, v := range rec.Header() {
+		w.Header()[k] = v
+	}
+	w.WriteHeader(rec.Code)
+	w.Write(rec.Body.Bytes())
+}
+
+func (s *Server) handleToken(w http.ResponseWriter, r *http.Request) {
+	id, secret, ok := r.BasicAuth()
+	if !ok || id != s.ClientID || secret != s.Secret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{
+			"error":             "invalid_client",
+			"error_description": "Client Authentication failed",
+		})
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "unsupported_grant_type",
+			"error_description": "Grant Type is NULL",
+		})
+		return
+	}
+
+	s.mu.Lock()
+	token := s.newID("A21AA")
+	s.tokens[token] = time.Now().Add(s.TokenTTL)
+	ttl := int(s.TokenTTL / time.Second)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, paypal.TokenResp{
+		Scope:     "https://api.paypal.com/v1/payments/.* https://api.paypal.com/v1/vault/credit-card",
+		Token:     token,
+		Type:      "Bearer",
+		AppID:     "APP-80W284485P519543T",
+		ExpiresIn: ttl,
+	})
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expires, ok := s.tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+// newID returns a new resource ID. Must be called with s.mu held
+func (s *Server) newID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s%017d", prefix, s.seq)
+}
+
+func (s *Server) debugID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	return fmt.Sprintf("%013x", s.seq)
+}
+
+// link returns a link to a resource of the server
+func (s *Server) link(rel, method, path string) paypal.Links {
+	return paypal.Links{Href: s.URL + path, Rel: rel, Method: method}
+}
+
+// decode reads the JSON body of r into v, writing a MALFORMED_REQUEST error
+// and returning false if it can't
+func decode(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	data, err := ioutil.ReadAll(r.Body)
+	if err == nil && len(data) > 0 {
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "MALFORMED_REQUEST", "Incoming JSON request does not map to API request")
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, name, message string, details ...paypal.ErrorDetail) {
+	writeJSON(w, status, errorBody{
+		Name:    name,
+		Message: message,
+		DebugID: w.Header().Get(paypal.DebugIDHeader),
+		Details: details,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter) {
+	writeError(w, http.StatusNotFound, paypal.ErrorNameInvalidResourceID, "The requested resource ID was not found")
+}
+
+// parseCents converts a decimal amount such as "7.47" to cents
+func parseCents(amount string) (int64, error) {
+	parts := strings.SplitN(amount, ".", 2)
+	units, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || units < 0 {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	var cents int64
+	if len(parts) == 2 {
+		frac := parts[1]
+		if len(frac) == 0 || len(frac) > 2 {
+			return 0, fmt.Errorf("invalid amount %q", amount)
+		}
+		if len(frac) == 1 {
+			frac += "0"
+		}
+		if cents, err = strconv.ParseInt(frac, 10, 64); err != nil || cents < 0 {
+			return 0, fmt.Errorf("invalid amount %q", amount)
+		}
+	}
+
+	return units*100 + cents, nil
+}
+
+// formatCents is the inverse of parseCents
+func formatCents(cents int64) string {
+	return fmt.Sprintf("%d.%02d", cents/100, cents%100)
+}
+
+func now() *time.Time {
+	t := time.Now().UTC().Truncate(time.Second)
+	return &t
+}
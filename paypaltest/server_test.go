@@ -0,0 +1,867 @@
+package paypaltest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/leebenson/paypal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func creditCardPayment(intent paypal.PaymentIntent, total string) paypal.Payment {
+	return paypal.Payment{
+		Intent: intent,
+		Payer: &paypal.Payer{
+			PaymentMethod: paypal.PaymentMethodCreditCard,
+			FundingInstruments: []paypal.FundingInstrument{{
+				CreditCard: &paypal.CreditCard{
+					Number:      "4417119669820331",
+					Type:        "visa",
+					ExpireMonth: "11",
+					ExpireYear:  "2030",
+					CVV2:        "874",
+				},
+			}},
+		},
+		Transactions: []paypal.Transaction{{
+			Amount:      &paypal.Amount{Currency: "USD", Total: total},
+			Description: "This is the payment transaction description.",
+		}},
+	}
+}
+
+func TestServer(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer receiver.Close()
+
+	Convey("With a sale paid by credit card", t, func() {
+		created, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentSale, "7.47"))
+
+		So(err, ShouldBeNil)
+		So(created.State, ShouldEqual, paypal.PaymentStateApproved)
+		saleID := created.Transactions[0].RelatedResources[0].Sale.ID
+
+		Convey("The sale should be completed", func() {
+			sale, err := client.GetSale(saleID)
+
+			So(err, ShouldBeNil)
+			So(sale.State, ShouldEqual, paypal.SaleStateCompleted)
+			So(sale.ParentPayment, ShouldEqual, created.ID)
+		})
+
+		Convey("A partial refund then a full refund should refund the sale", func() {
+			refund, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "2.34"})
+
+			So(err, ShouldBeNil)
+			So(refund.Amount.Total, ShouldEqual, "2.34")
+			So(refund.ParentPayment, ShouldEqual, created.ID)
+
+			sale, err := client.GetSale(saleID)
+			So(err, ShouldBeNil)
+			So(sale.State, ShouldEqual, paypal.SaleStatePartiallyRefunded)
+
+			rest, err := client.RefundSale(saleID, nil)
+			So(err, ShouldBeNil)
+			So(rest.Amount.Total, ShouldEqual, "5.13")
+
+			sale, err = client.GetSale(saleID)
+			So(err, ShouldBeNil)
+			So(sale.State, ShouldEqual, paypal.SaleStateRefunded)
+
+			fetched, err := client.GetRefund(refund.ID)
+			So(err, ShouldBeNil)
+			So(fetched.Amount, ShouldResemble, refund.Amount)
+
+			payment, err := client.GetPayment(created.ID)
+			So(err, ShouldBeNil)
+			So(payment.Transactions[0].RelatedResources, ShouldHaveLength, 3)
+		})
+
+		Convey("Refunding more than the sale amount should fail", func() {
+			_, err := client.RefundSale(saleID, &paypal.Amount{Currency: "USD", Total: "10.00"})
+
+			So(err, ShouldNotBeNil)
+			So(err.(*paypal.ErrorResponse).Name, ShouldEqual, "AMOUNT_EXCEEDED")
+		})
+
+		Convey("Retrying a refund with the same PayPal-Request-Id should not refund twice", func() {
+			ctx := paypal.WithRequestID(context.Background(), "refund-1")
+			first, err := client.RefundSaleContext(ctx, saleID, &paypal.Amount{Currency: "USD", Total: "1.00"})
+			So(err, ShouldBeNil)
+
+			second, err := client.RefundSaleContext(ctx, saleID, &paypal.Amount{Currency: "USD", Total: "1.00"})
+			So(err, ShouldBeNil)
+			So(second.ID, ShouldEqual, first.ID)
+		})
+
+		Convey("The payment should be listed", func() {
+			payments, err := client.ListPayments(map[string]string{"count": "1"})
+
+			So(err, ShouldBeNil)
+			So(payments, ShouldHaveLength, 1)
+			So(payments[0].ID, ShouldEqual, created.ID)
+		})
+	})
+
+	Convey("With an authorized payment", t, func() {
+		created, err := client.CreatePayment(creditCardPayment(paypal.PaymentIntentAuthorize, "10.00"))
+
+		So(err, ShouldBeNil)
+		authID := created.Transactions[0].RelatedResources[0].Authorization.ID
+
+		Convey("A partial capture should leave the rest of the authorization capturable", func() {
+			capture, err := client.CaptureAuthorization(authID, &paypal.Amount{Currency: "USD", Total: "4.00"}, false)
+
+			So(err, ShouldBeNil)
+			So(capture.State, ShouldEqual, paypal.CaptureStateCompleted)
+
+			auth, err := client.GetAuthorization(authID)
+			So(err, ShouldBeNil)
+			So(auth.State, ShouldEqual, paypal.AuthorizationStatePartiallyCaptured)
+
+			refund, err := client.RefundCapture(capture.ID, nil)
+			So(err, ShouldBeNil)
+			So(refund.CaptureID, ShouldEqual, capture.ID)
+			So(refund.Amount.Total, ShouldEqual, "4.00")
+
+			_, err = client.CaptureAuthorization(authID, nil, true)
+			So(err, ShouldBeNil)
+
+			auth, err = client.GetAuthorization(authID)
+			So(err, ShouldBeNil)
+			So(auth.State, ShouldEqual, paypal.AuthorizationStateCaptured)
+		})
+
+		Convey("Voiding should make the authorization unusable", func() {
+			auth, err := client.VoidAuthorization(authID)
+
+			So(err, ShouldBeNil)
+			So(auth.State, ShouldEqual, paypal.AuthorizationStateVoided)
+
+			_, err = client.CaptureAuthorization(authID, nil, true)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("With a payment made with a Paypal account", t, func() {
+		p := creditCardPayment(paypal.PaymentIntentSale, "5.00")
+		p.Payer = &paypal.Payer{PaymentMethod: paypal.PaymentMethodPaypal}
+		p.RedirectURLs = &paypal.RedirectURLs{ReturnURL: "https://example.com/ok", CancelURL: "https://example.com/cancel"}
+
+		created, err := client.CreatePayment(p)
+
+		So(err, ShouldBeNil)
+		So(created.State, ShouldEqual, paypal.PaymentStateCreated)
+		So(created.Links, ShouldContain, paypal.Links{
+			Href:   srv.URL + "/checkoutnow?token=EC-" + created.ID,
+			Rel:    "approval_url",
+			Method: "REDIRECT",
+		})
+
+		Convey("Executing it before the payer approved it should fail", func() {
+			_, err := client.ExecutePayment(created.ID, "PAYER1", nil)
+
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("Executing it once approved should complete the sale", func() {
+			So(srv.ApprovePayment(created.ID, "PAYER1"), ShouldBeNil)
+
+			resp, err := client.ExecutePayment(created.ID, "PAYER1", nil)
+
+			So(err, ShouldBeNil)
+			So(resp.Transactions[0].RelatedResources[0].Sale.State, ShouldEqual, paypal.SaleStateCompleted)
+		})
+	})
+
+	Convey("With an order approved by the payer", t, func() {
+		p := creditCardPayment(paypal.PaymentIntentOrder, "20.00")
+		p.Payer = &paypal.Payer{PaymentMethod: paypal.PaymentMethodPaypal}
+		p.RedirectURLs = &paypal.RedirectURLs{ReturnURL: "https://example.com/ok", CancelURL: "https://example.com/cancel"}
+
+		created, err := client.CreatePayment(p)
+		So(err, ShouldBeNil)
+		So(srv.ApprovePayment(created.ID, "PAYER1"), ShouldBeNil)
+
+		executed, err := client.ExecutePayment(created.ID, "PAYER1", nil)
+		So(err, ShouldBeNil)
+		order := executed.Transactions[0].RelatedResources[0].Order
+		So(order, ShouldNotBeNil)
+		So(order.State, ShouldEqual, paypal.OrderStatePending)
+
+		Convey("It can be authorized, captured then refunded", func() {
+			auth, err := client.AuthorizeOrder(order.ID, &paypal.Amount{Currency: "USD", Total: "20.00"})
+			So(err, ShouldBeNil)
+			So(auth.State, ShouldEqual, paypal.AuthorizationStateAuthorized)
+
+			capture, err := client.CaptureOrder(order.ID, &paypal.Amount{Currency: "USD", Total: "20.00"}, true)
+			So(err, ShouldBeNil)
+			So(capture.Amount.Total, ShouldEqual, "20.00")
+
+			fetched, err := client.GetOrder(order.ID)
+			So(err, ShouldBeNil)
+			So(fetched.State, ShouldEqual, paypal.OrderStateCompleted)
+
+			_, err = client.RefundOrder(order.ID, &paypal.Amount{Currency: "USD", Total: "5.00"})
+			So(err, ShouldBeNil)
+
+			fetched, err = client.GetOrder(order.ID)
+			So(err, ShouldBeNil)
+			So(fetched.State, ShouldEqual, paypal.OrderStatePartiallyRefunded)
+		})
+
+		Convey("It can be voided, after which it cannot be captured", func() {
+			voided, err := client.VoidOrder(order.ID)
+			So(err, ShouldBeNil)
+			So(voided.State, ShouldEqual, paypal.OrderStateVoided)
+
+			_, err = client.CaptureOrder(order.ID, nil, true)
+			So(err, ShouldNotBeNil)
+		})
+	})
+
+	Convey("With a billing plan", t, func() {
+		plan, err := client.CreatePlan(paypal.Plan{
+			Name:        "Monthly plan",
+			Description: "Monthly subscription",
+			Type:        paypal.PlanTypeInfinite,
+			PaymentDefinitions: []paypal.PaymentDefinition{{
+				Name:              "Regular payments",
+				Type:              paypal.PaymentDefinitionTypeRegular,
+				Frequency:         paypal.FrequencyMonth,
+				FrequencyInterval: "1",
+				Cycles:            "0",
+				Amount:            &paypal.Currency{Currency: "USD", Value: "9.99"},
+			}},
+			MerchantPreferences: &paypal.MerchantPreferences{
+				ReturnURL: "https://example.com/ok",
+				CancelURL: "https://example.com/cancel",
+			},
+		})
+
+		So(err, ShouldBeNil)
+		So(plan.State, ShouldEqual, paypal.PlanStateCreated)
+		So(plan.PaymentDefinitions[0].ID, ShouldNotBeBlank)
+
+		Convey("It can be activated then deactivated", func() {
+			So(client.ActivatePlan(plan.ID), ShouldBeNil)
+
+			active, err := client.ListPlans(map[string]string{"status": "ACTIVE", "total_required": "yes"})
+			So(err, ShouldBeNil)
+			So(active.TotalItems, ShouldEqual, "1")
+			So(active.Plans[0].ID, ShouldEqual, plan.ID)
+
+			So(client.DeactivatePlan(plan.ID), ShouldBeNil)
+
+			fetched, err := client.GetPlan(plan.ID)
+			So(err, ShouldBeNil)
+			So(fetched.State, ShouldEqual, paypal.PlanStateInactive)
+		})
+
+		Convey("Its fields can be patched", func() {
+			err := client.UpdatePlan(plan.ID, []paypal.Patch{{
+				Operation: "replace",
+				Path:      "/merchant_preferences/return_url",
+				Value:     "https://example.com/welcome",
+			}})
+			So(err, ShouldBeNil)
+
+			fetched, err := client.GetPlan(plan.ID)
+			So(err, ShouldBeNil)
+			So(fetched.MerchantPreferences.ReturnURL, ShouldEqual, "https://example.com/welcome")
+			So(fetched.Name, ShouldEqual, "Monthly plan")
+		})
+	})
+
+	Convey("With an agreement on an active plan", t, func() {
+		plan, err := client.CreatePlan(paypal.Plan{
+			Name:        "Monthly plan",
+			Description: "Monthly subscription",
+			Type:        paypal.PlanTypeFixed,
+			PaymentDefinitions: []paypal.PaymentDefinition{{
+				Name:              "Regular payments",
+				Type:              paypal.PaymentDefinitionTypeRegular,
+				Frequency:         paypal.FrequencyMonth,
+				FrequencyInterval: "1",
+				Cycles:            "12",
+				Amount:            &paypal.Currency{Currency: "USD", Value: "9.99"},
+			}},
+		})
+		So(err, ShouldBeNil)
+		So(client.ActivatePlan(plan.ID), ShouldBeNil)
+
+		start := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+		created, err := client.CreateAgreement(paypal.Agreement{
+			Name:        "Monthly subscription",
+			Description: "Monthly subscription to the service",
+			StartDate:   &start,
+			Payer:       &paypal.Payer{PaymentMethod: paypal.PaymentMethodPaypal},
+			Plan:        &paypal.Plan{ID: plan.ID},
+		})
+		So(err, ShouldBeNil)
+		So(created.ApprovalURL, ShouldEqual, srv.URL+"/checkoutnow?token="+created.Token)
+
+		_, err = client.ExecuteAgreement(created.Token)
+		So(err, ShouldNotBeNil)
+
+		So(srv.ApproveAgreement(created.Token, "PAYER1"), ShouldBeNil)
+		agreement, err := client.ExecuteAgreement(created.Token)
+		So(err, ShouldBeNil)
+		So(agreement.State, ShouldEqual, paypal.AgreementStateActive)
+		So(agreement.AgreementDetails.CyclesRemaining, ShouldEqual, "12")
+
+		Convey("It can be suspended, reactivated then cancelled", func() {
+			So(client.SuspendAgreement(agreement.ID, "Suspending"), ShouldBeNil)
+			So(client.SuspendAgreement(agreement.ID, "Suspending again"), ShouldNotBeNil)
+			So(client.ReactivateAgreement(agreement.ID, "Reactivating"), ShouldBeNil)
+			So(client.CancelAgreement(agreement.ID, "Cancelling"), ShouldBeNil)
+
+			fetched, err := client.GetAgreement(agreement.ID)
+			So(err, ShouldBeNil)
+			So(fetched.State, ShouldEqual, paypal.AgreementStateCancelled)
+
+			today := time.Now().UTC()
+			transactions, err := client.ListAgreementTransactions(agreement.ID, today.AddDate(0, 0, -1), today)
+			So(err, ShouldBeNil)
+			So(transactions, ShouldHaveLength, 4)
+			So(transactions[1].Status, ShouldEqual, "Suspended")
+		})
+
+		Convey("Its outstanding balance can be set then billed", func() {
+			So(client.SetAgreementBalance(agreement.ID, paypal.Currency{Currency: "USD", Value: "15.00"}), ShouldBeNil)
+			So(client.BillAgreementBalance(agreement.ID, "Billing part of the balance", &paypal.Currency{Currency: "USD", Value: "10.00"}), ShouldBeNil)
+
+			fetched, err := client.GetAgreement(agreement.ID)
+			So(err, ShouldBeNil)
+			So(fetched.AgreementDetails.OutstandingBalance.Value, ShouldEqual, "5.00")
+			So(fetched.AgreementDetails.LastPaymentAmount.Value, ShouldEqual, "10.00")
+
+			So(client.BillAgreementBalance(agreement.ID, "Billing too much", &paypal.Currency{Currency: "USD", Value: "6.00"}), ShouldNotBeNil)
+		})
+
+		Convey("Its description can be patched", func() {
+			err := client.UpdateAgreement(agreement.ID, []paypal.Patch{{
+				Operation: "replace",
+				Path:      "/",
+				Value:     map[string]string{"description": "New description"},
+			}})
+			So(err, ShouldBeNil)
+
+			fetched, err := client.GetAgreement(agreement.ID)
+			So(err, ShouldBeNil)
+			So(fetched.Description, ShouldEqual, "New description")
+			So(fetched.State, ShouldEqual, paypal.AgreementStateActive)
+		})
+	})
+
+	Convey("Storing a card in the vault should mask its number", t, func() {
+		card, err := client.StoreInVault(paypal.VaultRequest{
+			CreditCard:         paypal.CreditCard{Number: "4417119669820331", Type: "visa", ExpireMonth: "11", ExpireYear: "2030"},
+			ExternalCustomerID: "customer-1",
+		})
+
+		So(err, ShouldBeNil)
+		So(card.ID, ShouldNotBeBlank)
+		So(card.Number, ShouldEqual, "xxxxxxxxxxxx0331")
+		So(card.State, ShouldEqual, string(paypal.CreditCardStateOK))
+		So(card.CreateTime, ShouldNotBeNil)
+
+		Convey("It can be fetched and listed by customer", func() {
+			fetched, err := client.GetStoredCreditCard(card.ID)
+			So(err, ShouldBeNil)
+			So(fetched.ExternalCustomerID, ShouldEqual, "customer-1")
+
+			list, err := client.ListStoredCreditCards(map[string]string{"external_customer_id": "customer-1", "page_size": "1"})
+			So(err, ShouldBeNil)
+			So(list.Items, ShouldHaveLength, 1)
+			So(list.TotalItems, ShouldBeGreaterThanOrEqualTo, 1)
+
+			list, err = client.ListStoredCreditCards(map[string]string{"external_customer_id": "customer-2"})
+			So(err, ShouldBeNil)
+			So(list.Items, ShouldBeEmpty)
+		})
+
+		Convey("Its expiry date can be patched", func() {
+			updated, err := client.UpdateStoredCreditCard(card.ID, []paypal.Patch{{
+				Operation: "replace",
+				Path:      "/expire_year",
+				Value:     "2031",
+			}})
+
+			So(err, ShouldBeNil)
+			So(updated.ExpireYear, ShouldEqual, "2031")
+			So(updated.Number, ShouldEqual, card.Number)
+		})
+
+		Convey("Once deleted it can no longer be fetched", func() {
+			So(client.DeleteStoredCreditCard(card.ID), ShouldBeNil)
+
+			_, err := client.GetStoredCreditCard(card.ID)
+			So(paypal.IsNotFound(err), ShouldBeTrue)
+		})
+	})
+
+	Convey("With a webhook subscribed to sale events", t, func() {
+		webhook, err := client.CreateWebhook(receiver.URL+"/hooks/"+time.Now().Format(time.RFC3339Nano),
+			[]paypal.EventType{paypal.EventTypePaymentSaleCompleted, paypal.EventTypePaymentSaleRefunded})
+
+		So(err, ShouldBeNil)
+		So(webhook.ID, ShouldNotBeBlank)
+		Reset(func() { client.DeleteWebhook(webhook.ID) })
+
+		Convey("It should be listed with its event types", func() {
+			webhooks, err := client.ListWebhooks()
+			So(err, ShouldBeNil)
+			So(webhooks, ShouldHaveLength, 1)
+
+			types, err := client.ListWebhookEventTypes(webhook.ID)
+			So(err, ShouldBeNil)
+			So(types, ShouldHaveLength, 2)
+			So(types[0].Status, ShouldEqual, "ENABLED")
+		})
+
+		Convey("Its event types can be replaced", func() {
+			updated, err := client.UpdateWebhook(webhook.ID, []paypal.Patch{{
+				Operation: "replace",
+				Path:      "/event_types",
+				Value:     []paypal.WebhookEventType{{Name: paypal.EventTypeAll}},
+			}})
+
+			So(err, ShouldBeNil)
+			So(updated.EventTypes, ShouldHaveLength, 1)
+			So(updated.EventTypes[0].Name, ShouldEqual, paypal.EventTypeAll)
+		})
+
+		Convey("Subscribing it to an unknown event type should fail", func() {
+			_, err := client.UpdateWebhook(webhook.ID, []paypal.Patch{{
+				Operation: "replace",
+				Path:      "/event_types",
+				Value:     []paypal.WebhookEventType{{Name: "UNKNOWN.EVENT"}},
+			}})
+
+			So(paypal.IsValidationError(err), ShouldBeTrue)
+		})
+
+		Convey("A simulated event can be fetched, listed and resent", func() {
+			event, err := client.SimulateWebhookEvent(paypal.SimulateWebhookEventReq{
+				WebhookID: webhook.ID,
+				EventType: paypal.EventTypePaymentSaleCompleted,
+			})
+			So(err, ShouldBeNil)
+			So(event.ResourceType, ShouldEqual, "sale")
+
+			fetched, err := client.GetWebhookEvent(event.ID)
+			So(err, ShouldBeNil)
+			So(string(fetched.Resource), ShouldEqual, string(event.Resource))
+
+			events, err := client.ListWebhookEvents(map[string]string{
+				"event_type": string(paypal.EventTypePaymentSaleCompleted),
+				"start_time": event.CreateTime.Add(-time.Minute).Format(time.RFC3339),
+			})
+			So(err, ShouldBeNil)
+			So(events.Count, ShouldBeGreaterThanOrEqualTo, 1)
+			So(events.Events[0].ID, ShouldEqual, event.ID)
+
+			_, err = client.ResendWebhookEvent(event.ID, []string{webhook.ID})
+			So(err, ShouldBeNil)
+		})
+	})
+
+	Convey("The available event types should be listed", t, func() {
+		types, err := client.ListAvailableEventTypes()
+
+		So(err, ShouldBeNil)
+		So(types, ShouldContain, paypal.WebhookEventType{Name: paypal.EventTypePaymentSaleCompleted, Description: "A sale completed."})
+	})
+
+	Convey("Users should log in with PayPal", t, func() {
+		noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		}}
+		redirectURI := "https://example.com/callback"
+
+		resp, err := noRedirect.Get(client.AuthorizationURL(redirectURI,
+			[]string{paypal.ScopeOpenID, paypal.ScopeEmail}, "xyz"))
+		So(err, ShouldBeNil)
+		resp.Body.Close()
+		So(resp.StatusCode, ShouldEqual, http.StatusFound)
+		callback, err := resp.Location()
+		So(err, ShouldBeNil)
+		So(callback.Query().Get("state"), ShouldEqual, "xyz")
+		code := callback.Query().Get("code")
+		So(code, ShouldNotBeBlank)
+
+		token, err := client.ExchangeAuthorizationCode(code, redirectURI)
+		So(err, ShouldBeNil)
+		So(token.Token, ShouldNotBeBlank)
+		So(token.RefreshToken, ShouldNotBeBlank)
+		So(token.IDToken, ShouldNotBeBlank)
+		So(token.ExpiresAt, ShouldHappenAfter, time.Now())
+
+		info, err := client.GetUserInfo(token.Token)
+		So(err, ShouldBeNil)
+		So(info.UserID, ShouldEqual, srv.User.UserID)
+		So(info.Email, ShouldEqual, srv.User.Email)
+		So(info.Name, ShouldBeBlank)
+
+		_, err = client.ExchangeAuthorizationCode(code, redirectURI)
+		So(err, ShouldNotBeNil)
+		So(err.(*paypal.ErrorResponse).ErrorCode, ShouldEqual, "invalid_grant")
+
+		refreshed, err := client.RefreshIdentityToken(token.RefreshToken)
+		So(err, ShouldBeNil)
+		So(refreshed.Token, ShouldNotEqual, token.Token)
+		So(refreshed.RefreshToken, ShouldEqual, token.RefreshToken)
+
+		_, err = client.GetUserInfo(refreshed.Token)
+		So(err, ShouldBeNil)
+
+		Convey("User tokens should not grant access to the API", func() {
+			req, err := http.NewRequest("GET", srv.URL+"/payments/payment", nil)
+			So(err, ShouldBeNil)
+			req.Header.Set("Authorization", "Bearer "+refreshed.Token)
+			resp, err := http.DefaultClient.Do(req)
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusUnauthorized)
+
+			So(client.Token.Token, ShouldNotEqual, refreshed.Token)
+			_, err = client.ListPayments(nil)
+			So(err, ShouldBeNil)
+		})
+
+		Convey("The logout URL should redirect the user", func() {
+			resp, err := noRedirect.Get(client.LogoutURL(token.IDToken, redirectURI))
+			So(err, ShouldBeNil)
+			resp.Body.Close()
+			So(resp.StatusCode, ShouldEqual, http.StatusFound)
+			So(resp.Header.Get("Location"), ShouldEqual, redirectURI)
+		})
+	})
+
+	Convey("Invoices should be sent, paid and refunded", t, func() {
+		number, err := client.GenerateInvoiceNumber()
+		So(err, ShouldBeNil)
+		So(number, ShouldNotBeBlank)
+
+		invoice, err := client.CreateInvoice(paypal.Invoice{
+			MerchantInfo: &paypal.MerchantInfo{
+				Email:        "merchant@example.com",
+				BusinessName: "Example Inc",
+				Address: &paypal.Address{
+					Line1:       "1234 Main St.",
+					City:        "Portland",
+					State:       "OR",
+					PostalCode:  "97217",
+					CountryCode: "US",
+				},
+			},
+			BillingInfo: []paypal.BillingInfo{{Email: "buyer@example.com", FirstName: "Jane"}},
+			Items: []paypal.InvoiceItem{{
+				Name:      "Sutures",
+				Quantity:  100,
+				UnitPrice: &paypal.Currency{Currency: "USD", Value: "5.00"},
+				Tax:       &paypal.InvoiceTax{Name: "VAT", Percent: 10},
+			}},
+			Discount:    &paypal.Discount{Percent: 1},
+			PaymentTerm: &paypal.PaymentTerm{TermType: paypal.PaymentTermTypeNet45},
+		})
+		So(err, ShouldBeNil)
+		So(invoice.ID, ShouldNotBeBlank)
+		So(invoice.Number, ShouldEqual, number)
+		So(invoice.Status, ShouldEqual, paypal.InvoiceStatusDraft)
+		So(invoice.TotalAmount, ShouldResemble, &paypal.Currency{Currency: "USD", Value: "545.00"})
+
+		invoice.Note = "Thank you for your business."
+		invoice.Items[0].Quantity = 50
+		updated, err := client.UpdateInvoice(invoice.ID, *invoice)
+		So(err, ShouldBeNil)
+		So(updated.Note, ShouldEqual, invoice.Note)
+		So(updated.TotalAmount.Value, ShouldEqual, "272.50")
+
+		So(client.RemindInvoice(invoice.ID, paypal.InvoiceNotification{}), ShouldNotBeNil)
+		So(client.SendInvoice(invoice.ID, true), ShouldBeNil)
+		So(client.RemindInvoice(invoice.ID, paypal.InvoiceNotification{Subject: "Past due"}), ShouldBeNil)
+		So(client.DeleteInvoice(invoice.ID), ShouldNotBeNil)
+
+		err = client.RecordInvoicePayment(invoice.ID, paypal.InvoicePaymentDetail{
+			Method: paypal.InvoicePaymentMethodCash,
+			Amount: &paypal.Currency{Currency: "USD", Value: "100.00"},
+		})
+		So(err, ShouldBeNil)
+		invoice, err = client.GetInvoice(invoice.ID)
+		So(err, ShouldBeNil)
+		So(invoice.Status, ShouldEqual, paypal.InvoiceStatusPartiallyPaid)
+
+		So(client.RecordInvoicePayment(invoice.ID, paypal.InvoicePaymentDetail{Method: paypal.InvoicePaymentMethodCheck}), ShouldBeNil)
+		invoice, err = client.GetInvoice(invoice.ID)
+		So(err, ShouldBeNil)
+		So(invoice.Status, ShouldEqual, paypal.InvoiceStatusMarkedAsPaid)
+		So(invoice.Payments, ShouldHaveLength, 2)
+		So(invoice.PaidAmount.Other.Value, ShouldEqual, "272.50")
+
+		err = client.RecordInvoiceRefund(invoice.ID, paypal.InvoiceRefundDetail{
+			Amount: &paypal.Currency{Currency: "USD", Value: "300.00"},
+		})
+		So(errors.Is(err, paypal.ErrValidation), ShouldBeTrue)
+		So(client.RecordInvoiceRefund(invoice.ID, paypal.InvoiceRefundDetail{Note: "Returned"}), ShouldBeNil)
+		invoice, err = client.GetInvoice(invoice.ID)
+		So(err, ShouldBeNil)
+		So(invoice.Status, ShouldEqual, paypal.InvoiceStatusMarkedAsRefunded)
+
+		found, err := client.SearchInvoices(paypal.InvoiceSearch{
+			Email:              "buyer@example.com",
+			Number:             invoice.Number,
+			Status:             []paypal.InvoiceStatus{paypal.InvoiceStatusMarkedAsRefunded},
+			TotalCountRequired: true,
+		})
+		So(err, ShouldBeNil)
+		So(found.TotalCount, ShouldEqual, 1)
+		So(found.Invoices[0].ID, ShouldEqual, invoice.ID)
+
+		Convey("Drafts should be deleted, and sent invoices cancelled", func() {
+			draft, err := client.CreateInvoice(paypal.Invoice{
+				MerchantInfo: invoice.MerchantInfo,
+				BillingInfo:  invoice.BillingInfo,
+				Items:        invoice.Items,
+			})
+			So(err, ShouldBeNil)
+			So(draft.Number, ShouldNotEqual, invoice.Number)
+			So(client.DeleteInvoice(draft.ID), ShouldBeNil)
+			_, err = client.GetInvoice(draft.ID)
+			So(errors.Is(err, paypal.ErrNotFound), ShouldBeTrue)
+
+			sent, err := client.CreateInvoice(paypal.Invoice{
+				MerchantInfo: invoice.MerchantInfo,
+				BillingInfo:  invoice.BillingInfo,
+				Items:        invoice.Items,
+			})
+			So(err, ShouldBeNil)
+			So(client.SendInvoice(sent.ID, false), ShouldBeNil)
+			So(client.CancelInvoice(sent.ID, paypal.InvoiceNotification{SendToPayer: true}), ShouldBeNil)
+			sent, err = client.GetInvoice(sent.ID)
+			So(err, ShouldBeNil)
+			So(sent.Status, ShouldEqual, paypal.InvoiceStatusCancelled)
+		})
+
+		Convey("Invalid invoices should be rejected", func() {
+			_, err := client.CreateInvoice(paypal.Invoice{
+				MerchantInfo: invoice.MerchantInfo,
+				Number:       invoice.Number,
+			})
+			So(errors.Is(err, paypal.ErrValidation), ShouldBeTrue)
+		})
+	})
+
+	Convey("Invoices should be created from templates and have QR codes", t, func() {
+		template, err := client.CreateInvoiceTemplate(paypal.InvoiceTemplate{
+			Name:    "Hours " + time.Now().Format(time.RFC3339Nano),
+			Default: true,
+			TemplateData: &paypal.InvoiceTemplateData{
+				MerchantInfo: &paypal.MerchantInfo{Email: "merchant@example.com"},
+				Items: []paypal.InvoiceItem{{
+					Name:      "Consulting",
+					Quantity:  1,
+					UnitPrice: &paypal.Currency{Currency: "USD", Value: "80.00"},
+				}},
+				Note: "Payable within 30 days.",
+			},
+			Settings: []paypal.InvoiceTemplateSetting{{
+				FieldName:         "items.date",
+				DisplayPreference: &paypal.InvoiceTemplateDisplayPreference{Hidden: true},
+			}},
+			UnitOfMeasure: "HOURS",
+		})
+		So(err, ShouldBeNil)
+		So(template.TemplateID, ShouldNotBeBlank)
+		So(template.Custom, ShouldBeTrue)
+
+		template.TemplateData.Items[0].UnitPrice.Value = "90.00"
+		updated, err := client.UpdateInvoiceTemplate(template.TemplateID, *template)
+		So(err, ShouldBeNil)
+		So(updated.TemplateData.Items[0].UnitPrice.Value, ShouldEqual, "90.00")
+
+		got, err := client.GetInvoiceTemplate(template.TemplateID)
+		So(err, ShouldBeNil)
+		So(got, ShouldResemble, updated)
+
+		templates, err := client.ListInvoiceTemplates()
+		So(err, ShouldBeNil)
+		So(templates.Templates, ShouldContain, *updated)
+
+		invoice, err := client.CreateInvoice(paypal.Invoice{
+			TemplateID:  template.TemplateID,
+			BillingInfo: []paypal.BillingInfo{{Email: "buyer@example.com"}},
+		})
+		So(err, ShouldBeNil)
+		So(invoice.MerchantInfo.Email, ShouldEqual, "merchant@example.com")
+		So(invoice.Note, ShouldEqual, "Payable within 30 days.")
+		So(invoice.TotalAmount.Value, ShouldEqual, "90.00")
+
+		_, err = client.GetInvoiceQRCode(invoice.ID, 0, 0)
+		So(errors.Is(err, paypal.ErrNotFound), ShouldBeFalse)
+		So(err, ShouldNotBeNil)
+		So(client.SendInvoice(invoice.ID, false), ShouldBeNil)
+
+		code, err := client.GetInvoiceQRCode(invoice.ID, 200, 150)
+		So(err, ShouldBeNil)
+		img, err := png.Decode(bytes.NewReader(code))
+		So(err, ShouldBeNil)
+		So(img.Bounds().Dx(), ShouldEqual, 200)
+		So(img.Bounds().Dy(), ShouldEqual, 150)
+
+		var buf bytes.Buffer
+		So(client.WriteInvoiceQRCode(&buf, invoice.ID, 200, 150), ShouldBeNil)
+		So(buf.Bytes(), ShouldResemble, code)
+
+		So(client.DeleteInvoiceTemplate(template.TemplateID), ShouldBeNil)
+		_, err = client.GetInvoiceTemplate(template.TemplateID)
+		So(errors.Is(err, paypal.ErrNotFound), ShouldBeTrue)
+	})
+
+	Convey("Web profiles should brand payments", t, func() {
+		profile, err := client.CreateWebProfile(paypal.WebProfile{
+			Name: "Digital goods " + time.Now().Format(time.RFC3339Nano),
+			FlowConfig: &paypal.FlowConfig{
+				LandingPageType: paypal.LandingPageTypeBilling,
+				UserAction:      paypal.UserActionCommit,
+			},
+			InputFields:  &paypal.InputFields{NoShipping: paypal.NoShippingHide},
+			Presentation: &paypal.Presentation{BrandName: "Example Inc", LocaleCode: "US"},
+		})
+		So(err, ShouldBeNil)
+		So(profile.ID, ShouldNotBeBlank)
+		So(profile.InputFields.NoShipping, ShouldEqual, paypal.NoShippingHide)
+
+		got, err := client.GetWebProfile(profile.ID)
+		So(err, ShouldBeNil)
+		So(got, ShouldResemble, profile)
+
+		profile.Presentation.LogoImage = "https://example.com/logo.png"
+		So(client.UpdateWebProfile(profile.ID, *profile), ShouldBeNil)
+		So(client.PatchWebProfile(profile.ID, []paypal.Patch{
+			{Operation: "replace", Path: "/presentation/brand_name", Value: "Example"},
+		}), ShouldBeNil)
+		got, err = client.GetWebProfile(profile.ID)
+		So(err, ShouldBeNil)
+		So(got.Presentation.LogoImage, ShouldEqual, "https://example.com/logo.png")
+		So(got.Presentation.BrandName, ShouldEqual, "Example")
+
+		profiles, err := client.ListWebProfiles()
+		So(err, ShouldBeNil)
+		So(profiles, ShouldContain, *got)
+
+		p := creditCardPayment(paypal.PaymentIntentSale, "5.00")
+		p.Payer = &paypal.Payer{PaymentMethod: paypal.PaymentMethodPaypal}
+		p.RedirectURLs = &paypal.RedirectURLs{ReturnURL: "https://example.com/ok", CancelURL: "https://example.com/cancel"}
+		p.ExperienceProfileID = profile.ID
+		created, err := client.CreatePayment(p)
+		So(err, ShouldBeNil)
+		So(created.ExperienceProfileID, ShouldEqual, profile.ID)
+
+		_, err = client.CreateWebProfile(paypal.WebProfile{Name: got.Name})
+		So(errors.Is(err, paypal.ErrValidation), ShouldBeTrue)
+		So(client.PatchWebProfile(profile.ID, []paypal.Patch{
+			{Operation: "replace", Path: "/input_fields/no_shipping", Value: 3},
+		}), ShouldNotBeNil)
+
+		So(client.DeleteWebProfile(profile.ID), ShouldBeNil)
+		_, err = client.GetWebProfile(profile.ID)
+		So(errors.Is(err, paypal.ErrNotFound), ShouldBeTrue)
+		_, err = client.CreatePayment(p)
+		So(errors.Is(err, paypal.ErrValidation), ShouldBeTrue)
+	})
+
+	Convey("Payouts should be sent to recipients", t, func() {
+		srv.AddUnclaimedReceiver("unclaimed@example.com")
+		batchID := "batch-" + time.Now().Format(time.RFC3339Nano)
+		payout := paypal.Payout{
+			SenderBatchHeader: &paypal.SenderBatchHeader{
+				SenderBatchID: batchID,
+				EmailSubject:  "You have a payout",
+			},
+			Items: []paypal.PayoutItem{
+				{Amount: &paypal.Currency{Currency: "USD", Value: "10.00"}, Receiver: "seller@example.com"},
+				{RecipientType: paypal.PayoutRecipientTypePhone, Amount: &paypal.Currency{Currency: "USD", Value: "2.50"}, Receiver: "4085551234"},
+				{RecipientType: paypal.PayoutRecipientTypePaypalID, Amount: &paypal.Currency{Currency: "USD", Value: "1.00"}, Receiver: "PAYPAL-ID-1"},
+				{Amount: &paypal.Currency{Currency: "USD", Value: "0.50"}, Receiver: "unclaimed@example.com"},
+			},
+		}
+
+		batch, err := client.CreatePayout(payout, true)
+		So(err, ShouldBeNil)
+		So(batch.BatchHeader.BatchStatus, ShouldEqual, paypal.PayoutBatchStatusSuccess)
+		So(batch.BatchHeader.Amount.Value, ShouldEqual, "14.00")
+		So(batch.Items, ShouldHaveLength, 4)
+		So(batch.Items[0].PayoutItem.RecipientType, ShouldEqual, paypal.PayoutRecipientTypeEmail)
+		So(batch.Items[0].TransactionStatus, ShouldEqual, paypal.PayoutItemStatusSuccess)
+		So(batch.Items[3].TransactionStatus, ShouldEqual, paypal.PayoutItemStatusUnclaimed)
+
+		page, err := client.GetPayoutBatch(batch.BatchHeader.PayoutBatchID, map[string]string{
+			"page": "2", "page_size": "1", "total_required": "true",
+		})
+		So(err, ShouldBeNil)
+		So(page.TotalItems, ShouldEqual, 4)
+		So(page.TotalPages, ShouldEqual, 4)
+		So(page.Items, ShouldHaveLength, 1)
+		So(page.Items[0].PayoutItem.Receiver, ShouldEqual, "4085551234")
+
+		item, err := client.GetPayoutItem(batch.Items[2].PayoutItemID)
+		So(err, ShouldBeNil)
+		So(item.PayoutItem.Receiver, ShouldEqual, "PAYPAL-ID-1")
+		So(item.PayoutBatchID, ShouldEqual, batch.BatchHeader.PayoutBatchID)
+
+		cancelled, err := client.CancelPayoutItem(batch.Items[3].PayoutItemID)
+		So(err, ShouldBeNil)
+		So(cancelled.TransactionStatus, ShouldEqual, paypal.PayoutItemStatusReturned)
+		_, err = client.CancelPayoutItem(batch.Items[0].PayoutItemID)
+		So(err, ShouldNotBeNil)
+
+		_, err = client.CreatePayout(payout, false)
+		So(err, ShouldNotBeNil)
+
+		payout.SenderBatchHeader.SenderBatchID = batchID + "-async"
+		pending, err := client.CreatePayout(payout, false)
+		So(err, ShouldBeNil)
+		So(pending.BatchHeader.BatchStatus, ShouldEqual, paypal.PayoutBatchStatusPending)
+		So(pending.Items, ShouldBeEmpty)
+		processed, err := client.GetPayoutBatch(pending.BatchHeader.PayoutBatchID, nil)
+		So(err, ShouldBeNil)
+		So(processed.BatchHeader.BatchStatus, ShouldEqual, paypal.PayoutBatchStatusSuccess)
+		So(processed.Items, ShouldHaveLength, 4)
+
+		payout.SenderBatchHeader.SenderBatchID = batchID + "-invalid"
+		payout.Items[1].Receiver = "not a phone"
+		_, err = client.CreatePayout(payout, true)
+		So(errors.Is(err, paypal.ErrValidation), ShouldBeTrue)
+		_, err = client.GetPayoutItem("PI-UNKNOWN")
+		So(errors.Is(err, paypal.ErrNotFound), ShouldBeTrue)
+	})
+
+	Convey("Requests should succeed after the access token expired", t, func() {
+		_, err := client.ListPayments(nil)
+		So(err, ShouldBeNil)
+
+		srv.ExpireTokens()
+
+		_, err = client.ListPayments(nil)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Unknown resources should be reported as not found", t, func() {
+		_, err := client.GetSale("UNKNOWN")
+
+		So(paypal.IsNotFound(err), ShouldBeTrue)
+	})
+}
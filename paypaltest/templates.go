@@ -0,0 +1,237 @@
+package paypaltest
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"net/http"
+	"strconv"
+
+	"github.com/leebenson/paypal"
+)
+
+func (s *Server) handleCreateInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	var t paypal.InvoiceTemplate
+	if !decode(w, r, &t) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if details := s.validateInvoiceTemplate(&t, ""); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	t.TemplateID = s.newID("TEMP-")
+	t.Custom = true
+	t.Links = []paypal.Links{
+		s.link("self", "GET", "/invoicing/templates/"+t.TemplateID),
+	}
+	s.storeInvoiceTemplate(&t)
+	s.templateOrder = append(s.templateOrder, t.TemplateID)
+
+	writeJSON(w, http.StatusCreated, t)
+}
+
+// validateInvoiceTemplate returns the issues with a template to create or
+// to replace the one with id. Must be called with s.mu held
+func (s *Server) validateInvoiceTemplate(t *paypal.InvoiceTemplate, id string) []paypal.ErrorDetail {
+	if t.Name == "" {
+		return []paypal.ErrorDetail{{Field: "name", Issue: "Required field missing"}}
+	}
+	for _, other := range s.templates {
+		if other.Name == t.Name && other.TemplateID != id {
+			return []paypal.ErrorDetail{{Field: "name", Issue: "Template name already in use"}}
+		}
+	}
+
+	return nil
+}
+
+// storeInvoiceTemplate stores t, which becomes the only default template if
+// it is one. Must be called with s.mu held
+func (s *Server) storeInvoiceTemplate(t *paypal.InvoiceTemplate) {
+	if t.Default {
+		for _, other := range s.templates {
+			other.Default = false
+		}
+	}
+	s.templates[t.TemplateID] = t
+}
+
+func (s *Server) handleListInvoiceTemplates(w http.ResponseWriter, r *http.Request) {
+	all := r.URL.Query().Get("fields") == "all"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := paypal.ListInvoiceTemplatesResp{Templates: []paypal.InvoiceTemplate{}}
+	for _, id := range s.templateOrder {
+		t := *s.templates[id]
+		if !all {
+			t.TemplateData, t.Settings = nil, nil
+		}
+		resp.Templates = append(resp.Templates, t)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.templates[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) handleUpdateInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	var update paypal.InvoiceTemplate
+	if !decode(w, r, &update) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.templates[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if details := s.validateInvoiceTemplate(&update, t.TemplateID); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	update.TemplateID = t.TemplateID
+	update.Custom = t.Custom
+	update.Links = t.Links
+	s.storeInvoiceTemplate(&update)
+
+	writeJSON(w, http.StatusOK, update)
+}
+
+func (s *Server) handleDeleteInvoiceTemplate(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.templates[id]; !ok {
+		writeNotFound(w)
+		return
+	}
+
+	delete(s.templates, id)
+	for i, other := range s.templateOrder {
+		if other == id {
+			s.templateOrder = append(s.templateOrder[:i], s.templateOrder[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyInvoiceTemplate fills the fields of inv left unset with the data of
+// its template. Must be called with s.mu held
+func (s *Server) applyInvoiceTemplate(inv *paypal.Invoice) bool {
+	if inv.TemplateID == "" {
+		return true
+	}
+	t, ok := s.templates[inv.TemplateID]
+	if !ok {
+		return false
+	}
+	if t.TemplateData == nil {
+		return true
+	}
+
+	data, err := json.Marshal(t.TemplateData)
+	if err != nil {
+		return false
+	}
+	fields, err := json.Marshal(inv)
+	if err != nil {
+		return false
+	}
+
+	var merged paypal.Invoice
+	if json.Unmarshal(data, &merged) != nil || json.Unmarshal(fields, &merged) != nil {
+		return false
+	}
+	*inv = merged
+
+	return true
+}
+
+func (s *Server) handleInvoiceQRCode(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	size := func(name string) (int, bool) {
+		if q.Get(name) == "" {
+			return 500, true
+		}
+		n, err := strconv.Atoi(q.Get(name))
+		return n, err == nil && n > 0 && n <= 1000
+	}
+	width, okWidth := size("width")
+	height, okHeight := size("height")
+	if !okWidth || !okHeight {
+		field := "width"
+		if okWidth {
+			field = "height"
+		}
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: field, Issue: "Value is invalid"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	inv := s.invoice(w, r)
+	if inv == nil || !invoiceStatusIn(w, inv, "qr-code",
+		paypal.InvoiceStatusSent, paypal.InvoiceStatusPartiallyPaid, paypal.InvoiceStatusPaymentPending) {
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, qrCode(inv.ID, width, height)); err != nil {
+		writeError(w, http.StatusInternalServerError, paypal.ErrorNameInternalServerError, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]string{"image": base64.StdEncoding.EncodeToString(buf.Bytes())})
+}
+
+// qrCode draws an image standing for the QR code of the invoice with id: a
+// grid of modules derived from the ID, which is not scannable but differs
+// between invoices
+func qrCode(id string, width, height int) image.Image {
+	const modules = 25
+
+	img := image.NewGray(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mx, my := x*modules/width, y*modules/height
+			b := id[(mx*modules+my)%len(id)]
+			if (int(b)+mx*my)%2 == 0 {
+				img.SetGray(x, y, color.Gray{})
+			} else {
+				img.SetGray(x, y, color.Gray{Y: 0xff})
+			}
+		}
+	}
+
+	return img
+}
@@ -0,0 +1,144 @@
+package paypaltest
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+func (s *Server) handleStoreCreditCard(w http.ResponseWriter, r *http.Request) {
+	var req paypal.VaultRequest
+	if !decode(w, r, &req) {
+		return
+	}
+	if !luhn(req.Number) {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "number", Issue: "Value is invalid"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card := &paypal.VaultResponse{
+		VaultRequest: req,
+		CreateTime:   now(),
+		UpdateTime:   now(),
+		State:        string(paypal.CreditCardStateOK),
+		ValidUntil:   time.Now().UTC().AddDate(3, 0, 0).Format(time.RFC3339),
+	}
+	card.ID = s.newID("CARD-")
+	card.Number = maskNumber(req.Number)
+	card.CVV2 = ""
+	card.Links = []paypal.Links{
+		s.link("self", "GET", "/vault/credit-cards/"+card.ID),
+		s.link("delete", "DELETE", "/vault/credit-cards/"+card.ID),
+		s.link("patch", "PATCH", "/vault/credit-cards/"+card.ID),
+	}
+	s.cards[card.ID] = card
+	s.cardOrder = append(s.cardOrder, card.ID)
+
+	writeJSON(w, http.StatusCreated, card)
+}
+
+func (s *Server) handleGetCreditCard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.cards[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, card)
+}
+
+func (s *Server) handleListCreditCards(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	pageSize, err := strconv.Atoi(q.Get("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var matching []paypal.VaultResponse
+	for _, id := range s.cardOrder {
+		card, ok := s.cards[id]
+		if !ok ||
+			q.Get("merchant_id") != "" && card.MerchantID != q.Get("merchant_id") ||
+			q.Get("external_card_id") != "" && card.ExternalCardID != q.Get("external_card_id") ||
+			q.Get("external_customer_id") != "" && card.ExternalCustomerID != q.Get("external_customer_id") {
+			continue
+		}
+		matching = append(matching, *card)
+	}
+
+	resp := paypal.ListStoredCreditCardsResp{Items: []paypal.VaultResponse{}}
+	if start := (page - 1) * pageSize; start < len(matching) {
+		end := start + pageSize
+		if end > len(matching) {
+			end = len(matching)
+		}
+		resp.Items = matching[start:end]
+	}
+	if q.Get("total_required") != "false" {
+		resp.TotalItems = len(matching)
+		resp.TotalPages = (len(matching) + pageSize - 1) / pageSize
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePatchCreditCard(w http.ResponseWriter, r *http.Request) {
+	var patches []paypal.Patch
+	if !decode(w, r, &patches) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	card, ok := s.cards[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	var patched paypal.VaultResponse
+	if err := applyPatch(&patched, card, patches); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "patch_request", Issue: err.Error()})
+		return
+	}
+	patched.ID = card.ID
+	patched.Number = card.Number
+	patched.CreateTime = card.CreateTime
+	patched.UpdateTime = now()
+	patched.Links = card.Links
+	s.cards[card.ID] = &patched
+
+	writeJSON(w, http.StatusOK, patched)
+}
+
+func (s *Server) handleDeleteCreditCard(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.cards[id]; !ok {
+		writeNotFound(w)
+		return
+	}
+	delete(s.cards, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,128 @@
+package paypaltest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/leebenson/paypal"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWebhookHandler(t *testing.T) {
+	srv := NewServer()
+	defer srv.Close()
+
+	client := srv.Client()
+
+	var (
+		mu      sync.Mutex
+		handler *paypal.WebhookHandler
+	)
+	statuses := make(chan int, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		h := handler
+		mu.Unlock()
+
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		w.WriteHeader(rec.Code)
+		statuses <- rec.Code
+	}))
+	defer receiver.Close()
+
+	status := func() int {
+		select {
+		case code := <-statuses:
+			return code
+		case <-time.After(5 * time.Second):
+			return 0
+		}
+	}
+
+	Convey("With a webhook subscribed to sale events", t, func() {
+		webhook, err := client.CreateWebhook(receiver.URL, []paypal.EventType{paypal.EventTypePaymentSaleCompleted})
+		So(err, ShouldBeNil)
+		Reset(func() { client.DeleteWebhook(webhook.ID) })
+
+		fetches := 0
+		verifier := &paypal.OfflineVerifier{
+			Roots: srv.CertPool(),
+			FetchCert: func(ctx context.Context, certURL string) ([]byte, error) {
+				fetches++
+				return srv.FetchCert(ctx, certURL)
+			},
+		}
+
+		sales := make(chan *paypal.Sale, 10)
+		setHandler := func(webhookID string, v paypal.WebhookVerifier) {
+			h := paypal.NewWebhookHandler(webhookID, v)
+			h.HandleSale(paypal.EventTypePaymentSaleCompleted, func(ctx context.Context, e *paypal.WebhookEvent, s *paypal.Sale) error {
+				sales <- s
+				return nil
+			})
+
+			mu.Lock()
+			handler = h
+			mu.Unlock()
+		}
+		simulate := func() {
+			_, err := client.SimulateWebhookEvent(paypal.SimulateWebhookEventReq{
+				WebhookID: webhook.ID,
+				EventType: paypal.EventTypePaymentSaleCompleted,
+			})
+			So(err, ShouldBeNil)
+		}
+
+		Convey("Notifications verified offline should be dispatched with their sale", func() {
+			setHandler(webhook.ID, verifier)
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusOK)
+			sale := <-sales
+			So(sale.ID, ShouldNotBeBlank)
+			So(sale.Amount.Total, ShouldEqual, "7.47")
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusOK)
+			So(fetches, ShouldEqual, 1)
+		})
+
+		Convey("Notifications verified through the API should be dispatched", func() {
+			setHandler(webhook.ID, paypal.APIVerifier{Client: client})
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusOK)
+			So(<-sales, ShouldNotBeNil)
+		})
+
+		Convey("Notifications for another webhook should be rejected", func() {
+			setHandler("WH-OTHER", verifier)
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusUnauthorized)
+			So(sales, ShouldBeEmpty)
+
+			setHandler("WH-OTHER", paypal.APIVerifier{Client: client})
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusUnauthorized)
+			So(sales, ShouldBeEmpty)
+		})
+
+		Convey("A failing callback should make the handler respond with an error", func() {
+			setHandler(webhook.ID, verifier)
+			handler.Handle(paypal.EventTypeAll, func(ctx context.Context, e *paypal.WebhookEvent) error {
+				return errors.New("database unavailable")
+			})
+
+			simulate()
+			So(status(), ShouldEqual, http.StatusInternalServerError)
+		})
+	})
+}
@@ -0,0 +1,374 @@
+package paypaltest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/leebenson/paypal"
+)
+
+// maxWebhooks is the number of webhooks an application can have
+const maxWebhooks = 10
+
+// eventTypes are the event types webhooks can subscribe to, with the type
+// of the resource their events are about
+var eventTypes = []struct {
+	paypal.WebhookEventType
+	resourceType string
+}{
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentAuthorizationCreated, Description: "A payment authorization was created."}, "authorization"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentAuthorizationVoided, Description: "A payment authorization was voided."}, "authorization"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentCaptureCompleted, Description: "A payment capture completed."}, "capture"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentCaptureRefunded, Description: "A merchant refunded a payment capture."}, "capture"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentSaleCompleted, Description: "A sale completed."}, "sale"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentSaleDenied, Description: "The state of a sale changed from pending to denied."}, "sale"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentSalePending, Description: "The state of a sale changed to pending."}, "sale"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentSaleRefunded, Description: "A merchant refunded a sale."}, "refund"},
+	{paypal.WebhookEventType{Name: paypal.EventTypePaymentSaleReversed, Description: "PayPal reversed a sale."}, "refund"},
+	{paypal.WebhookEventType{Name: paypal.EventTypeBillingPlanCreated, Description: "A billing plan was created."}, "plan"},
+	{paypal.WebhookEventType{Name: paypal.EventTypeBillingSubscriptionCreated, Description: "A billing agreement was created."}, "Agreement"},
+	{paypal.WebhookEventType{Name: paypal.EventTypeBillingSubscriptionCancelled, Description: "A billing agreement was canceled."}, "Agreement"},
+	{paypal.WebhookEventType{Name: paypal.EventTypeVaultCreditCardCreated, Description: "A credit card was stored in the vault."}, "credit_card"},
+	{paypal.WebhookEventType{Name: paypal.EventTypeVaultCreditCardDeleted, Description: "A credit card was deleted from the vault."}, "credit_card"},
+}
+
+// resourceType returns the type of the resource of the events of
+// eventType, and false if eventType is unknown
+func resourceType(eventType paypal.EventType) (string, bool) {
+	for _, t := range eventTypes {
+		if t.Name == eventType {
+			return t.resourceType, true
+		}
+	}
+
+	return "", false
+}
+
+// validateWebhook returns the issues with a webhook to create or update.
+// Must be called with s.mu held
+func (s *Server) validateWebhook(wh *paypal.Webhook) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+	if u, err := url.Parse(wh.URL); err != nil || !u.IsAbs() {
+		details = append(details, paypal.ErrorDetail{Field: "url", Issue: "Value is invalid"})
+	}
+	if len(wh.EventTypes) == 0 {
+		details = append(details, paypal.ErrorDetail{Field: "event_types", Issue: "Required field missing"})
+	}
+	for _, t := range wh.EventTypes {
+		if _, ok := resourceType(t.Name); !ok && t.Name != paypal.EventTypeAll {
+			details = append(details, paypal.ErrorDetail{Field: "event_types", Issue: "Event type " + string(t.Name) + " is not supported"})
+		}
+	}
+
+	return details
+}
+
+// subscribedEventTypes returns the event types a webhook is subscribed to,
+// expanding EventTypeAll into every event type
+func subscribedEventTypes(wh *paypal.Webhook) []paypal.WebhookEventType {
+	var types []paypal.WebhookEventType
+	for _, t := range wh.EventTypes {
+		if t.Name == paypal.EventTypeAll {
+			return []paypal.WebhookEventType{{Name: paypal.EventTypeAll, Description: "ALL", Status: "ENABLED"}}
+		}
+		for _, known := range eventTypes {
+			if known.Name == t.Name {
+				types = append(types, paypal.WebhookEventType{Name: known.Name, Description: known.Description, Status: "ENABLED"})
+			}
+		}
+	}
+
+	return types
+}
+
+func (s *Server) handleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var wh paypal.Webhook
+	if !decode(w, r, &wh) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if details := s.validateWebhook(&wh); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided", details...)
+		return
+	}
+	if len(s.webhooks) >= maxWebhooks {
+		writeError(w, http.StatusBadRequest, "WEBHOOK_NUMBER_LIMIT_EXCEEDED", "Maximum number of webhooks reached")
+		return
+	}
+	for _, other := range s.webhooks {
+		if other.URL == wh.URL {
+			writeError(w, http.StatusBadRequest, "WEBHOOK_URL_ALREADY_EXISTS", "Webhook URL already exists")
+			return
+		}
+	}
+
+	wh.ID = s.newID("WH-")
+	wh.EventTypes = subscribedEventTypes(&wh)
+	wh.Links = []paypal.Links{
+		s.link("self", "GET", "/notifications/webhooks/"+wh.ID),
+		s.link("update", "PATCH", "/notifications/webhooks/"+wh.ID),
+		s.link("delete", "DELETE", "/notifications/webhooks/"+wh.ID),
+	}
+	s.webhooks[wh.ID] = &wh
+	s.webhookOrder = append(s.webhookOrder, wh.ID)
+
+	writeJSON(w, http.StatusCreated, wh)
+}
+
+func (s *Server) handleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := paypal.ListWebhooksResp{Webhooks: []paypal.Webhook{}}
+	for _, id := range s.webhookOrder {
+		if wh, ok := s.webhooks[id]; ok {
+			resp.Webhooks = append(resp.Webhooks, *wh)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetWebhook(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wh, ok := s.webhooks[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, wh)
+}
+
+func (s *Server) handlePatchWebhook(w http.ResponseWriter, r *http.Request) {
+	var patches []paypal.Patch
+	if !decode(w, r, &patches) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wh, ok := s.webhooks[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	var patched paypal.Webhook
+	if err := applyPatch(&patched, wh, patches); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided",
+			paypal.ErrorDetail{Field: "patch_request", Issue: err.Error()})
+		return
+	}
+	if details := s.validateWebhook(&patched); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided", details...)
+		return
+	}
+	patched.ID = wh.ID
+	patched.EventTypes = subscribedEventTypes(&patched)
+	patched.Links = wh.Links
+	s.webhooks[wh.ID] = &patched
+
+	writeJSON(w, http.StatusOK, patched)
+}
+
+func (s *Server) handleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.webhooks[id]; !ok {
+		writeNotFound(w)
+		return
+	}
+	delete(s.webhooks, id)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleListWebhookEventTypes(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wh, ok := s.webhooks[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, paypal.ListWebhookEventTypesResp{EventTypes: wh.EventTypes})
+}
+
+func (s *Server) handleListAvailableEventTypes(w http.ResponseWriter, r *http.Request) {
+	resp := paypal.ListWebhookEventTypesResp{}
+	for _, t := range eventTypes {
+		resp.EventTypes = append(resp.EventTypes, t.WebhookEventType)
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleSimulateWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	var req paypal.SimulateWebhookEventReq
+	if !decode(w, r, &req) {
+		return
+	}
+	resType, ok := resourceType(req.EventType)
+	if !ok {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided",
+			paypal.ErrorDetail{Field: "event_type", Issue: "Event type is not supported"})
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var deliveries []delivery
+	switch {
+	case req.WebhookID != "":
+		wh, ok := s.webhooks[req.WebhookID]
+		if !ok {
+			writeNotFound(w)
+			return
+		}
+		deliveries = []delivery{{url: wh.URL, webhookID: wh.ID}}
+	case req.URL != "":
+		deliveries = []delivery{{url: req.URL}}
+	default:
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided",
+			paypal.ErrorDetail{Field: "webhook_id", Issue: "Either webhook_id or url is required"})
+		return
+	}
+
+	resource, _ := json.Marshal(map[string]interface{}{
+		"id":             s.newID(""),
+		"state":          "completed",
+		"amount":         paypal.Amount{Currency: "USD", Total: "7.47"},
+		"parent_payment": s.newID("PAY-"),
+		"create_time":    now(),
+		"update_time":    now(),
+	})
+	e := &paypal.WebhookEvent{
+		ID:           s.newID("WH-EVENT-"),
+		CreateTime:   now(),
+		ResourceType: resType,
+		EventVersion: "1.0",
+		EventType:    req.EventType,
+		Summary:      "Simulated " + string(req.EventType) + " event",
+		Resource:     resource,
+		Status:       "PENDING",
+	}
+	e.Links = []paypal.Links{
+		s.link("self", "GET", "/notifications/webhooks-events/"+e.ID),
+		s.link("resend", "POST", "/notifications/webhooks-events/"+e.ID+"/resend"),
+	}
+	s.events[e.ID] = e
+	s.eventOrder = append(s.eventOrder, e.ID)
+	if req.WebhookID != "" {
+		s.deliveries[e.ID] = []string{req.WebhookID}
+	}
+	s.deliver(*e, deliveries)
+
+	writeJSON(w, http.StatusAccepted, e)
+}
+
+func (s *Server) handleListWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	var start, end time.Time
+	for _, f := range []struct {
+		name string
+		t    *time.Time
+	}{{"start_time", &start}, {"end_time", &end}} {
+		if v := q.Get(f.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided",
+					paypal.ErrorDetail{Field: f.name, Issue: "Value is invalid"})
+				return
+			}
+			*f.t = t
+		}
+	}
+	pageSize, err := strconv.Atoi(q.Get("page_size"))
+	if err != nil || pageSize < 1 {
+		pageSize = 10
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	resp := paypal.ListWebhookEventsResp{Events: []paypal.WebhookEvent{}}
+	for i := len(s.eventOrder) - 1; i >= 0 && len(resp.Events) < pageSize; i-- {
+		e := s.events[s.eventOrder[i]]
+		if !start.IsZero() && e.CreateTime.Before(start) ||
+			!end.IsZero() && e.CreateTime.After(end) ||
+			q.Get("event_type") != "" && string(e.EventType) != q.Get("event_type") {
+			continue
+		}
+		resp.Events = append(resp.Events, *e)
+	}
+	resp.Count = len(resp.Events)
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleGetWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.events[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) handleResendWebhookEvent(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		WebhookIDs []string `json:"webhook_ids"`
+	}
+	if !decode(w, r, &req) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.events[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	for _, id := range req.WebhookIDs {
+		if _, ok := s.webhooks[id]; !ok {
+			writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid data provided",
+				paypal.ErrorDetail{Field: "webhook_ids", Issue: "Webhook " + id + " does not exist"})
+			return
+		}
+	}
+	if len(req.WebhookIDs) == 0 {
+		req.WebhookIDs = s.deliveries[e.ID]
+	}
+	var deliveries []delivery
+	for _, id := range req.WebhookIDs {
+		if wh, ok := s.webhooks[id]; ok {
+			deliveries = append(deliveries, delivery{url: wh.URL, webhookID: id})
+		}
+	}
+	s.deliveries[e.ID] = req.WebhookIDs
+	s.deliver(*e, deliveries)
+	e.Status = "PENDING"
+
+	writeJSON(w, http.StatusAccepted, e)
+}
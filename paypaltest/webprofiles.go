@@ -0,0 +1,171 @@
+package paypaltest
+
+import (
+	"net/http"
+
+	"github.com/leebenson/paypal"
+)
+
+func (s *Server) handleCreateWebProfile(w http.ResponseWriter, r *http.Request) {
+	var p paypal.WebProfile
+	if !decode(w, r, &p) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if details := s.validateWebProfile(&p, ""); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+
+	p.ID = s.newID("XP-")
+	s.webProfiles[p.ID] = &p
+	s.webProfileOrder = append(s.webProfileOrder, p.ID)
+
+	writeJSON(w, http.StatusCreated, map[string]string{"id": p.ID})
+}
+
+// validateWebProfile returns the issues with a profile to create or to
+// replace the one with id. Must be called with s.mu held
+func (s *Server) validateWebProfile(p *paypal.WebProfile, id string) []paypal.ErrorDetail {
+	var details []paypal.ErrorDetail
+	if p.Name == "" || len(p.Name) > 50 {
+		details = append(details, paypal.ErrorDetail{Field: "name", Issue: "Value is invalid"})
+	}
+	for _, other := range s.webProfiles {
+		if other.Name == p.Name && other.ID != id {
+			details = append(details, paypal.ErrorDetail{Field: "name", Issue: "A profile with this name already exists"})
+			break
+		}
+	}
+	if fc := p.FlowConfig; fc != nil {
+		switch fc.LandingPageType {
+		case "", paypal.LandingPageTypeBilling, paypal.LandingPageTypeLogin:
+		default:
+			details = append(details, paypal.ErrorDetail{Field: "flow_config.landing_page_type", Issue: "Value is invalid"})
+		}
+		if fc.UserAction != "" && fc.UserAction != paypal.UserActionCommit {
+			details = append(details, paypal.ErrorDetail{Field: "flow_config.user_action", Issue: "Value is invalid"})
+		}
+		switch fc.ReturnURIHTTPMethod {
+		case "", "GET", "POST":
+		default:
+			details = append(details, paypal.ErrorDetail{Field: "flow_config.return_uri_http_method", Issue: "Value is invalid"})
+		}
+	}
+	if in := p.InputFields; in != nil {
+		if in.NoShipping < paypal.NoShippingDisplay || in.NoShipping > paypal.NoShippingFromAccount {
+			details = append(details, paypal.ErrorDetail{Field: "input_fields.no_shipping", Issue: "Value is invalid"})
+		}
+		if in.AddressOverride < 0 || in.AddressOverride > 1 {
+			details = append(details, paypal.ErrorDetail{Field: "input_fields.address_override", Issue: "Value is invalid"})
+		}
+	}
+
+	return details
+}
+
+func (s *Server) handleListWebProfiles(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	profiles := []paypal.WebProfile{}
+	for _, id := range s.webProfileOrder {
+		if p := s.webProfiles[id]; !p.Temporary {
+			profiles = append(profiles, *p)
+		}
+	}
+
+	writeJSON(w, http.StatusOK, profiles)
+}
+
+func (s *Server) handleGetWebProfile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.webProfiles[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, p)
+}
+
+func (s *Server) handleUpdateWebProfile(w http.ResponseWriter, r *http.Request) {
+	var update paypal.WebProfile
+	if !decode(w, r, &update) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.webProfiles[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+	if details := s.validateWebProfile(&update, p.ID); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+	update.ID = p.ID
+	s.webProfiles[p.ID] = &update
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handlePatchWebProfile(w http.ResponseWriter, r *http.Request) {
+	var patches []paypal.Patch
+	if !decode(w, r, &patches) {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.webProfiles[r.PathValue("id")]
+	if !ok {
+		writeNotFound(w)
+		return
+	}
+
+	var patched paypal.WebProfile
+	if err := applyPatch(&patched, p, patches); err != nil {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details",
+			paypal.ErrorDetail{Field: "patch_request", Issue: err.Error()})
+		return
+	}
+	if details := s.validateWebProfile(&patched, p.ID); len(details) > 0 {
+		writeError(w, http.StatusBadRequest, paypal.ErrorNameValidation, "Invalid request - see details", details...)
+		return
+	}
+	patched.ID = p.ID
+	s.webProfiles[p.ID] = &patched
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleDeleteWebProfile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := r.PathValue("id")
+	if _, ok := s.webProfiles[id]; !ok {
+		writeNotFound(w)
+		return
+	}
+
+	delete(s.webProfiles, id)
+	for i, other := range s.webProfileOrder {
+		if other == id {
+			s.webProfileOrder = append(s.webProfileOrder[:i], s.webProfileOrder[i+1:]...)
+			break
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,216 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/payments.billing-plans/
+
+var (
+	PlanTypeFixed    PlanType = "FIXED"
+	PlanTypeInfinite PlanType = "INFINITE"
+
+	PlanStateCreated  PlanState = "CREATED"
+	PlanStateActive   PlanState = "ACTIVE"
+	PlanStateInactive PlanState = "INACTIVE"
+	PlanStateDeleted  PlanState = "DELETED"
+
+	PaymentDefinitionTypeTrial   PaymentDefinitionType = "TRIAL"
+	PaymentDefinitionTypeRegular PaymentDefinitionType = "REGULAR"
+
+	FrequencyDay   Frequency = "DAY"
+	FrequencyWeek  Frequency = "WEEK"
+	FrequencyMonth Frequency = "MONTH"
+	FrequencyYear  Frequency = "YEAR"
+
+	ChargeModelTypeShipping ChargeModelType = "SHIPPING"
+	ChargeModelTypeTax      ChargeModelType = "TAX"
+)
+
+type (
+	PlanType              string
+	PlanState             string
+	PaymentDefinitionType string
+	Frequency             string
+	ChargeModelType       string
+
+	// Plan maps to plan object
+	Plan struct {
+		ID                  string               `json:"id,omitempty"`
+		Name                string               `json:"name,omitempty"`
+		Description         string               `json:"description,omitempty"`
+		Type                PlanType             `json:"type,omitempty"`
+		State               PlanState            `json:"state,omitempty"`
+		CreateTime          *time.Time           `json:"create_time,omitempty"`
+		UpdateTime          *time.Time           `json:"update_time,omitempty"`
+		PaymentDefinitions  []PaymentDefinition  `json:"payment_definitions,omitempty"`
+		MerchantPreferences *MerchantPreferences `json:"merchant_preferences,omitempty"`
+		Links               []Links              `json:"links,omitempty"`
+	}
+
+	// PaymentDefinition maps to payment_definition object
+	PaymentDefinition struct {
+		ID                string                `json:"id,omitempty"`
+		Name              string                `json:"name"`
+		Type              PaymentDefinitionType `json:"type"`
+		Frequency         Frequency             `json:"frequency"`
+		FrequencyInterval string                `json:"frequency_interval"`
+		Cycles            string                `json:"cycles"`
+		Amount            *Currency             `json:"amount"`
+		ChargeModels      []ChargeModel         `json:"charge_models,omitempty"`
+	}
+
+	// ChargeModel maps to charge_model object
+	ChargeModel struct {
+		ID     string          `json:"id,omitempty"`
+		Type   ChargeModelType `json:"type"`
+		Amount *Currency       `json:"amount"`
+	}
+
+	// MerchantPreferences maps to merchant_preferences object
+	MerchantPreferences struct {
+		ID                      string    `json:"id,omitempty"`
+		SetupFee                *Currency `json:"setup_fee,omitempty"`
+		CancelURL               string    `json:"cancel_url"`
+		ReturnURL               string    `json:"return_url"`
+		NotifyURL               string    `json:"notify_url,omitempty"`
+		MaxFailAttempts         string    `json:"max_fail_attempts,omitempty"`
+		AutoBillAmount          string    `json:"auto_bill_amount,omitempty"`
+		InitialFailAmountAction string    `json:"initial_fail_amount_action,omitempty"`
+		AcceptedPaymentType     string    `json:"accepted_payment_type,omitempty"`
+		CharSet                 string    `json:"char_set,omitempty"`
+	}
+
+	// ListPlansResp maps to the response of the list plans request
+	ListPlansResp struct {
+		Plans      []Plan  `json:"plans"`
+		TotalItems string  `json:"total_items,omitempty"`
+		TotalPages string  `json:"total_pages,omitempty"`
+		Links      []Links `json:"links,omitempty"`
+	}
+)
+
+// CreatePlan creates a billing plan in Paypal. The plan is created in the
+// CREATED state and must be activated before agreements can use it
+func (c *Client) CreatePlan(p Plan) (*Plan, error) {
+	return c.CreatePlanContext(context.Background(), p)
+}
+
+// CreatePlanContext is like CreatePlan but uses ctx for the request
+func (c *Client) CreatePlanContext(ctx context.Context, p Plan) (*Plan, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/billing-plans", c.APIBase), p)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Plan{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetPlan returns a billing plan by ID
+func (c *Client) GetPlan(planID string) (*Plan, error) {
+	return c.GetPlanContext(context.Background(), planID)
+}
+
+// GetPlanContext is like GetPlan but uses ctx for the request
+func (c *Client) GetPlanContext(ctx context.Context, planID string) (*Plan, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/billing-plans/%s", c.APIBase, planID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Plan{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListPlans retrieves billing plans from Paypal. filter can set the status,
+// page, page_size and total_required query parameters; plans in the CREATED
+// state are listed when no status is given
+func (c *Client) ListPlans(filter map[string]string) (*ListPlansResp, error) {
+	return c.ListPlansContext(context.Background(), filter)
+}
+
+// ListPlansContext is like ListPlans but uses ctx for the request
+func (c *Client) ListPlansContext(ctx context.Context, filter map[string]string) (*ListPlansResp, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/billing-plans", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter != nil {
+		q := req.URL.Query()
+
+		for k, v := range filter {
+			q.Set(k, v)
+		}
+
+		req.URL.RawQuery = q.Encode()
+	}
+
+	v := &ListPlansResp{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdatePlan applies a JSON Patch to a billing plan
+func (c *Client) UpdatePlan(planID string, patches []Patch) error {
+	return c.UpdatePlanContext(context.Background(), planID, patches)
+}
+
+// UpdatePlanContext is like UpdatePlan but uses ctx for the request
+func (c *Client) UpdatePlanContext(ctx context.Context, planID string, patches []Patch) error {
+	req, err := NewRequestContext(ctx, "PATCH", fmt.Sprintf("%s/payments/billing-plans/%s", c.APIBase, planID), patches)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// ActivatePlan sets the state of a billing plan to ACTIVE
+func (c *Client) ActivatePlan(planID string) error {
+	return c.ActivatePlanContext(context.Background(), planID)
+}
+
+// ActivatePlanContext is like ActivatePlan but uses ctx for the request
+func (c *Client) ActivatePlanContext(ctx context.Context, planID string) error {
+	return c.UpdatePlanContext(ctx, planID, planStatePatch(PlanStateActive))
+}
+
+// DeactivatePlan sets the state of a billing plan to INACTIVE
+func (c *Client) DeactivatePlan(planID string) error {
+	return c.DeactivatePlanContext(context.Background(), planID)
+}
+
+// DeactivatePlanContext is like DeactivatePlan but uses ctx for the request
+func (c *Client) DeactivatePlanContext(ctx context.Context, planID string) error {
+	return c.UpdatePlanContext(ctx, planID, planStatePatch(PlanStateInactive))
+}
+
+// planStatePatch returns the patch moving a plan to state
+func planStatePatch(state PlanState) []Patch {
+	return []Patch{{
+		Operation: "replace",
+		Path:      "/",
+		Value:     map[string]PlanState{"state": state},
+	}}
+}
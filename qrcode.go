@@ -0,0 +1,66 @@
+package paypal
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// errNoQRCode is returned when a QR code response holds no image
+var errNoQRCode = errors.New("paypal: no image in QR code response")
+
+// GetInvoiceQRCode returns the PNG image of a QR code linking to the payer
+// view of a sent invoice. Zero width or height uses the default of 500 pixels
+func (c *Client) GetInvoiceQRCode(invoiceID string, width, height int) ([]byte, error) {
+	return c.GetInvoiceQRCodeContext(context.Background(), invoiceID, width, height)
+}
+
+// GetInvoiceQRCodeContext is like GetInvoiceQRCode but uses ctx for the request
+func (c *Client) GetInvoiceQRCodeContext(ctx context.Context, invoiceID string, width, height int) ([]byte, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/invoicing/invoices/%s/qr-code", c.APIBase, invoiceID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	q := req.URL.Query()
+	if width > 0 {
+		q.Set("width", strconv.Itoa(width))
+	}
+	if height > 0 {
+		q.Set("height", strconv.Itoa(height))
+	}
+	req.URL.RawQuery = q.Encode()
+
+	var resp struct {
+		Image string `json:"image"`
+	}
+
+	err = c.SendWithAuth(req, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Image == "" {
+		return nil, errNoQRCode
+	}
+
+	return base64.StdEncoding.DecodeString(resp.Image)
+}
+
+// WriteInvoiceQRCode is like GetInvoiceQRCode but writes the PNG image to w
+func (c *Client) WriteInvoiceQRCode(w io.Writer, invoiceID string, width, height int) error {
+	return c.WriteInvoiceQRCodeContext(context.Background(), w, invoiceID, width, height)
+}
+
+// WriteInvoiceQRCodeContext is like WriteInvoiceQRCode but uses ctx for the request
+func (c *Client) WriteInvoiceQRCodeContext(ctx context.Context, w io.Writer, invoiceID string, width, height int) error {
+	image, err := c.GetInvoiceQRCodeContext(ctx, invoiceID, width, height)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(image)
+	return err
+}
@@ -0,0 +1,88 @@
+package paypal
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWriteInvoiceQRCode(t *testing.T) {
+	image := bytes.Repeat([]byte("\x89PNG\r\n\x1a\n image data ?>"), 20)
+	encoded := base64.StdEncoding.EncodeToString(image)
+
+	// newClient returns a client whose server answers QR code requests
+	// with body, declaring a Content-Length of length if set
+	newClient := func(body string, length string) *Client {
+		return newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				writeToken(w, "token")
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if length != "" {
+				w.Header().Set("Content-Length", length)
+			}
+			w.Write([]byte(body))
+		})
+	}
+
+	Convey("WriteInvoiceQRCode should write the decoded image", t, func() {
+		c := newClient(`{"image":"`+encoded+`"}`, "")
+
+		var buf bytes.Buffer
+		So(c.WriteInvoiceQRCode(&buf, "INV2-1", 0, 0), ShouldBeNil)
+		So(buf.Bytes(), ShouldResemble, image)
+	})
+
+	Convey("GetInvoiceQRCode should decode an image with escapes and line breaks", t, func() {
+		// Paypal escapes slashes and wraps the base64 in lines
+		escaped := strings.ReplaceAll(encoded, "/", `\/`)
+		escaped = escaped[:76] + `\n` + escaped[76:]
+		// Any character may be sent as a \u escape
+		escaped = strings.ReplaceAll(escaped, "+", `+`)
+		So(escaped, ShouldContainSubstring, `+`)
+		c := newClient(`{"image":"`+escaped+`"}`, "")
+
+		got, err := c.GetInvoiceQRCode("INV2-1", 0, 0)
+		So(err, ShouldBeNil)
+		So(got, ShouldResemble, image)
+	})
+
+	Convey("GetInvoiceQRCode should find the image after a large field", t, func() {
+		padding := strings.Repeat("x", 64*1024)
+		c := newClient(`{"padding":"`+padding+`","image":"`+encoded+`"}`, "")
+
+		got, err := c.GetInvoiceQRCode("INV2-1", 0, 0)
+		So(err, ShouldBeNil)
+		So(got, ShouldResemble, image)
+	})
+
+	Convey("GetInvoiceQRCode should report a response without an image", t, func() {
+		c := newClient(`{"width":500}`, "")
+
+		_, err := c.GetInvoiceQRCode("INV2-1", 0, 0)
+		So(err, ShouldEqual, errNoQRCode)
+	})
+
+	Convey("WriteInvoiceQRCode should report invalid base64", t, func() {
+		c := newClient(`{"image":"iVBO*w0KGgo="}`, "")
+
+		err := c.WriteInvoiceQRCode(io.Discard, "INV2-1", 0, 0)
+		var corrupt base64.CorruptInputError
+		So(errors.As(err, &corrupt), ShouldBeTrue)
+	})
+
+	Convey("WriteInvoiceQRCode should report a body cut short by the transport", t, func() {
+		body := `{"image":"` + encoded + `"}`
+		c := newClient(body[:len(body)-6], "1000")
+
+		err := c.WriteInvoiceQRCode(io.Discard, "INV2-1", 0, 0)
+		So(errors.Is(err, io.ErrUnexpectedEOF), ShouldBeTrue)
+	})
+}
@@ -0,0 +1,150 @@
+package paypal
+
+import (
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// sensitiveField matches JSON string fields that must never be logged in
+// clear, capturing the field name and its value
+var sensitiveField = regexp.MustCompile(`"(number|cvv2|access_token|refresh_token|id_token|client_secret)"(\s*:\s*)"((?:[^"\\]|\\.)*)"`)
+
+// sensitiveFormField matches form-encoded values that must never be logged
+// in clear, as sent to the token endpoints
+var sensitiveFormField = regexp.MustCompile(`(^|&)(code|access_token|refresh_token|id_token|client_secret)=([^&]*)`)
+
+// cardNumber matches values that look like a card number (PAN)
+var cardNumber = regexp.MustCompile(`^[0-9][0-9 -]{11,22}[0-9]$`)
+
+// Redact returns a copy of a JSON body with card numbers masked down to
+// their last 4 digits, and CVVs and OAuth2 tokens removed. Authorization
+// codes and tokens of form-encoded bodies are removed too. It is applied to
+// every body the Client logs
+func Redact(body []byte) []byte {
+	body = sensitiveFormField.ReplaceAll(body, []byte("${1}${2}=[REDACTED]"))
+
+	return sensitiveField.ReplaceAllFunc(body, func(m []byte) []byte {
+		sub := sensitiveField.FindSubmatch(m)
+		name, sep, value := string(sub[1]), string(sub[2]), string(sub[3])
+
+		switch {
+		case name == "number" && cardNumber.MatchString(value):
+			value = MaskCardNumber(value)
+		case name == "number":
+			return m
+		case value != "":
+			value = "[REDACTED]"
+		}
+
+		return []byte(`"` + name + `"` + sep + `"` + value + `"`)
+	})
+}
+
+// MaskCardNumber replaces all but the last 4 digits of a card number
+func MaskCardNumber(number string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, number)
+	if len(digits) <= 4 {
+		return strings.Repeat("*", len(digits))
+	}
+
+	return strings.Repeat("*", len(digits)-4) + digits[len(digits)-4:]
+}
+
+// creditCard has the fields of CreditCard but none of its methods, so that
+// it can be handed to fmt without recursing into Format
+type creditCard CreditCard
+
+// masked returns a copy of cc safe to print
+func (cc CreditCard) masked() creditCard {
+	cc.Number = MaskCardNumber(cc.Number)
+	if cc.CVV2 != "" {
+		cc.CVV2 = "***"
+	}
+	return creditCard(cc)
+}
+
+// Format implements fmt.Formatter, printing the card number masked down to
+// its last 4 digits and hiding the CVV
+func (cc CreditCard) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), cc.masked())
+}
+
+// LogValue implements slog.LogValuer, logging the card number masked down
+// to its last 4 digits and omitting the CVV
+func (cc CreditCard) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("id", cc.ID),
+		slog.String("type", cc.Type),
+		slog.String("number", MaskCardNumber(cc.Number)),
+		slog.String("expire_month", cc.ExpireMonth),
+		slog.String("expire_year", cc.ExpireYear),
+		slog.String("first_name", cc.FirstName),
+		slog.String("last_name", cc.LastName),
+		slog.String("state", string(cc.State)),
+	)
+}
+
+// vaultRequest has the fields of VaultRequest with a card safe to print
+type vaultRequest struct {
+	creditCard
+	MerchantID         string
+	ExternalCardID     string
+	ExternalCustomerID string
+}
+
+func (r VaultRequest) masked() vaultRequest {
+	return vaultRequest{r.CreditCard.masked(), r.MerchantID, r.ExternalCardID, r.ExternalCustomerID}
+}
+
+// Format implements fmt.Formatter, masking the card like CreditCard does
+func (r VaultRequest) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), r.masked())
+}
+
+// LogValue implements slog.LogValuer, masking the card like CreditCard does
+func (r VaultRequest) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("credit_card", r.CreditCard),
+		slog.String("merchant_id", r.MerchantID),
+		slog.String("external_card_id", r.ExternalCardID),
+		slog.String("external_customer_id", r.ExternalCustomerID),
+	)
+}
+
+// vaultResponse has the fields of VaultResponse with a card safe to print
+type vaultResponse struct {
+	vaultRequest
+	CreateTime *time.Time
+	UpdateTime *time.Time
+	State      string
+	ValidUntil string
+	Links      []Links
+}
+
+// Format implements fmt.Formatter. Without it, the Format method of the
+// embedded VaultRequest would print the request fields only
+func (r VaultResponse) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, fmt.FormatString(f, verb), vaultResponse{
+		r.VaultRequest.masked(), r.CreateTime, r.UpdateTime, r.State, r.ValidUntil, r.Links,
+	})
+}
+
+// LogValue implements slog.LogValuer, masking the card like CreditCard does
+func (r VaultResponse) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Any("credit_card", r.CreditCard),
+		slog.String("merchant_id", r.MerchantID),
+		slog.String("external_card_id", r.ExternalCardID),
+		slog.String("external_customer_id", r.ExternalCustomerID),
+		slog.String("state", r.State),
+		slog.String("valid_until", r.ValidUntil),
+	)
+}
@@ -0,0 +1,163 @@
+package paypal
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRedact(t *testing.T) {
+	Convey("Redact should mask card data and remove secrets", t, func() {
+		cases := []struct {
+			name, body, want string
+		}{
+			{
+				"card number and CVV",
+				`{"number":"4417119669820331","cvv2":"874","type":"visa"}`,
+				`{"number":"************0331","cvv2":"[REDACTED]","type":"visa"}`,
+			},
+			{
+				"spaced card number",
+				`{"number" : "4417 1196 6982 0331"}`,
+				`{"number" : "************0331"}`,
+			},
+			{
+				"nested card",
+				`{"funding_instruments":[{"credit_card":{"number":"4417-1196-6982-0331","cvv2":"874"}}]}`,
+				`{"funding_instruments":[{"credit_card":{"number":"************0331","cvv2":"[REDACTED]"}}]}`,
+			},
+			{
+				"empty CVV",
+				`{"cvv2":""}`,
+				`{"cvv2":""}`,
+			},
+			{
+				"OAuth2 tokens",
+				`{"access_token":"A21AAF","refresh_token":"R23AAF","id_token":"eyJ","token_type":"Bearer"}`,
+				`{"access_token":"[REDACTED]","refresh_token":"[REDACTED]","id_token":"[REDACTED]","token_type":"Bearer"}`,
+			},
+			{
+				"escaped quote in a secret",
+				`{"client_secret":"a\"b","name":"x"}`,
+				`{"client_secret":"[REDACTED]","name":"x"}`,
+			},
+			{
+				"invoice number",
+				`{"number":"INV2-9XQT-6WVG","status":"DRAFT"}`,
+				`{"number":"INV2-9XQT-6WVG","status":"DRAFT"}`,
+			},
+			{
+				"short numeric number",
+				`{"number":"0042"}`,
+				`{"number":"0042"}`,
+			},
+			{
+				"phone number",
+				`{"phone":{"country_code":"1","national_number":"4085551234"}}`,
+				`{"phone":{"country_code":"1","national_number":"4085551234"}}`,
+			},
+			{
+				"form-encoded authorization code",
+				`grant_type=authorization_code&code=C21AAH&redirect_uri=https%3A%2F%2Fexample.com`,
+				`grant_type=authorization_code&code=[REDACTED]&redirect_uri=https%3A%2F%2Fexample.com`,
+			},
+			{
+				"form-encoded refresh token",
+				`grant_type=refresh_token&refresh_token=R23AAF`,
+				`grant_type=refresh_token&refresh_token=[REDACTED]`,
+			},
+			{
+				"form-encoded client credentials",
+				`grant_type=client_credentials`,
+				`grant_type=client_credentials`,
+			},
+		}
+
+		for _, c := range cases {
+			So(string(Redact([]byte(c.body))), ShouldEqual, c.want)
+		}
+	})
+
+	Convey("MaskCardNumber should keep the last 4 digits only", t, func() {
+		So(MaskCardNumber("4417119669820331"), ShouldEqual, "************0331")
+		So(MaskCardNumber("4417 1196 6982 0331"), ShouldEqual, "************0331")
+		So(MaskCardNumber("1234"), ShouldEqual, "****")
+		So(MaskCardNumber(""), ShouldEqual, "")
+	})
+}
+
+func TestCardFormatting(t *testing.T) {
+	card := CreditCard{
+		ID:          "CARD-1",
+		Number:      "4417119669820331",
+		CVV2:        "874",
+		Type:        "visa",
+		ExpireMonth: "11",
+		ExpireYear:  "2030",
+		FirstName:   "Betsy",
+	}
+	request := VaultRequest{CreditCard: card, ExternalCardID: "card-1"}
+	response := VaultResponse{VaultRequest: request, State: "ok"}
+
+	Convey("Printing cards with fmt should mask their number and CVV", t, func() {
+		values := []interface{}{card, &card, request, &request, response, &response}
+
+		for _, v := range values {
+			for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+				out := fmt.Sprintf(verb, v)
+				So(out, ShouldNotContainSubstring, "4417119669820331")
+				So(out, ShouldNotContainSubstring, "874")
+				So(out, ShouldContainSubstring, "************0331")
+				So(out, ShouldContainSubstring, "Betsy")
+			}
+		}
+		So(fmt.Sprintf("%+v", request), ShouldContainSubstring, "card-1")
+		So(fmt.Sprintf("%+v", response), ShouldContainSubstring, "State:ok")
+	})
+
+	Convey("Logging cards with slog should mask their number and omit the CVV", t, func() {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+		logger.Info("card", "card", card, "request", request, "response", response)
+
+		out := buf.String()
+		So(out, ShouldNotContainSubstring, "4417119669820331")
+		So(out, ShouldNotContainSubstring, "874")
+
+		var record map[string]interface{}
+		So(json.Unmarshal(buf.Bytes(), &record), ShouldBeNil)
+		So(record["card"].(map[string]interface{})["number"], ShouldEqual, "************0331")
+		So(record["request"].(map[string]interface{})["credit_card"].(map[string]interface{})["number"], ShouldEqual, "************0331")
+		So(record["response"].(map[string]interface{})["state"], ShouldEqual, "ok")
+	})
+
+	Convey("Encoding cards to JSON should keep their number", t, func() {
+		data, err := json.Marshal(request)
+		So(err, ShouldBeNil)
+		So(string(data), ShouldContainSubstring, `"number":"4417119669820331"`)
+	})
+}
+
+func TestErrorBodyRedaction(t *testing.T) {
+	Convey("An error body that cannot be decoded should be redacted", t, func() {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"credit_card":{"number":"4417119669820331","cvv2":"874"`))
+		})
+
+		req, _ := NewRequest("POST", c.APIBase+"/vault/credit-card", nil)
+		err := c.Send(req, nil)
+
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldNotContainSubstring, "4417119669820331")
+		So(err.Error(), ShouldNotContainSubstring, "874")
+		So(strings.Contains(err.Error(), "************0331"), ShouldBeTrue)
+	})
+}
@@ -1,10 +1,18 @@
 package paypal
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+)
 
 // GetRefund returns a refund by ID
 func (c *Client) GetRefund(refundID string) (*Refund, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/refund/%s", c.APIBase, refundID), nil)
+	return c.GetRefundContext(context.Background(), refundID)
+}
+
+// GetRefundContext is like GetRefund but uses ctx for the request
+func (c *Client) GetRefundContext(ctx context.Context, refundID string) (*Refund, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/refund/%s", c.APIBase, refundID), nil)
 	if err != nil {
 		return nil, err
 	}
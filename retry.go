@@ -0,0 +1,243 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+type (
+	// RetryPolicy controls how Send retries a request that failed with a
+	// transport error, a 429 or a 5xx response. Only requests that are safe
+	// to repeat are retried: GET, HEAD, OPTIONS, PUT and DELETE requests,
+	// and any request carrying a PayPal-Request-Id header
+	RetryPolicy struct {
+		// MaxAttempts is the total number of attempts, including the first
+		// one. A value of 1 or less disables retries
+		MaxAttempts int
+
+		// InitialBackoff is the delay before the first retry. Defaults to 500ms
+		InitialBackoff time.Duration
+
+		// MaxBackoff caps the delay between two attempts, including the
+		// delay requested by a Retry-After header. Defaults to 30s
+		MaxBackoff time.Duration
+
+		// Multiplier is applied to the delay after each attempt. Defaults to 2
+		Multiplier float64
+
+		// Jitter is the fraction, between 0 and 1, of each delay that is
+		// randomized so that concurrent clients don't retry in lockstep
+		Jitter float64
+	}
+
+	// RetryError is returned by Send when a RetryPolicy is in effect and the
+	// request ultimately failed. It wraps the error of the last attempt
+	RetryError struct {
+		Attempts int
+		Err      error
+	}
+)
+
+// DefaultRetryPolicy retries up to 3 times with exponential backoff
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	Jitter:         0.2,
+}
+
+// responseBodyError is an error met while reading the body of a successful
+// response, or writing it to the io.Writer passed to Send. The request must
+// not be retried, since it was processed
+type responseBodyError struct {
+	err error
+}
+
+func (e *responseBodyError) Error() string {
+	return fmt.Sprintf("paypal: response body: %v", e.err)
+}
+
+func (e *responseBodyError) Unwrap() error {
+	return e.err
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("paypal: giving up after %d attempt(s): %v", e.Attempts, e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Backoff returns the delay to wait after the given attempt (starting at 1)
+// has failed
+func (p *RetryPolicy) Backoff(attempt int) time.Duration {
+	initial, max, mult := p.InitialBackoff, p.maxBackoff(), p.Multiplier
+	if initial <= 0 {
+		initial = 500 * time.Millisecond
+	}
+	if mult < 1 {
+		mult = 2
+	}
+
+	d := float64(initial) * math.Pow(mult, float64(attempt-1))
+	if d > float64(max) {
+		d = float64(max)
+	}
+	if p.Jitter > 0 {
+		d -= d * math.Min(p.Jitter, 1) * rand.Float64()
+	}
+
+	return time.Duration(d)
+}
+
+// maxBackoff returns MaxBackoff, or its default if unset
+func (p *RetryPolicy) maxBackoff() time.Duration {
+	if p.MaxBackoff <= 0 {
+		return 30 * time.Second
+	}
+	return p.MaxBackoff
+}
+
+// retryPolicy returns the policy that applies to a request made with ctx,
+// or nil if the request should not be retried
+func (c *Client) retryPolicy(ctx context.Context) *RetryPolicy {
+	p := c.RetryPolicy
+	if cp, ok := retryPolicyFromContext(ctx); ok {
+		p = cp
+	}
+	if p == nil || p.MaxAttempts <= 1 {
+		return nil
+	}
+
+	return p
+}
+
+// isReplayable reports whether req can be sent again without risk of
+// performing the operation twice
+func isReplayable(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+		return true
+	}
+
+	return req.Header.Get(RequestIDHeader) != ""
+}
+
+// isRetryable reports whether err is worth another attempt. Transport
+// errors are only retried if they were met before the response status was
+// received, since the request may have succeeded otherwise
+func isRetryable(err error) bool {
+	var bodyErr *responseBodyError
+	if errors.As(err, &bodyErr) {
+		return false
+	}
+
+	var errResp *ErrorResponse
+	if errors.As(err, &errResp) {
+		if errResp.Response == nil {
+			return false
+		}
+		code := errResp.Response.StatusCode
+		return code == http.StatusTooManyRequests || code >= 500
+	}
+
+	// Send returns the error of a done request context as is, while the
+	// transport wraps its own timeouts, http.Client.Timeout included, in a
+	// *url.Error
+	var urlErr *url.Error
+	if isContextError(err) && !errors.As(err, &urlErr) {
+		return false
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// retryAfter returns the delay requested by the Retry-After header of the
+// response that caused err, if any
+func retryAfter(err error) (time.Duration, bool) {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return 0, false
+	}
+
+	h := errResp.Response.Header.Get("Retry-After")
+	if h == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(h); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(h); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+// sendWithRetry calls send until it succeeds, the error is not retryable,
+// or the policy gives up
+func (c *Client) sendWithRetry(req *http.Request, v interface{}, p *RetryPolicy) error {
+	ctx := req.Context()
+	attempts := 0
+
+	for {
+		attempts++
+		err := c.send(req, v, attempts)
+		if err == nil {
+			return nil
+		}
+		if attempts >= p.MaxAttempts || ctx.Err() != nil || !isRetryable(err) {
+			return &RetryError{Attempts: attempts, Err: err}
+		}
+
+		delay := p.Backoff(attempts)
+		if d, ok := retryAfter(err); ok {
+			delay = d
+			if max := p.maxBackoff(); delay > max {
+				delay = max
+			}
+		}
+
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return &RetryError{Attempts: attempts, Err: err}
+			}
+			req.Body = body
+		}
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return &RetryError{Attempts: attempts, Err: ctx.Err()}
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package paypal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestBackoff(t *testing.T) {
+	Convey("Backoff should grow exponentially up to MaxBackoff", t, func() {
+		p := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Multiplier: 2}
+
+		So(p.Backoff(1), ShouldEqual, 100*time.Millisecond)
+		So(p.Backoff(2), ShouldEqual, 200*time.Millisecond)
+		So(p.Backoff(4), ShouldEqual, 800*time.Millisecond)
+		So(p.Backoff(5), ShouldEqual, time.Second)
+		So(p.Backoff(50), ShouldEqual, time.Second)
+	})
+
+	Convey("Backoff should default unset fields", t, func() {
+		p := &RetryPolicy{}
+
+		So(p.Backoff(1), ShouldEqual, 500*time.Millisecond)
+		So(p.Backoff(2), ShouldEqual, time.Second)
+		So(p.Backoff(100), ShouldEqual, 30*time.Second)
+	})
+
+	Convey("Jitter should only shorten the delay, by at most its fraction", t, func() {
+		p := &RetryPolicy{InitialBackoff: time.Second, MaxBackoff: time.Minute, Multiplier: 2, Jitter: 0.2}
+
+		seen := make(map[time.Duration]bool)
+		for i := 0; i < 100; i++ {
+			d := p.Backoff(2)
+			So(d, ShouldBeLessThanOrEqualTo, 2*time.Second)
+			So(d, ShouldBeGreaterThanOrEqualTo, 1600*time.Millisecond)
+			seen[d] = true
+		}
+		So(len(seen), ShouldBeGreaterThan, 1)
+
+		p.Jitter = 5
+		for i := 0; i < 100; i++ {
+			So(p.Backoff(1), ShouldBeGreaterThanOrEqualTo, 0)
+		}
+	})
+}
+
+func TestIsReplayable(t *testing.T) {
+	newRequest := func(method string, body io.Reader, requestID string) *http.Request {
+		req, _ := http.NewRequest(method, "https://example.com/v1/payments/payment", body)
+		if requestID != "" {
+			req.Header.Set(RequestIDHeader, requestID)
+		}
+		return req
+	}
+
+	Convey("Idempotent methods should be replayable", t, func() {
+		for _, method := range []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE"} {
+			So(isReplayable(newRequest(method, nil, "")), ShouldBeTrue)
+		}
+		So(isReplayable(newRequest("PUT", strings.NewReader("{}"), "")), ShouldBeTrue)
+	})
+
+	Convey("POST and PATCH should only be replayable with a PayPal-Request-Id", t, func() {
+		So(isReplayable(newRequest("POST", strings.NewReader("{}"), "")), ShouldBeFalse)
+		So(isReplayable(newRequest("PATCH", strings.NewReader("[]"), "")), ShouldBeFalse)
+		So(isReplayable(newRequest("POST", strings.NewReader("{}"), "id")), ShouldBeTrue)
+	})
+
+	Convey("A body that cannot be read again should not be replayable", t, func() {
+		req := newRequest("PUT", io.NopCloser(strings.NewReader("{}")), "id")
+		req.GetBody = nil
+
+		So(isReplayable(req), ShouldBeFalse)
+	})
+}
+
+// timeoutError is a net.Error reporting a timeout
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	errorResponse := func(status int) error {
+		return &ErrorResponse{Response: &http.Response{StatusCode: status}}
+	}
+
+	Convey("isRetryable should classify errors", t, func() {
+		cases := []struct {
+			name string
+			err  error
+			want bool
+		}{
+			{"429", errorResponse(http.StatusTooManyRequests), true},
+			{"500", errorResponse(http.StatusInternalServerError), true},
+			{"503", errorResponse(http.StatusServiceUnavailable), true},
+			{"400", errorResponse(http.StatusBadRequest), false},
+			{"401", errorResponse(http.StatusUnauthorized), false},
+			{"404", errorResponse(http.StatusNotFound), false},
+			{"error without response", &ErrorResponse{}, false},
+			{"connection reset", &url.Error{Op: "Post", Err: syscall.ECONNRESET}, true},
+			{"connection refused", &url.Error{Op: "Post", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, true},
+			{"EOF before the response", &url.Error{Op: "Post", Err: io.EOF}, true},
+			{"timeout", &url.Error{Op: "Post", Err: timeoutError{}}, true},
+			{"canceled", context.Canceled, false},
+			{"deadline exceeded", fmt.Errorf("post: %w", context.DeadlineExceeded), false},
+			{"client timeout", &url.Error{Op: "Get", Err: context.DeadlineExceeded}, true},
+			{"truncated response body", &responseBodyError{io.ErrUnexpectedEOF}, false},
+			{"response body timeout", &responseBodyError{timeoutError{}}, false},
+			{"other error", errors.New("boom"), false},
+		}
+
+		for _, c := range cases {
+			So(isRetryable(c.err), ShouldEqual, c.want)
+		}
+	})
+}
+
+func TestSendWithRetry(t *testing.T) {
+	policy := &RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 20 * time.Millisecond}
+
+	Convey("Retry-After should be capped by MaxBackoff", t, func() {
+		var calls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.Header().Set("Retry-After", "3600")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{}`))
+		})
+		c.RetryPolicy = policy
+		req, _ := NewRequest("GET", c.APIBase+"/payments/payment", nil)
+
+		start := time.Now()
+		err := c.Send(req, &struct{}{})
+
+		So(err, ShouldBeNil)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 2)
+		So(time.Since(start), ShouldBeLessThan, 5*time.Second)
+	})
+
+	Convey("A request that hit the http.Client timeout should be retried", t, func() {
+		var calls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				writeToken(w, "token")
+				return
+			}
+			if atomic.AddInt32(&calls, 1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			w.Write([]byte(`{"id": "PAY-1"}`))
+		}, WithTimeout(50*time.Millisecond))
+		c.RetryPolicy = policy
+
+		payment, err := c.GetPayment("PAY-1")
+
+		So(err, ShouldBeNil)
+		So(payment.ID, ShouldEqual, "PAY-1")
+		So(atomic.LoadInt32(&calls), ShouldEqual, 2)
+	})
+
+	Convey("A request whose context expired should not be retried", t, func() {
+		var calls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			<-r.Context().Done()
+		})
+		c.RetryPolicy = policy
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		req, _ := NewRequestContext(ctx, "GET", c.APIBase+"/payments/payment", nil)
+
+		err := c.Send(req, &struct{}{})
+
+		So(errors.Is(err, context.DeadlineExceeded), ShouldBeTrue)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+	})
+
+	Convey("A POST whose response body is cut short should not be sent again", t, func() {
+		var calls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.Header().Set("Content-Length", "100")
+			w.Write([]byte(`{"id": "PAY-`))
+		})
+		c.RetryPolicy = policy
+		req, _ := NewRequest("POST", c.APIBase+"/payments/payment", bytes.NewBufferString("{}"))
+
+		err := c.Send(req, &Payment{})
+
+		So(errors.Is(err, io.ErrUnexpectedEOF), ShouldBeTrue)
+		So(atomic.LoadInt32(&calls), ShouldEqual, 1)
+	})
+
+	Convey("A retryable error should be retried with the same PayPal-Request-Id", t, func() {
+		var mu sync.Mutex
+		var ids []string
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			ids = append(ids, r.Header.Get(RequestIDHeader))
+			n := len(ids)
+			mu.Unlock()
+			if n < 3 {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Write([]byte(`{}`))
+		})
+		c.RetryPolicy = policy
+		req, _ := NewRequest("POST", c.APIBase+"/payments/payment", struct{}{})
+
+		So(c.Send(req, &Payment{}), ShouldBeNil)
+		mu.Lock()
+		defer mu.Unlock()
+		So(ids, ShouldHaveLength, 3)
+		So(ids[1], ShouldEqual, ids[0])
+		So(ids[2], ShouldEqual, ids[0])
+	})
+}
@@ -1,6 +1,7 @@
 package paypal
 
 import (
+	"context"
 	"fmt"
 )
 
@@ -12,7 +13,12 @@ type (
 
 // GetSales returns a sale by ID
 func (c *Client) GetSale(saleID string) (*Sale, error) {
-	req, err := NewRequest("GET", fmt.Sprintf("%s/payments/sale/%s", c.APIBase, saleID), nil)
+	return c.GetSaleContext(context.Background(), saleID)
+}
+
+// GetSaleContext is like GetSale but uses ctx for the request
+func (c *Client) GetSaleContext(ctx context.Context, saleID string) (*Sale, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payments/sale/%s", c.APIBase, saleID), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -31,7 +37,12 @@ func (c *Client) GetSale(saleID string) (*Sale, error) {
 // Amount struct. If Amount is provided, a partial refund is requested,
 // or else a full refund is made instead
 func (c *Client) RefundSale(saleID string, a *Amount) (*Refund, error) {
-	req, err := NewRequest("POST", fmt.Sprintf("%s/payments/sale/%s/refund", c.APIBase, saleID), &RefundReq{Amount: a})
+	return c.RefundSaleContext(context.Background(), saleID, a)
+}
+
+// RefundSaleContext is like RefundSale but uses ctx for the request
+func (c *Client) RefundSaleContext(ctx context.Context, saleID string, a *Amount) (*Refund, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payments/sale/%s/refund", c.APIBase, saleID), &RefundReq{Amount: a})
 	if err != nil {
 		return nil, err
 	}
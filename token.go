@@ -0,0 +1,155 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// tokenExpiryDelta is how long before ExpiresAt a token is considered stale,
+// so that it gets refreshed before requests start failing with it
+const tokenExpiryDelta = 30 * time.Second
+
+// tokenRefreshTimeout limits a token refresh when the http.Client of the
+// Client has no timeout
+const tokenRefreshTimeout = time.Minute
+
+// tokenCall is an in-flight token refresh that concurrent callers wait on
+type tokenCall struct {
+	done  chan struct{}
+	token *TokenResp
+	err   error
+}
+
+// valid reports whether t can still be used for a request
+func (t *TokenResp) valid() bool {
+	return t != nil && t.Token != "" && time.Now().Add(tokenExpiryDelta).Before(t.ExpiresAt)
+}
+
+// accessToken returns the current access token, requesting a new one if it
+// is missing or about to expire. Only one refresh is made at a time; other
+// callers wait for its result. The refresh does not depend on the context
+// of the caller that started it, so that cancelling one caller does not fail
+// the others: each caller only stops waiting when its own ctx is done
+func (c *Client) accessToken(ctx context.Context) (*TokenResp, error) {
+	retried := false
+
+	for {
+		c.tokenMu.Lock()
+		if c.Token.valid() {
+			t := c.Token
+			c.tokenMu.Unlock()
+			return t, nil
+		}
+
+		call := c.tokenCall
+		if call == nil {
+			call = &tokenCall{done: make(chan struct{})}
+			c.tokenCall = call
+			go c.runTokenCall(ctx, call)
+		}
+		c.tokenMu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		// A refresh that ran out of time is tried once more while the
+		// caller is still willing to wait
+		if isContextError(call.err) && !retried {
+			retried = true
+			continue
+		}
+		if call.err != nil {
+			return nil, call.err
+		}
+
+		return call.token, nil
+	}
+}
+
+// runTokenCall refreshes the token for call. It runs on a copy of ctx that
+// keeps its values but is neither cancelled with it nor bound by its
+// deadline, the refresh being limited by the timeout of the http.Client, or
+// tokenRefreshTimeout if it has none
+func (c *Client) runTokenCall(ctx context.Context, call *tokenCall) {
+	timeout := c.client.Timeout
+	if timeout <= 0 {
+		timeout = tokenRefreshTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	// The token request must not overwrite the caller's ResponseMeta
+	call.token, call.err = c.refreshToken(WithResponseMeta(ctx, nil))
+
+	c.tokenMu.Lock()
+	if call.err == nil {
+		c.Token = call.token
+	}
+	c.tokenCall = nil
+	c.tokenMu.Unlock()
+	close(call.done)
+}
+
+// isContextError reports whether err was caused by a context being
+// cancelled or its deadline passing
+func isContextError(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// refreshToken returns a token from the TokenStore if it holds a usable one,
+// or else requests a new token and saves it to the store. Store failures
+// are treated as cache misses and do not fail the request
+func (c *Client) refreshToken(ctx context.Context) (*TokenResp, error) {
+	if c.TokenStore == nil {
+		return c.GetAccessTokenContext(ctx)
+	}
+
+	c.tokenMu.Lock()
+	rejected := c.rejectedToken
+	c.tokenMu.Unlock()
+
+	t, err := c.TokenStore.Get(ctx, c.ClientID, c.APIBase)
+	if err != nil {
+		c.log(ctx, slog.LevelWarn, "paypal: reading token store", "error", err)
+	} else if t.valid() && t.Token != rejected {
+		return t, nil
+	}
+
+	t, err = c.GetAccessTokenContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.TokenStore.Put(ctx, c.ClientID, c.APIBase, t); err != nil {
+		c.log(ctx, slog.LevelWarn, "paypal: writing token store", "error", err)
+	}
+
+	return t, nil
+}
+
+// invalidateToken drops t so that the next request fetches a new token. It
+// is a no-op if t was already replaced by another caller
+func (c *Client) invalidateToken(t *TokenResp) {
+	c.tokenMu.Lock()
+	if c.Token == t {
+		c.Token = nil
+	}
+	c.rejectedToken = t.Token
+	c.tokenMu.Unlock()
+}
+
+// isInvalidToken reports whether err was caused by the API rejecting the
+// access token
+func isInvalidToken(err error) bool {
+	var errResp *ErrorResponse
+	if !errors.As(err, &errResp) || errResp.Response == nil {
+		return false
+	}
+
+	return errResp.Response.StatusCode == http.StatusUnauthorized && errResp.ErrorCode == "invalid_token"
+}
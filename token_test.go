@@ -0,0 +1,95 @@
+package paypal
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConcurrentTokenRefresh(t *testing.T) {
+	Convey("Cancelling the caller that started a token refresh should not fail the others", t, func() {
+		tokenRequested := make(chan struct{})
+		release := make(chan struct{})
+		var tokenCalls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				if atomic.AddInt32(&tokenCalls, 1) == 1 {
+					close(tokenRequested)
+				}
+				<-release
+				writeToken(w, "fresh")
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer fresh" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_token","error_description":"Token signature verification failed"}`))
+				return
+			}
+			w.Write([]byte(`{"id":"PAY-1"}`))
+		})
+		c.Token = &TokenResp{Token: "revoked", ExpiresAt: time.Now().Add(time.Hour)}
+
+		send := func(ctx context.Context) error {
+			req, err := NewRequestContext(ctx, "GET", c.APIBase+"/payments/payment/PAY-1", nil)
+			if err != nil {
+				return err
+			}
+			return c.SendWithAuth(req, &Payment{})
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error, 1)
+		go func() { first <- send(ctx) }()
+		<-tokenRequested
+
+		const waiters = 8
+		var wg sync.WaitGroup
+		errs := make(chan error, waiters)
+		for i := 0; i < waiters; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				errs <- send(context.Background())
+			}()
+		}
+
+		cancel()
+		So(errors.Is(<-first, context.Canceled), ShouldBeTrue)
+
+		close(release)
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			So(err, ShouldBeNil)
+		}
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 1)
+		So(c.Token.Token, ShouldEqual, "fresh")
+	})
+
+	Convey("A token refresh that timed out should be tried again by a waiting caller", t, func() {
+		var tokenCalls int32
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				if atomic.AddInt32(&tokenCalls, 1) == 1 {
+					time.Sleep(200 * time.Millisecond)
+				}
+				writeToken(w, "fresh")
+				return
+			}
+			w.Write([]byte(`{"id":"PAY-1"}`))
+		}, WithTimeout(50*time.Millisecond))
+
+		token, err := c.accessToken(context.Background())
+
+		So(err, ShouldBeNil)
+		So(token.Token, ShouldEqual, "fresh")
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 2)
+	})
+}
@@ -0,0 +1,133 @@
+package paypal
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+type (
+	// TokenStore caches access tokens outside of a Client, so that they can
+	// be shared by several clients or processes and survive restarts.
+	// Tokens are keyed by client ID and API base
+	TokenStore interface {
+		// Get returns the stored token, or nil if there is none
+		Get(ctx context.Context, clientID, apiBase string) (*TokenResp, error)
+
+		// Put stores t, replacing any previous token
+		Put(ctx context.Context, clientID, apiBase string, t *TokenResp) error
+	}
+
+	// MemoryTokenStore is a TokenStore that keeps tokens in memory. It can
+	// be shared by several clients of the same process
+	MemoryTokenStore struct {
+		mu     sync.Mutex
+		tokens map[string]TokenResp
+	}
+
+	// FileTokenStore is a TokenStore that keeps each token in a JSON file
+	// of its own in a directory, so that it can be shared by processes
+	// running on the same host
+	FileTokenStore struct {
+		Dir string
+	}
+)
+
+// NewMemoryTokenStore returns an empty MemoryTokenStore
+func NewMemoryTokenStore() *MemoryTokenStore {
+	return &MemoryTokenStore{tokens: make(map[string]TokenResp)}
+}
+
+// Get implements TokenStore
+func (s *MemoryTokenStore) Get(ctx context.Context, clientID, apiBase string) (*TokenResp, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[tokenStoreKey(clientID, apiBase)]
+	if !ok {
+		return nil, nil
+	}
+
+	return &t, nil
+}
+
+// Put implements TokenStore
+func (s *MemoryTokenStore) Put(ctx context.Context, clientID, apiBase string, t *TokenResp) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tokens == nil {
+		s.tokens = make(map[string]TokenResp)
+	}
+	s.tokens[tokenStoreKey(clientID, apiBase)] = *t
+
+	return nil
+}
+
+// NewFileTokenStore returns a FileTokenStore writing to dir. The directory
+// is created if needed
+func NewFileTokenStore(dir string) (*FileTokenStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileTokenStore{Dir: dir}, nil
+}
+
+// Get implements TokenStore
+func (s *FileTokenStore) Get(ctx context.Context, clientID, apiBase string) (*TokenResp, error) {
+	data, err := ioutil.ReadFile(s.path(clientID, apiBase))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	t := &TokenResp{}
+	if err := json.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Put implements TokenStore. The file is replaced atomically so that
+// concurrent readers never see a partial token
+func (s *FileTokenStore) Put(ctx context.Context, clientID, apiBase string, t *TokenResp) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+
+	f, err := ioutil.TempFile(s.Dir, ".token-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), s.path(clientID, apiBase))
+}
+
+func (s *FileTokenStore) path(clientID, apiBase string) string {
+	return filepath.Join(s.Dir, tokenStoreKey(clientID, apiBase)+".json")
+}
+
+// tokenStoreKey derives a key that is safe to use as a file name
+func tokenStoreKey(clientID, apiBase string) string {
+	h := sha256.Sum256([]byte(clientID + "\n" + apiBase))
+	return hex.EncodeToString(h[:])
+}
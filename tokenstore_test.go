@@ -0,0 +1,179 @@
+package paypal
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTokenStores(t *testing.T) {
+	ctx := context.Background()
+	token := &TokenResp{Token: "token", Type: "Bearer", ExpiresIn: 3600, ExpiresAt: time.Now().Add(time.Hour).Round(0)}
+
+	newFileStore := func() TokenStore {
+		s, err := NewFileTokenStore(filepath.Join(t.TempDir(), "tokens"))
+		So(err, ShouldBeNil)
+		return s
+	}
+
+	for name, newStore := range map[string]func() TokenStore{
+		"MemoryTokenStore": func() TokenStore { return NewMemoryTokenStore() },
+		"FileTokenStore":   newFileStore,
+	} {
+		Convey(name+" should return the token it was given", t, func() {
+			s := newStore()
+
+			got, err := s.Get(ctx, "client-id", APIBaseSandBox)
+			So(err, ShouldBeNil)
+			So(got, ShouldBeNil)
+
+			So(s.Put(ctx, "client-id", APIBaseSandBox, token), ShouldBeNil)
+			got, err = s.Get(ctx, "client-id", APIBaseSandBox)
+			So(err, ShouldBeNil)
+			So(got.Token, ShouldEqual, token.Token)
+			So(got.ExpiresAt.Equal(token.ExpiresAt), ShouldBeTrue)
+
+			replaced := *token
+			replaced.Token = "replaced"
+			So(s.Put(ctx, "client-id", APIBaseSandBox, &replaced), ShouldBeNil)
+			got, err = s.Get(ctx, "client-id", APIBaseSandBox)
+			So(err, ShouldBeNil)
+			So(got.Token, ShouldEqual, "replaced")
+		})
+
+		Convey(name+" should keep the tokens of each client ID and API base apart", t, func() {
+			s := newStore()
+			So(s.Put(ctx, "client-id", APIBaseSandBox, token), ShouldBeNil)
+
+			got, err := s.Get(ctx, "other-client-id", APIBaseSandBox)
+			So(err, ShouldBeNil)
+			So(got, ShouldBeNil)
+			got, err = s.Get(ctx, "client-id", APIBaseLive)
+			So(err, ShouldBeNil)
+			So(got, ShouldBeNil)
+		})
+	}
+
+	Convey("FileTokenStore should report a corrupt token file", t, func() {
+		s, err := NewFileTokenStore(t.TempDir())
+		So(err, ShouldBeNil)
+		So(os.WriteFile(s.path("client-id", APIBaseSandBox), []byte(`{"access_token": "tok`), 0600), ShouldBeNil)
+
+		_, err = s.Get(ctx, "client-id", APIBaseSandBox)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("FileTokenStore should replace the token file atomically", t, func() {
+		s, err := NewFileTokenStore(t.TempDir())
+		So(err, ShouldBeNil)
+
+		var wg sync.WaitGroup
+		var failures int32
+		for i := 0; i < 4; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if s.Put(ctx, "client-id", APIBaseSandBox, token) != nil {
+						atomic.AddInt32(&failures, 1)
+					}
+				}
+			}()
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 50; j++ {
+					if got, err := s.Get(ctx, "client-id", APIBaseSandBox); err != nil || (got != nil && got.Token != "token") {
+						atomic.AddInt32(&failures, 1)
+					}
+				}
+			}()
+		}
+		wg.Wait()
+		So(atomic.LoadInt32(&failures), ShouldEqual, 0)
+
+		files, err := os.ReadDir(s.Dir)
+		So(err, ShouldBeNil)
+		So(files, ShouldHaveLength, 1)
+		So(files[0].Name(), ShouldEqual, tokenStoreKey("client-id", APIBaseSandBox)+".json")
+	})
+}
+
+func TestTokenStoreReuse(t *testing.T) {
+	ctx := context.Background()
+
+	// newClient returns a client whose server issues the "issued" token and
+	// only accepts that one and "valid", counting token requests
+	newClient := func(tokenCalls *int32) *Client {
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				atomic.AddInt32(tokenCalls, 1)
+				writeToken(w, "issued")
+				return
+			}
+			if auth := r.Header.Get("Authorization"); auth != "Bearer issued" && auth != "Bearer valid" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error":"invalid_token","error_description":"Token is revoked"}`))
+				return
+			}
+			w.Write([]byte(`{"id":"PAY-1"}`))
+		})
+		c.TokenStore = NewMemoryTokenStore()
+		return c
+	}
+	getPayment := func(c *Client) error {
+		_, err := c.GetPayment("PAY-1")
+		return err
+	}
+
+	Convey("A stored token that has not expired should be reused", t, func() {
+		var tokenCalls int32
+		c := newClient(&tokenCalls)
+		c.TokenStore.Put(ctx, c.ClientID, c.APIBase, &TokenResp{Token: "valid", ExpiresAt: time.Now().Add(time.Hour)})
+
+		So(getPayment(c), ShouldBeNil)
+		So(c.Token.Token, ShouldEqual, "valid")
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 0)
+	})
+
+	Convey("A stored token about to expire should be replaced in the store", t, func() {
+		var tokenCalls int32
+		c := newClient(&tokenCalls)
+		c.TokenStore.Put(ctx, c.ClientID, c.APIBase, &TokenResp{Token: "valid", ExpiresAt: time.Now().Add(time.Second)})
+
+		So(getPayment(c), ShouldBeNil)
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 1)
+		stored, _ := c.TokenStore.Get(ctx, c.ClientID, c.APIBase)
+		So(stored.Token, ShouldEqual, "issued")
+	})
+
+	Convey("A stored token rejected by the API should not be reused", t, func() {
+		var tokenCalls int32
+		c := newClient(&tokenCalls)
+		c.TokenStore.Put(ctx, c.ClientID, c.APIBase, &TokenResp{Token: "revoked", ExpiresAt: time.Now().Add(time.Hour)})
+
+		So(getPayment(c), ShouldBeNil)
+		So(c.Token.Token, ShouldEqual, "issued")
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 1)
+		stored, _ := c.TokenStore.Get(ctx, c.ClientID, c.APIBase)
+		So(stored.Token, ShouldEqual, "issued")
+	})
+
+	Convey("A token store shared by clients should save token requests", t, func() {
+		var tokenCalls int32
+		c := newClient(&tokenCalls)
+		other := NewClient(c.ClientID, c.Secret, c.APIBase)
+		other.TokenStore = c.TokenStore
+
+		So(getPayment(c), ShouldBeNil)
+		So(getPayment(other), ShouldBeNil)
+		So(atomic.LoadInt32(&tokenCalls), ShouldEqual, 1)
+	})
+}
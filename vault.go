@@ -1,6 +1,7 @@
 package paypal
 
 import (
+	"context"
 	"fmt"
 	"time"
 )
@@ -11,34 +12,144 @@ type (
 	// VaultRequest maps to vault_request object
 	VaultRequest struct {
 		CreditCard
-		MerchantID     string `json:"merchant_id,omitempty"`
-		ExternalCardID string `json:"external_card_id,omitempty"`
+		MerchantID         string `json:"merchant_id,omitempty"`
+		ExternalCardID     string `json:"external_card_id,omitempty"`
+		ExternalCustomerID string `json:"external_customer_id,omitempty"`
 	}
 
 	// VaultResponse maps to vault_response object
 	VaultResponse struct {
 		VaultRequest
-		CreateTime *time.Time `json: "create_time"`
-		UpdateTime *time.Time `json: "update_time"`
-		State      string     `json: "state"`
-		ValidUntil string     `json: "valid_until"`
-		Links      []Links    `json:"links"`
+		CreateTime *time.Time `json:"create_time,omitempty"`
+		UpdateTime *time.Time `json:"update_time,omitempty"`
+		State      string     `json:"state,omitempty"`
+		ValidUntil string     `json:"valid_until,omitempty"`
+		Links      []Links    `json:"links,omitempty"`
+	}
+
+	// ListStoredCreditCardsResp maps to the response of the list credit
+	// cards request
+	ListStoredCreditCardsResp struct {
+		Items      []VaultResponse `json:"items"`
+		TotalItems int             `json:"total_items,omitempty"`
+		TotalPages int             `json:"total_pages,omitempty"`
+		Links      []Links         `json:"links,omitempty"`
 	}
 )
 
 // StoreInVault will store credit card details with PayPal.
 func (c *Client) StoreInVault(cc VaultRequest) (*VaultResponse, error) {
+	return c.StoreInVaultContext(context.Background(), cc)
+}
+
+// StoreInVaultContext is like StoreInVault but uses ctx for the request
+func (c *Client) StoreInVaultContext(ctx context.Context, cc VaultRequest) (*VaultResponse, error) {
+
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/vault/credit-cards", c.APIBase), cc)
+
+	if err != nil {
+		return nil, err
+	}
+	v := &VaultResponse{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// GetStoredCreditCard returns a credit card stored in the vault
+func (c *Client) GetStoredCreditCard(cardID string) (*VaultResponse, error) {
+	return c.GetStoredCreditCardContext(context.Background(), cardID)
+}
+
+// GetStoredCreditCardContext is like GetStoredCreditCard but uses ctx for the request
+func (c *Client) GetStoredCreditCardContext(ctx context.Context, cardID string) (*VaultResponse, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/vault/credit-cards/%s", c.APIBase, cardID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &VaultResponse{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListStoredCreditCards retrieves the credit cards stored in the vault.
+// filter can set the page, page_size, total_required, sort_by and
+// sort_order query parameters, and restrict the cards to those of a
+// merchant_id, external_card_id or external_customer_id
+func (c *Client) ListStoredCreditCards(filter map[string]string) (*ListStoredCreditCardsResp, error) {
+	return c.ListStoredCreditCardsContext(context.Background(), filter)
+}
+
+// ListStoredCreditCardsContext is like ListStoredCreditCards but uses ctx for the request
+func (c *Client) ListStoredCreditCardsContext(ctx context.Context, filter map[string]string) (*ListStoredCreditCardsResp, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/vault/credit-cards", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
 
-	req, err := NewRequest("POST", fmt.Sprintf("%s/vault/credit-cards", c.APIBase), cc)
+	if filter != nil {
+		q := req.URL.Query()
 
+		for k, v := range filter {
+			q.Set(k, v)
+		}
+
+		req.URL.RawQuery = q.Encode()
+	}
+
+	v := &ListStoredCreditCardsResp{}
+
+	err = c.SendWithAuth(req, v)
 	if err != nil {
 		return nil, err
 	}
+
+	return v, nil
+}
+
+// UpdateStoredCreditCard applies a JSON Patch to a credit card stored in the
+// vault, e.g. to replace its expiry date, and returns the updated card
+func (c *Client) UpdateStoredCreditCard(cardID string, patches []Patch) (*VaultResponse, error) {
+	return c.UpdateStoredCreditCardContext(context.Background(), cardID, patches)
+}
+
+// UpdateStoredCreditCardContext is like UpdateStoredCreditCard but uses ctx for the request
+func (c *Client) UpdateStoredCreditCardContext(ctx context.Context, cardID string, patches []Patch) (*VaultResponse, error) {
+	req, err := NewRequestContext(ctx, "PATCH", fmt.Sprintf("%s/vault/credit-cards/%s", c.APIBase, cardID), patches)
+	if err != nil {
+		return nil, err
+	}
+
 	v := &VaultResponse{}
 
 	err = c.SendWithAuth(req, v)
 	if err != nil {
 		return nil, err
 	}
+
 	return v, nil
 }
+
+// DeleteStoredCreditCard deletes a credit card stored in the vault
+func (c *Client) DeleteStoredCreditCard(cardID string) error {
+	return c.DeleteStoredCreditCardContext(context.Background(), cardID)
+}
+
+// DeleteStoredCreditCardContext is like DeleteStoredCreditCard but uses ctx for the request
+func (c *Client) DeleteStoredCreditCardContext(ctx context.Context, cardID string) error {
+	req, err := NewRequestContext(ctx, "DELETE", fmt.Sprintf("%s/vault/credit-cards/%s", c.APIBase, cardID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
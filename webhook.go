@@ -0,0 +1,413 @@
+package paypal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/webhooks/
+
+// Event types webhooks can subscribe to
+var (
+	EventTypeAll                            EventType = "*"
+	EventTypePaymentAuthorizationCreated    EventType = "PAYMENT.AUTHORIZATION.CREATED"
+	EventTypePaymentAuthorizationVoided     EventType = "PAYMENT.AUTHORIZATION.VOIDED"
+	EventTypePaymentCaptureCompleted        EventType = "PAYMENT.CAPTURE.COMPLETED"
+	EventTypePaymentCaptureDenied           EventType = "PAYMENT.CAPTURE.DENIED"
+	EventTypePaymentCapturePending          EventType = "PAYMENT.CAPTURE.PENDING"
+	EventTypePaymentCaptureRefunded         EventType = "PAYMENT.CAPTURE.REFUNDED"
+	EventTypePaymentCaptureReversed         EventType = "PAYMENT.CAPTURE.REVERSED"
+	EventTypePaymentSaleCompleted           EventType = "PAYMENT.SALE.COMPLETED"
+	EventTypePaymentSaleDenied              EventType = "PAYMENT.SALE.DENIED"
+	EventTypePaymentSalePending             EventType = "PAYMENT.SALE.PENDING"
+	EventTypePaymentSaleRefunded            EventType = "PAYMENT.SALE.REFUNDED"
+	EventTypePaymentSaleReversed            EventType = "PAYMENT.SALE.REVERSED"
+	EventTypeBillingPlanCreated             EventType = "BILLING.PLAN.CREATED"
+	EventTypeBillingPlanUpdated             EventType = "BILLING.PLAN.UPDATED"
+	EventTypeBillingSubscriptionCreated     EventType = "BILLING.SUBSCRIPTION.CREATED"
+	EventTypeBillingSubscriptionCancelled   EventType = "BILLING.SUBSCRIPTION.CANCELLED"
+	EventTypeBillingSubscriptionReActivated EventType = "BILLING.SUBSCRIPTION.RE-ACTIVATED"
+	EventTypeBillingSubscriptionSuspended   EventType = "BILLING.SUBSCRIPTION.SUSPENDED"
+	EventTypeBillingSubscriptionUpdated     EventType = "BILLING.SUBSCRIPTION.UPDATED"
+	EventTypeVaultCreditCardCreated         EventType = "VAULT.CREDIT-CARD.CREATED"
+	EventTypeVaultCreditCardDeleted         EventType = "VAULT.CREDIT-CARD.DELETED"
+	EventTypeVaultCreditCardUpdated         EventType = "VAULT.CREDIT-CARD.UPDATED"
+	EventTypeInvoicingInvoiceCancelled      EventType = "INVOICING.INVOICE.CANCELLED"
+	EventTypeInvoicingInvoiceCreated        EventType = "INVOICING.INVOICE.CREATED"
+	EventTypeInvoicingInvoicePaid           EventType = "INVOICING.INVOICE.PAID"
+	EventTypeInvoicingInvoiceRefunded       EventType = "INVOICING.INVOICE.REFUNDED"
+	EventTypeInvoicingInvoiceUpdated        EventType = "INVOICING.INVOICE.UPDATED"
+	EventTypePaymentPayoutsBatchSuccess     EventType = "PAYMENT.PAYOUTSBATCH.SUCCESS"
+	EventTypePaymentPayoutsItemSucceeded    EventType = "PAYMENT.PAYOUTS-ITEM.SUCCEEDED"
+)
+
+var (
+	VerificationStatusSuccess VerificationStatus = "SUCCESS"
+	VerificationStatusFailure VerificationStatus = "FAILURE"
+)
+
+type (
+	EventType          string
+	VerificationStatus string
+
+	// Webhook maps to webhook object
+	Webhook struct {
+		ID         string             `json:"id,omitempty"`
+		URL        string             `json:"url"`
+		EventTypes []WebhookEventType `json:"event_types"`
+		Links      []Links            `json:"links,omitempty"`
+	}
+
+	// WebhookEventType maps to event_type object
+	WebhookEventType struct {
+		Name             EventType `json:"name"`
+		Description      string    `json:"description,omitempty"`
+		Status           string    `json:"status,omitempty"`
+		ResourceVersions []string  `json:"resource_versions,omitempty"`
+	}
+
+	// WebhookEvent maps to event object. Resource is left undecoded, as its
+	// type depends on ResourceType
+	WebhookEvent struct {
+		ID              string          `json:"id"`
+		CreateTime      *time.Time      `json:"create_time,omitempty"`
+		ResourceType    string          `json:"resource_type,omitempty"`
+		EventVersion    string          `json:"event_version,omitempty"`
+		EventType       EventType       `json:"event_type"`
+		Summary         string          `json:"summary,omitempty"`
+		ResourceVersion string          `json:"resource_version,omitempty"`
+		Resource        json.RawMessage `json:"resource,omitempty"`
+		Status          string          `json:"status,omitempty"`
+		Links           []Links         `json:"links,omitempty"`
+	}
+
+	// ListWebhooksResp maps to webhook_list object
+	ListWebhooksResp struct {
+		Webhooks []Webhook `json:"webhooks"`
+	}
+
+	// ListWebhookEventTypesResp maps to event_type_list object
+	ListWebhookEventTypesResp struct {
+		EventTypes []WebhookEventType `json:"event_types"`
+	}
+
+	// ListWebhookEventsResp maps to event_list object
+	ListWebhookEventsResp struct {
+		Events []WebhookEvent `json:"events"`
+		Count  int            `json:"count"`
+		Links  []Links        `json:"links,omitempty"`
+	}
+
+	// SimulateWebhookEventReq maps to the body of the simulate event request
+	SimulateWebhookEventReq struct {
+		WebhookID       string    `json:"webhook_id,omitempty"`
+		URL             string    `json:"url,omitempty"`
+		EventType       EventType `json:"event_type"`
+		ResourceVersion string    `json:"resource_version,omitempty"`
+	}
+
+	// VerifyWebhookSignatureReq maps to verify_webhook_signature object.
+	// WebhookEvent must be the body of the notification, as received
+	VerifyWebhookSignatureReq struct {
+		AuthAlgo         string          `json:"auth_algo"`
+		CertURL          string          `json:"cert_url"`
+		TransmissionID   string          `json:"transmission_id"`
+		TransmissionSig  string          `json:"transmission_sig"`
+		TransmissionTime string          `json:"transmission_time"`
+		WebhookID        string          `json:"webhook_id"`
+		WebhookEvent     json.RawMessage `json:"webhook_event"`
+	}
+
+	// VerifyWebhookSignatureResp maps to verify_webhook_signature_response
+	// object
+	VerifyWebhookSignatureResp struct {
+		VerificationStatus VerificationStatus `json:"verification_status"`
+	}
+)
+
+// CreateWebhook subscribes url to the events of eventTypes, which can be
+// EventTypeAll to subscribe it to every event
+func (c *Client) CreateWebhook(url string, eventTypes []EventType) (*Webhook, error) {
+	return c.CreateWebhookContext(context.Background(), url, eventTypes)
+}
+
+// CreateWebhookContext is like CreateWebhook but uses ctx for the request
+func (c *Client) CreateWebhookContext(ctx context.Context, url string, eventTypes []EventType) (*Webhook, error) {
+	w := Webhook{URL: url, EventTypes: make([]WebhookEventType, len(eventTypes))}
+	for i, name := range eventTypes {
+		w.EventTypes[i].Name = name
+	}
+
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/notifications/webhooks", c.APIBase), w)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Webhook{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListWebhooks returns the webhooks of the application
+func (c *Client) ListWebhooks() ([]Webhook, error) {
+	return c.ListWebhooksContext(context.Background())
+}
+
+// ListWebhooksContext is like ListWebhooks but uses ctx for the request
+func (c *Client) ListWebhooksContext(ctx context.Context) ([]Webhook, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v ListWebhooksResp
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Webhooks, nil
+}
+
+// GetWebhook returns a webhook by ID
+func (c *Client) GetWebhook(webhookID string) (*Webhook, error) {
+	return c.GetWebhookContext(context.Background(), webhookID)
+}
+
+// GetWebhookContext is like GetWebhook but uses ctx for the request
+func (c *Client) GetWebhookContext(ctx context.Context, webhookID string) (*Webhook, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks/%s", c.APIBase, webhookID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Webhook{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateWebhook applies a JSON Patch to a webhook, whose url and
+// event_types can be replaced, and returns the updated webhook
+func (c *Client) UpdateWebhook(webhookID string, patches []Patch) (*Webhook, error) {
+	return c.UpdateWebhookContext(context.Background(), webhookID, patches)
+}
+
+// UpdateWebhookContext is like UpdateWebhook but uses ctx for the request
+func (c *Client) UpdateWebhookContext(ctx context.Context, webhookID string, patches []Patch) (*Webhook, error) {
+	req, err := NewRequestContext(ctx, "PATCH", fmt.Sprintf("%s/notifications/webhooks/%s", c.APIBase, webhookID), patches)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Webhook{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// DeleteWebhook deletes a webhook
+func (c *Client) DeleteWebhook(webhookID string) error {
+	return c.DeleteWebhookContext(context.Background(), webhookID)
+}
+
+// DeleteWebhookContext is like DeleteWebhook but uses ctx for the request
+func (c *Client) DeleteWebhookContext(ctx context.Context, webhookID string) error {
+	req, err := NewRequestContext(ctx, "DELETE", fmt.Sprintf("%s/notifications/webhooks/%s", c.APIBase, webhookID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// ListWebhookEventTypes returns the event types a webhook is subscribed to
+func (c *Client) ListWebhookEventTypes(webhookID string) ([]WebhookEventType, error) {
+	return c.ListWebhookEventTypesContext(context.Background(), webhookID)
+}
+
+// ListWebhookEventTypesContext is like ListWebhookEventTypes but uses ctx for the request
+func (c *Client) ListWebhookEventTypesContext(ctx context.Context, webhookID string) ([]WebhookEventType, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks/%s/event-types", c.APIBase, webhookID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v ListWebhookEventTypesResp
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.EventTypes, nil
+}
+
+// ListAvailableEventTypes returns the event types webhooks can subscribe to
+func (c *Client) ListAvailableEventTypes() ([]WebhookEventType, error) {
+	return c.ListAvailableEventTypesContext(context.Background())
+}
+
+// ListAvailableEventTypesContext is like ListAvailableEventTypes but uses ctx for the request
+func (c *Client) ListAvailableEventTypesContext(ctx context.Context) ([]WebhookEventType, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks-event-types", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v ListWebhookEventTypesResp
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.EventTypes, nil
+}
+
+// ListWebhookEvents retrieves the webhook events of the last 30 days.
+// filter can set the start_time and end_time of the range to list, as
+// RFC 3339 timestamps, and the page_size, transaction_id and event_type
+// query parameters
+func (c *Client) ListWebhookEvents(filter map[string]string) (*ListWebhookEventsResp, error) {
+	return c.ListWebhookEventsContext(context.Background(), filter)
+}
+
+// ListWebhookEventsContext is like ListWebhookEvents but uses ctx for the request
+func (c *Client) ListWebhookEventsContext(ctx context.Context, filter map[string]string) (*ListWebhookEventsResp, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks-events", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if filter != nil {
+		q := req.URL.Query()
+
+		for k, v := range filter {
+			q.Set(k, v)
+		}
+
+		req.URL.RawQuery = q.Encode()
+	}
+
+	v := &ListWebhookEventsResp{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetWebhookEvent returns a webhook event by ID
+func (c *Client) GetWebhookEvent(eventID string) (*WebhookEvent, error) {
+	return c.GetWebhookEventContext(context.Background(), eventID)
+}
+
+// GetWebhookEventContext is like GetWebhookEvent but uses ctx for the request
+func (c *Client) GetWebhookEventContext(ctx context.Context, eventID string) (*WebhookEvent, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/notifications/webhooks-events/%s", c.APIBase, eventID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &WebhookEvent{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ResendWebhookEvent sends a webhook event again to the webhooks of
+// webhookIDs, or to all the webhooks it was sent to if webhookIDs is empty
+func (c *Client) ResendWebhookEvent(eventID string, webhookIDs []string) (*WebhookEvent, error) {
+	return c.ResendWebhookEventContext(context.Background(), eventID, webhookIDs)
+}
+
+// ResendWebhookEventContext is like ResendWebhookEvent but uses ctx for the request
+func (c *Client) ResendWebhookEventContext(ctx context.Context, eventID string, webhookIDs []string) (*WebhookEvent, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/notifications/webhooks-events/%s/resend", c.APIBase, eventID), struct {
+		WebhookIDs []string `json:"webhook_ids,omitempty"`
+	}{
+		webhookIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	v := &WebhookEvent{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// SimulateWebhookEvent sends a sample event to a webhook, identified by
+// either its WebhookID or URL, to test its handling
+func (c *Client) SimulateWebhookEvent(s SimulateWebhookEventReq) (*WebhookEvent, error) {
+	return c.SimulateWebhookEventContext(context.Background(), s)
+}
+
+// SimulateWebhookEventContext is like SimulateWebhookEvent but uses ctx for the request
+func (c *Client) SimulateWebhookEventContext(ctx context.Context, s SimulateWebhookEventReq) (*WebhookEvent, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/notifications/simulate-event", c.APIBase), s)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &WebhookEvent{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// VerifyWebhookSignature asks Paypal whether a webhook notification was
+// sent by it. See APIVerifier for verifying the notifications received by
+// a WebhookHandler this way
+func (c *Client) VerifyWebhookSignature(v VerifyWebhookSignatureReq) (*VerifyWebhookSignatureResp, error) {
+	return c.VerifyWebhookSignatureContext(context.Background(), v)
+}
+
+// VerifyWebhookSignatureContext is like VerifyWebhookSignature but uses ctx for the request
+func (c *Client) VerifyWebhookSignatureContext(ctx context.Context, v VerifyWebhookSignatureReq) (*VerifyWebhookSignatureResp, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/notifications/verify-webhook-signature", c.APIBase), v)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &VerifyWebhookSignatureResp{}
+
+	err = c.SendWithAuth(req, resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,429 @@
+package paypal
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// https://developer.paypal.com/docs/api/webhooks/#verify-webhook-signature
+
+// Headers Paypal signs webhook notifications with
+const (
+	TransmissionIDHeader   = "Paypal-Transmission-Id"
+	TransmissionTimeHeader = "Paypal-Transmission-Time"
+	TransmissionSigHeader  = "Paypal-Transmission-Sig"
+	CertURLHeader          = "Paypal-Cert-Url"
+	AuthAlgoHeader         = "Paypal-Auth-Algo"
+)
+
+// maxWebhookBody is the size above which a WebhookHandler rejects a
+// notification
+const maxWebhookBody = 1 << 20
+
+// ErrWebhookSignature is returned by a WebhookVerifier when a notification
+// was not signed by Paypal for the webhook
+var ErrWebhookSignature = errors.New("paypal: invalid webhook signature")
+
+type (
+	// WebhookHeaders holds the transmission headers of a webhook
+	// notification, which its signature is verified with
+	WebhookHeaders struct {
+		TransmissionID   string
+		TransmissionTime string
+		TransmissionSig  string
+		CertURL          string
+		AuthAlgo         string
+	}
+
+	// WebhookVerifier verifies that body was sent by Paypal to the webhook
+	// with ID webhookID. It returns an error wrapping ErrWebhookSignature
+	// if the signature is invalid, and other errors if it could not be
+	// checked
+	WebhookVerifier interface {
+		VerifyWebhook(ctx context.Context, webhookID string, h WebhookHeaders, body []byte) error
+	}
+
+	// CertCache keeps the certificates an OfflineVerifier fetched, keyed by
+	// their URL
+	CertCache interface {
+		// Get returns the certificate fetched from certURL, or nil if there
+		// is none
+		Get(certURL string) *x509.Certificate
+
+		// Put stores cert, replacing any previous certificate
+		Put(certURL string, cert *x509.Certificate)
+	}
+
+	// MemoryCertCache is a CertCache that keeps certificates in memory
+	MemoryCertCache struct {
+		mu    sync.Mutex
+		certs map[string]*x509.Certificate
+	}
+
+	// OfflineVerifier verifies webhook notifications without calling the
+	// API: it checks the CRC32 of the body and the RSA signature of the
+	// transmission with the certificate at the URL of the notification,
+	// whose chain must verify against Roots
+	OfflineVerifier struct {
+		// FetchCert returns the PEM encoded certificate chain at certURL.
+		// The default only fetches certificates over https from paypal.com
+		FetchCert func(ctx context.Context, certURL string) ([]byte, error)
+
+		// Cache keeps certificates until they expire. Defaults to an
+		// in-memory cache
+		Cache CertCache
+
+		// Roots verifies the certificate chains. nil uses the system roots
+		Roots *x509.CertPool
+
+		cache MemoryCertCache
+	}
+
+	// APIVerifier verifies webhook notifications by calling the
+	// verify-webhook-signature API with Client
+	APIVerifier struct {
+		Client *Client
+	}
+
+	// WebhookHandlerFunc handles a verified webhook event. An error makes
+	// the WebhookHandler respond with a server error, so that Paypal sends
+	// the notification again later
+	WebhookHandlerFunc func(ctx context.Context, e *WebhookEvent) error
+
+	// WebhookHandler is an http.Handler receiving the notifications of a
+	// webhook. It verifies their signature, then calls the functions
+	// registered for their event type
+	WebhookHandler struct {
+		// WebhookID is the ID of the webhook notifications are sent for
+		WebhookID string
+
+		// Verifier verifies the signature of notifications
+		Verifier WebhookVerifier
+
+		// Logger, if set, receives the notifications that were rejected or
+		// failed to be handled
+		Logger Logger
+
+		mu       sync.RWMutex
+		handlers map[EventType][]WebhookHandlerFunc
+	}
+)
+
+// NewWebhookHandler returns a WebhookHandler for the webhook with ID
+// webhookID, verifying notifications with v
+func NewWebhookHandler(webhookID string, v WebhookVerifier) *WebhookHandler {
+	return &WebhookHandler{WebhookID: webhookID, Verifier: v}
+}
+
+// WebhookHeadersFromRequest returns the transmission headers of r
+func WebhookHeadersFromRequest(r *http.Request) WebhookHeaders {
+	return WebhookHeaders{
+		TransmissionID:   r.Header.Get(TransmissionIDHeader),
+		TransmissionTime: r.Header.Get(TransmissionTimeHeader),
+		TransmissionSig:  r.Header.Get(TransmissionSigHeader),
+		CertURL:          r.Header.Get(CertURLHeader),
+		AuthAlgo:         r.Header.Get(AuthAlgoHeader),
+	}
+}
+
+// Handle registers fn for the events of eventType. Functions registered
+// for EventTypeAll are called for every event, after those registered for
+// its type
+func (h *WebhookHandler) Handle(eventType EventType, fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.handlers == nil {
+		h.handlers = make(map[EventType][]WebhookHandlerFunc)
+	}
+	h.handlers[eventType] = append(h.handlers[eventType], fn)
+}
+
+// HandleSale registers fn for the events of eventType, with their resource
+// decoded as a Sale
+func (h *WebhookHandler) HandleSale(eventType EventType, fn func(ctx context.Context, e *WebhookEvent, s *Sale) error) {
+	h.Handle(eventType, func(ctx context.Context, e *WebhookEvent) error {
+		s, err := e.Sale()
+		if err != nil {
+			return err
+		}
+		return fn(ctx, e, s)
+	})
+}
+
+// HandleRefund registers fn for the events of eventType, with their
+// resource decoded as a Refund
+func (h *WebhookHandler) HandleRefund(eventType EventType, fn func(ctx context.Context, e *WebhookEvent, r *Refund) error) {
+	h.Handle(eventType, func(ctx context.Context, e *WebhookEvent) error {
+		r, err := e.Refund()
+		if err != nil {
+			return err
+		}
+		return fn(ctx, e, r)
+	})
+}
+
+// HandleAuthorization registers fn for the events of eventType, with their
+// resource decoded as an Authorization
+func (h *WebhookHandler) HandleAuthorization(eventType EventType, fn func(ctx context.Context, e *WebhookEvent, a *Authorization) error) {
+	h.Handle(eventType, func(ctx context.Context, e *WebhookEvent) error {
+		a, err := e.Authorization()
+		if err != nil {
+			return err
+		}
+		return fn(ctx, e, a)
+	})
+}
+
+// ServeHTTP implements http.Handler. It responds 401 to notifications
+// whose signature is invalid, and 500 when they could not be verified or a
+// registered function failed
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	body, err := ioutil.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+	if err != nil || len(body) > maxWebhookBody {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	if h.Verifier == nil {
+		h.log(ctx, slog.LevelError, "paypal: webhook handler has no verifier")
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	headers := WebhookHeadersFromRequest(r)
+	if err := h.Verifier.VerifyWebhook(ctx, h.WebhookID, headers, body); err != nil {
+		h.log(ctx, slog.LevelWarn, "paypal: webhook notification rejected",
+			"transmission_id", headers.TransmissionID, "error", err)
+		if errors.Is(err, ErrWebhookSignature) {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		} else {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	var e WebhookEvent
+	if err := json.Unmarshal(body, &e); err != nil {
+		http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+		return
+	}
+
+	h.mu.RLock()
+	handlers := append(append([]WebhookHandlerFunc(nil), h.handlers[e.EventType]...), h.handlers[EventTypeAll]...)
+	h.mu.RUnlock()
+
+	for _, fn := range handlers {
+		if err := fn(ctx, &e); err != nil {
+			h.log(ctx, slog.LevelError, "paypal: webhook event handling failed",
+				"event_id", e.ID, "event_type", e.EventType, "error", err)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *WebhookHandler) log(ctx context.Context, level slog.Level, msg string, args ...interface{}) {
+	if h.Logger != nil {
+		h.Logger.Log(ctx, level, msg, args...)
+	}
+}
+
+// Sale decodes the resource of the event as a Sale
+func (e *WebhookEvent) Sale() (*Sale, error) {
+	v := &Sale{}
+	if err := json.Unmarshal(e.Resource, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Refund decodes the resource of the event as a Refund
+func (e *WebhookEvent) Refund() (*Refund, error) {
+	v := &Refund{}
+	if err := json.Unmarshal(e.Resource, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// Authorization decodes the resource of the event as an Authorization
+func (e *WebhookEvent) Authorization() (*Authorization, error) {
+	v := &Authorization{}
+	if err := json.Unmarshal(e.Resource, v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// NewMemoryCertCache returns an empty MemoryCertCache
+func NewMemoryCertCache() *MemoryCertCache {
+	return &MemoryCertCache{}
+}
+
+// Get implements CertCache
+func (c *MemoryCertCache) Get(certURL string) *x509.Certificate {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.certs[certURL]
+}
+
+// Put implements CertCache
+func (c *MemoryCertCache) Put(certURL string, cert *x509.Certificate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.certs == nil {
+		c.certs = make(map[string]*x509.Certificate)
+	}
+	c.certs[certURL] = cert
+}
+
+// VerifyWebhook implements WebhookVerifier
+func (v *OfflineVerifier) VerifyWebhook(ctx context.Context, webhookID string, h WebhookHeaders, body []byte) error {
+	if h.AuthAlgo != "SHA256withRSA" {
+		return fmt.Errorf("%w: unsupported algorithm %q", ErrWebhookSignature, h.AuthAlgo)
+	}
+	sig, err := base64.StdEncoding.DecodeString(h.TransmissionSig)
+	if err != nil {
+		return fmt.Errorf("%w: malformed signature", ErrWebhookSignature)
+	}
+
+	cert, err := v.cert(ctx, h.CertURL)
+	if err != nil {
+		return err
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: certificate has no RSA key", ErrWebhookSignature)
+	}
+
+	signed := fmt.Sprintf("%s|%s|%s|%d", h.TransmissionID, h.TransmissionTime, webhookID, crc32.ChecksumIEEE(body))
+	hash := sha256.Sum256([]byte(signed))
+	if err := rsa.VerifyPKCS1v15(pub, crypto.SHA256, hash[:], sig); err != nil {
+		return fmt.Errorf("%w: %v", ErrWebhookSignature, err)
+	}
+
+	return nil
+}
+
+// cert returns the verified certificate at certURL, from the cache if it
+// has not expired
+func (v *OfflineVerifier) cert(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	cache := v.Cache
+	if cache == nil {
+		cache = &v.cache
+	}
+	if cert := cache.Get(certURL); cert != nil && time.Now().Before(cert.NotAfter) {
+		return cert, nil
+	}
+
+	fetch := v.FetchCert
+	if fetch == nil {
+		fetch = fetchCert
+	}
+	data, err := fetch(ctx, certURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var chain []*x509.Certificate
+	for block, rest := pem.Decode(data); block != nil; block, rest = pem.Decode(rest) {
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrWebhookSignature, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: no certificate at %s", ErrWebhookSignature, certURL)
+	}
+
+	opts := x509.VerifyOptions{Roots: v.Roots, Intermediates: x509.NewCertPool()}
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	leaf := chain[0]
+	if _, err := leaf.Verify(opts); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrWebhookSignature, err)
+	}
+	if cn := leaf.Subject.CommonName; !strings.HasPrefix(cn, "messageverificationcerts.") || !strings.HasSuffix(cn, ".paypal.com") {
+		return nil, fmt.Errorf("%w: certificate issued to %q", ErrWebhookSignature, cn)
+	}
+	cache.Put(certURL, leaf)
+
+	return leaf, nil
+}
+
+// fetchCert is the default OfflineVerifier.FetchCert
+func fetchCert(ctx context.Context, certURL string) ([]byte, error) {
+	u, err := url.Parse(certURL)
+	if err != nil || u.Scheme != "https" || !strings.HasSuffix(u.Hostname(), ".paypal.com") {
+		return nil, fmt.Errorf("%w: certificate URL %q is not on paypal.com", ErrWebhookSignature, certURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("paypal: fetching certificate %s: %s", certURL, resp.Status)
+	}
+
+	return ioutil.ReadAll(io.LimitReader(resp.Body, maxWebhookBody))
+}
+
+// VerifyWebhook implements WebhookVerifier
+func (v APIVerifier) VerifyWebhook(ctx context.Context, webhookID string, h WebhookHeaders, body []byte) error {
+	resp, err := v.Client.VerifyWebhookSignatureContext(ctx, VerifyWebhookSignatureReq{
+		AuthAlgo:         h.AuthAlgo,
+		CertURL:          h.CertURL,
+		TransmissionID:   h.TransmissionID,
+		TransmissionSig:  h.TransmissionSig,
+		TransmissionTime: h.TransmissionTime,
+		WebhookID:        webhookID,
+		WebhookEvent:     json.RawMessage(body),
+	})
+	if err != nil {
+		return err
+	}
+	if resp.VerificationStatus != VerificationStatusSuccess {
+		return fmt.Errorf("%w: verification status %s", ErrWebhookSignature, resp.VerificationStatus)
+	}
+
+	return nil
+}
@@ -0,0 +1,179 @@
+package paypal
+
+import (
+	"context"
+	"fmt"
+)
+
+// https://developer.paypal.com/docs/api/payment-experience/v1/
+
+var (
+	LandingPageTypeBilling LandingPageType = "Billing"
+	LandingPageTypeLogin   LandingPageType = "Login"
+
+	UserActionCommit UserAction = "commit"
+
+	NoShippingDisplay     NoShipping = 0
+	NoShippingHide        NoShipping = 1
+	NoShippingFromAccount NoShipping = 2
+)
+
+type (
+	LandingPageType string
+	UserAction      string
+
+	// NoShipping tells whether the Paypal pages display shipping address
+	// fields
+	NoShipping int
+
+	// WebProfile maps to web_profile object. Set its ID as the
+	// ExperienceProfileID of a Payment to apply it to the payment
+	WebProfile struct {
+		ID           string        `json:"id,omitempty"`
+		Name         string        `json:"name"`
+		Temporary    bool          `json:"temporary,omitempty"`
+		FlowConfig   *FlowConfig   `json:"flow_config,omitempty"`
+		InputFields  *InputFields  `json:"input_fields,omitempty"`
+		Presentation *Presentation `json:"presentation,omitempty"`
+	}
+
+	// FlowConfig maps to flow_config object
+	FlowConfig struct {
+		LandingPageType     LandingPageType `json:"landing_page_type,omitempty"`
+		BankTXNPendingURL   string          `json:"bank_txn_pending_url,omitempty"`
+		UserAction          UserAction      `json:"user_action,omitempty"`
+		ReturnURIHTTPMethod string          `json:"return_uri_http_method,omitempty"`
+	}
+
+	// InputFields maps to input_fields object. AddressOverride set to 1
+	// displays the shipping address of the payment instead of the one on
+	// file with Paypal. Zero values are sent too, as they are meaningful
+	InputFields struct {
+		AllowNote       bool       `json:"allow_note"`
+		NoShipping      NoShipping `json:"no_shipping"`
+		AddressOverride int        `json:"address_override"`
+	}
+
+	// Presentation maps to presentation object, the branding of the Paypal
+	// pages
+	Presentation struct {
+		BrandName         string `json:"brand_name,omitempty"`
+		LogoImage         string `json:"logo_image,omitempty"`
+		LocaleCode        string `json:"locale_code,omitempty"`
+		ReturnURLLabel    string `json:"return_url_label,omitempty"`
+		NoteToSellerLabel string `json:"note_to_seller_label,omitempty"`
+	}
+)
+
+// CreateWebProfile creates a web experience profile. The returned profile is
+// p along with its ID
+func (c *Client) CreateWebProfile(p WebProfile) (*WebProfile, error) {
+	return c.CreateWebProfileContext(context.Background(), p)
+}
+
+// CreateWebProfileContext is like CreateWebProfile but uses ctx for the request
+func (c *Client) CreateWebProfileContext(ctx context.Context, p WebProfile) (*WebProfile, error) {
+	req, err := NewRequestContext(ctx, "POST", fmt.Sprintf("%s/payment-experience/web-profiles", c.APIBase), p)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &p
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListWebProfiles returns the web experience profiles of the merchant,
+// except the temporary ones
+func (c *Client) ListWebProfiles() ([]WebProfile, error) {
+	return c.ListWebProfilesContext(context.Background())
+}
+
+// ListWebProfilesContext is like ListWebProfiles but uses ctx for the request
+func (c *Client) ListWebProfilesContext(ctx context.Context) ([]WebProfile, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payment-experience/web-profiles", c.APIBase), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var v []WebProfile
+
+	err = c.SendWithAuth(req, &v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetWebProfile returns a web experience profile by ID
+func (c *Client) GetWebProfile(profileID string) (*WebProfile, error) {
+	return c.GetWebProfileContext(context.Background(), profileID)
+}
+
+// GetWebProfileContext is like GetWebProfile but uses ctx for the request
+func (c *Client) GetWebProfileContext(ctx context.Context, profileID string) (*WebProfile, error) {
+	req, err := NewRequestContext(ctx, "GET", fmt.Sprintf("%s/payment-experience/web-profiles/%s", c.APIBase, profileID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &WebProfile{}
+
+	err = c.SendWithAuth(req, v)
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// UpdateWebProfile replaces a web experience profile with p
+func (c *Client) UpdateWebProfile(profileID string, p WebProfile) error {
+	return c.UpdateWebProfileContext(context.Background(), profileID, p)
+}
+
+// UpdateWebProfileContext is like UpdateWebProfile but uses ctx for the request
+func (c *Client) UpdateWebProfileContext(ctx context.Context, profileID string, p WebProfile) error {
+	req, err := NewRequestContext(ctx, "PUT", fmt.Sprintf("%s/payment-experience/web-profiles/%s", c.APIBase, profileID), p)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// PatchWebProfile applies a JSON Patch to a web experience profile
+func (c *Client) PatchWebProfile(profileID string, patches []Patch) error {
+	return c.PatchWebProfileContext(context.Background(), profileID, patches)
+}
+
+// PatchWebProfileContext is like PatchWebProfile but uses ctx for the request
+func (c *Client) PatchWebProfileContext(ctx context.Context, profileID string, patches []Patch) error {
+	req, err := NewRequestContext(ctx, "PATCH", fmt.Sprintf("%s/payment-experience/web-profiles/%s", c.APIBase, profileID), patches)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
+
+// DeleteWebProfile deletes a web experience profile
+func (c *Client) DeleteWebProfile(profileID string) error {
+	return c.DeleteWebProfileContext(context.Background(), profileID)
+}
+
+// DeleteWebProfileContext is like DeleteWebProfile but uses ctx for the request
+func (c *Client) DeleteWebProfileContext(ctx context.Context, profileID string) error {
+	req, err := NewRequestContext(ctx, "DELETE", fmt.Sprintf("%s/payment-experience/web-profiles/%s", c.APIBase, profileID), nil)
+	if err != nil {
+		return err
+	}
+
+	return c.SendWithAuth(req, nil)
+}
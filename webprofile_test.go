@@ -0,0 +1,37 @@
+package paypal
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestWebProfileInputFields(t *testing.T) {
+	Convey("Input fields should be sent even when set to their zero value", t, func() {
+		var body map[string]interface{}
+		c := newOfflineClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/v1/oauth2/token" {
+				writeToken(w, "token")
+				return
+			}
+			data, _ := io.ReadAll(r.Body)
+			json.Unmarshal(data, &body)
+			w.WriteHeader(http.StatusNoContent)
+		})
+
+		err := c.UpdateWebProfile("XP-1", WebProfile{
+			Name:        "Physical goods",
+			InputFields: &InputFields{NoShipping: NoShippingDisplay},
+		})
+
+		So(err, ShouldBeNil)
+		So(body["input_fields"], ShouldResemble, map[string]interface{}{
+			"allow_note":       false,
+			"no_shipping":      float64(NoShippingDisplay),
+			"address_override": float64(0),
+		})
+	})
+}